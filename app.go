@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"rungrid/backend/backup"
 	"rungrid/backend/domain"
 	"rungrid/backend/hotkey"
 	"rungrid/backend/icon"
@@ -19,6 +22,10 @@ import (
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// appVersion is recorded in backup manifests; keep it in sync with
+// info.productVersion in wails.json.
+const appVersion = "0.1.0"
+
 // App struct
 type App struct {
 	ctx      context.Context
@@ -26,9 +33,18 @@ type App struct {
 	groups   *service.GroupService
 	icons    *service.IconService
 	scanner  *service.ScannerService
+	scans    *service.ScanJobService
+	watcher  *service.WatcherService
 	launcher *service.LauncherService
+	usage    *service.UsageService
+	settings *service.SettingsService
+	journal  *service.OperationService
+	rules    *service.RuleSetService
+	backups  *backup.Manager
 	hotkeys  *hotkey.Manager
 	closeFn  func() error
+
+	hotkeyIssues []domain.HotkeyIssue
 }
 
 // NewApp creates a new App application struct
@@ -44,27 +60,61 @@ func NewApp() (*App, error) {
 		return nil, err
 	}
 
-	if err := sqlite.EnsureSchema(context.Background(), db); err != nil {
+	if err := sqlite.EnsureSchema(context.Background(), db, filepath.Join(dataRoot, "backups")); err != nil {
 		_ = db.Close()
 		return nil, err
 	}
 
 	itemRepo := sqlite.NewItemRepository(db)
 	groupRepo := sqlite.NewGroupRepository(db)
+	launchRepo := sqlite.NewLaunchRepository(db)
+	settingsRepo := sqlite.NewSettingsRepository(db)
 
-	itemService := service.NewItemService(itemRepo)
-	groupService := service.NewGroupService(groupRepo)
+	unitOfWork := sqlite.NewUnitOfWork(db)
+	operationService := service.NewOperationService(sqlite.NewOperationRepository(db), itemRepo, groupRepo, unitOfWork)
+	itemService := service.NewItemService(itemRepo, launchRepo, groupRepo, operationService, unitOfWork)
+	groupService := service.NewGroupService(groupRepo, itemService)
+	settingsService := service.NewSettingsService(settingsRepo)
+
+	halfLife, err := settingsService.FrecencyHalfLifeDays(context.Background())
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	itemService.SetFrecencyHalfLife(daysToDuration(halfLife))
 
 	iconRoot := filepath.Join(dataRoot, "icons")
 	iconCache := icon.NewCache(iconRoot, icon.NewHybridExtractor())
 	iconService := service.NewIconService(iconCache, itemService)
+	ruleSetService := service.NewRuleSetService(sqlite.NewRuleSetRepository(db), groupService, itemService, filepath.Join(dataRoot, "rules"))
+	scannerService := service.NewScannerService(scanner.NewDefaultScanner(), itemService, iconService, ruleSetService, sqlite.NewScanIndexRepository(db))
+	brokenPolicy, err := settingsService.BrokenItemPolicy(context.Background())
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	scannerService.SetBrokenItemPolicy(brokenPolicy)
+	scanConcurrency, err := settingsService.ScanConcurrency(context.Background())
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	scannerService.SetConcurrency(scanConcurrency)
+	scanJobs := service.NewScanJobService(scannerService)
 	hotkeyManager := hotkey.NewManager()
 	app := &App{
 		items:    itemService,
 		groups:   groupService,
 		icons:    iconService,
-		scanner:  service.NewScannerService(scanner.NewDefaultScanner(), itemService, iconService),
+		scanner:  scannerService,
+		scans:    scanJobs,
+		watcher:  service.NewWatcherService(scanJobs),
 		launcher: service.NewLauncherService(launcher.NewDefaultLauncher(), itemService),
+		usage:    service.NewUsageService(launchRepo, itemService, groupService),
+		settings: settingsService,
+		journal:  operationService,
+		rules:    ruleSetService,
+		backups:  backup.NewManager(db, dataRoot, appVersion, settingsRepo),
 		hotkeys:  hotkeyManager,
 		closeFn:  db.Close,
 	}
@@ -75,6 +125,10 @@ func NewApp() (*App, error) {
 		_ = db.Close()
 		return nil, err
 	}
+	if _, err := app.groups.RepairOrphans(context.Background()); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
 
 	return app, nil
 }
@@ -86,12 +140,49 @@ func (a *App) startup(ctx context.Context) {
 	globalTray.start(ctx)
 	if a.hotkeys != nil {
 		a.hotkeys.Start(ctx)
+		a.applyStoredHotkeys(ctx)
+	}
+	a.startWatcher(ctx)
+}
+
+// startWatcher (re)starts watching the saved scan roots, or stops watching
+// when the user turned it off.
+func (a *App) startWatcher(ctx context.Context) {
+	enabled, err := a.settings.WatchScanRoots(ctx)
+	if err != nil {
+		runtime.LogWarningf(ctx, "load watch setting: %v", err)
+		return
+	}
+	roots, err := a.settings.ScanRoots(ctx)
+	if err != nil {
+		runtime.LogWarningf(ctx, "load scan roots: %v", err)
+		return
+	}
+	if err := a.watcher.Start(ctx, roots, enabled); err != nil {
+		runtime.LogWarningf(ctx, "start watcher: %v", err)
+	}
+}
+
+// applyStoredHotkeys registers the saved bindings so they work before the
+// frontend has loaded. Issues are kept for the frontend to report.
+func (a *App) applyStoredHotkeys(ctx context.Context) {
+	bindings, err := a.settings.Hotkeys(ctx)
+	if err != nil {
+		runtime.LogWarningf(ctx, "load hotkeys: %v", err)
+		return
+	}
+	issues, err := a.hotkeys.Apply(bindings)
+	if err != nil {
+		runtime.LogWarningf(ctx, "apply hotkeys: %v", err)
+		return
 	}
+	a.hotkeyIssues = issues
 }
 
 // shutdown is called when the app is terminating.
 func (a *App) shutdown(ctx context.Context) {
 	globalTray.stop()
+	a.watcher.Stop()
 	if a.hotkeys != nil {
 		a.hotkeys.Stop()
 	}
@@ -100,8 +191,22 @@ func (a *App) shutdown(ctx context.Context) {
 	}
 }
 
+// ListItems lists the items shown under a group: the items of a manual
+// group and its subgroups, or those matching the query of a smart group.
 func (a *App) ListItems(groupID string, query string) ([]domain.Item, error) {
-	return a.items.List(a.context(), storage.ItemFilter{GroupID: groupID, Query: query})
+	filter, err := a.groups.ItemFilter(a.context(), groupID, query)
+	if err != nil {
+		return nil, err
+	}
+	return a.items.List(a.context(), filter)
+}
+
+func (a *App) SearchItems(groupID string, query string) ([]domain.SearchResult, error) {
+	filter, err := a.groups.ItemFilter(a.context(), groupID, query)
+	if err != nil {
+		return nil, err
+	}
+	return a.items.Search(a.context(), filter)
 }
 
 func (a *App) CreateItem(input domain.ItemInput) (domain.Item, error) {
@@ -123,15 +228,73 @@ func (a *App) ClearItems() (int, error) {
 	return a.items.Clear(a.context())
 }
 
-func (a *App) RecordLaunch(id string) (domain.Item, error) {
-	return a.items.RecordLaunch(a.context(), id)
+// BulkUpdateItems applies the updates as a single undoable operation.
+func (a *App) BulkUpdateItems(updates []domain.ItemUpdate) ([]domain.Item, error) {
+	return a.items.BulkUpdate(a.context(), updates)
+}
+
+func (a *App) ListOperations(limit int) ([]domain.Operation, error) {
+	return a.journal.List(a.context(), limit)
 }
 
-func (a *App) ScanShortcuts(roots []string) (domain.ScanResult, error) {
-	if a.scanner == nil {
-		return domain.ScanResult{}, scanner.ErrUnsupported
+func (a *App) UndoOperation(id string) (domain.Operation, error) {
+	return a.journal.Undo(a.context(), id)
+}
+
+func (a *App) RecordLaunch(id string, source string) (domain.Item, error) {
+	return a.items.RecordLaunch(a.context(), id, domain.LaunchSource(source))
+}
+
+func (a *App) ListItemUsage(window string) ([]domain.ItemUsage, error) {
+	return a.usage.ItemUsage(a.context(), domain.UsageWindow(window))
+}
+
+func (a *App) ListGroupUsage(window string) ([]domain.GroupUsage, error) {
+	return a.usage.GroupUsage(a.context(), domain.UsageWindow(window))
+}
+
+func (a *App) TopItems(window string, limit int) ([]domain.ItemUsage, error) {
+	return a.usage.TopItems(a.context(), domain.UsageWindow(window), limit)
+}
+
+func (a *App) GetUsageHeatmap(window string, itemID string) (domain.UsageHeatmap, error) {
+	return a.usage.Heatmap(a.context(), domain.UsageWindow(window), itemID)
+}
+
+func (a *App) GetItemFrecency(id string) (domain.FrecencyBreakdown, error) {
+	return a.items.Frecency(a.context(), id)
+}
+
+// ScanShortcuts runs a scan job and waits for it. While another scan of the
+// same roots is running it waits for that one and returns its job instead
+// of starting a second scan; a scan of other roots runs once the current
+// one ends. A cancelled job is returned with status cancelled.
+func (a *App) ScanShortcuts(roots []string) (domain.ScanJob, error) {
+	if a.scans == nil {
+		return domain.ScanJob{}, scanner.ErrUnsupported
+	}
+	return a.scans.Run(a.context(), domain.ScanSourceManual, roots, nil)
+}
+
+// CancelScan stops the running scan job with the given ID, or any running
+// job when id is empty. A scan cancelled while writing rolls back, so the
+// items stay as they were.
+func (a *App) CancelScan(id string) bool {
+	return a.scans.Cancel(id)
+}
+
+// GetCurrentScan returns the running scan job, or nil when idle.
+func (a *App) GetCurrentScan() *domain.ScanJob {
+	job, ok := a.scans.Current()
+	if !ok {
+		return nil
 	}
-	return a.scanner.ScanWithRoots(a.context(), roots)
+	return &job
+}
+
+// ListScanHistory returns the most recent finished scan jobs, newest first.
+func (a *App) ListScanHistory() []domain.ScanJob {
+	return a.scans.History()
 }
 
 func (a *App) ListScanRoots() ([]string, error) {
@@ -201,6 +364,19 @@ func (a *App) PickRuleFile() (string, error) {
 	})
 }
 
+func (a *App) PickRuleExportDestination() (string, error) {
+	return runtime.SaveFileDialog(a.context(), runtime.SaveDialogOptions{
+		Title:           "导出分组规则",
+		DefaultFilename: "rungrid-rules.json",
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "规则文件 (*.json)",
+				Pattern:     "*.json",
+			},
+		},
+	})
+}
+
 func (a *App) GetDataRoot() (string, error) {
 	return dataRootPath("rungrid", false)
 }
@@ -255,6 +431,22 @@ func (a *App) RestartApp() error {
 	return nil
 }
 
+// PreviewGroupRules returns the changes ImportGroupRules would make for the
+// rule file at path, without applying them.
+func (a *App) PreviewGroupRules(path string) (domain.RuleImportPlan, error) {
+	if strings.TrimSpace(path) == "" {
+		return domain.RuleImportPlan{}, storage.ErrInvalidInput
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return domain.RuleImportPlan{}, err
+	}
+	return a.rules.Preview(a.context(), path, data)
+}
+
+// ImportGroupRules applies the rule file at path and keeps it as a rule set,
+// named after the file, that is re-applied to newly scanned items.
+// Importing the same path again replaces that set.
 func (a *App) ImportGroupRules(path string) (domain.RuleImportResult, error) {
 	if strings.TrimSpace(path) == "" {
 		return domain.RuleImportResult{}, storage.ErrInvalidInput
@@ -263,7 +455,91 @@ func (a *App) ImportGroupRules(path string) (domain.RuleImportResult, error) {
 	if err != nil {
 		return domain.RuleImportResult{}, err
 	}
-	return service.ImportGroupRules(a.context(), data, a.groups, a.items)
+	return a.rules.Import(a.context(), path, data)
+}
+
+// ExportGroupRules writes the current groups and the target names of their
+// items, or the paths of items without one, to path in the rule file format.
+func (a *App) ExportGroupRules(path string) (domain.RuleExportResult, error) {
+	return a.rules.Export(a.context(), path)
+}
+
+func (a *App) ListRuleSets() ([]domain.RuleSet, error) {
+	return a.rules.List(a.context())
+}
+
+func (a *App) SetRuleSetEnabled(id string, enabled bool) (domain.RuleSet, error) {
+	return a.rules.SetEnabled(a.context(), id, enabled)
+}
+
+func (a *App) DeleteRuleSet(id string) error {
+	return a.rules.Delete(a.context(), id)
+}
+
+func (a *App) PickBackupDestination() (string, error) {
+	return runtime.SaveFileDialog(a.context(), runtime.SaveDialogOptions{
+		Title:           "导出备份",
+		DefaultFilename: fmt.Sprintf("rungrid-backup-%s.zip", time.Now().Format("20060102")),
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "备份文件 (*.zip)",
+				Pattern:     "*.zip",
+			},
+		},
+	})
+}
+
+func (a *App) PickBackupFile() (string, error) {
+	return runtime.OpenFileDialog(a.context(), runtime.OpenDialogOptions{
+		Title: "选择备份文件",
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "备份文件 (*.zip)",
+				Pattern:     "*.zip",
+			},
+		},
+	})
+}
+
+func (a *App) ExportBackup(path string) (domain.BackupManifest, error) {
+	return a.backups.Export(a.context(), path)
+}
+
+// PreviewBackup reports what ImportBackup would replace without touching
+// any data.
+func (a *App) PreviewBackup(path string) (domain.BackupPreview, error) {
+	return a.backups.Preview(a.context(), path)
+}
+
+// ImportBackup replaces the current data with the archive and re-applies the
+// restored settings.
+func (a *App) ImportBackup(path string) (domain.BackupPreview, error) {
+	ctx := a.context()
+	preview, err := a.backups.Import(ctx, path)
+	if err != nil {
+		return preview, err
+	}
+
+	if halfLife, err := a.settings.FrecencyHalfLifeDays(ctx); err == nil {
+		a.items.SetFrecencyHalfLife(daysToDuration(halfLife))
+	}
+	if policy, err := a.settings.BrokenItemPolicy(ctx); err == nil {
+		a.scanner.SetBrokenItemPolicy(policy)
+	}
+	if limit, err := a.settings.ScanConcurrency(ctx); err == nil {
+		a.scanner.SetConcurrency(limit)
+	}
+	if a.hotkeys != nil {
+		a.applyStoredHotkeys(ctx)
+	}
+	a.startWatcher(ctx)
+	if err := service.EnsureDefaultGroups(ctx, a.groups); err != nil {
+		return preview, err
+	}
+	if _, err := a.groups.RepairOrphans(ctx); err != nil {
+		return preview, err
+	}
+	return preview, nil
 }
 
 func (a *App) UpdateItemIconFromSource(id string, source string) (domain.Item, error) {
@@ -287,11 +563,11 @@ func (a *App) RefreshItemIcon(id string) (domain.Item, error) {
 	return a.icons.RefreshItem(a.context(), id)
 }
 
-func (a *App) LaunchItem(id string) (domain.Item, error) {
+func (a *App) LaunchItem(id string, source string) (domain.Item, error) {
 	if a.launcher == nil {
 		return domain.Item{}, launcher.ErrUnsupported
 	}
-	return a.launcher.LaunchItem(a.context(), id)
+	return a.launcher.LaunchItem(a.context(), id, domain.LaunchSource(source))
 }
 
 func (a *App) OpenItemLocation(id string) error {
@@ -318,6 +594,104 @@ func (a *App) ApplyHotkeys(bindings []domain.HotkeyBinding) (domain.HotkeyApplyR
 	return domain.HotkeyApplyResult{Issues: issues}, nil
 }
 
+// GetHotkeyIssues reports bindings that failed to register at startup.
+func (a *App) GetHotkeyIssues() []domain.HotkeyIssue {
+	if a.hotkeyIssues == nil {
+		return []domain.HotkeyIssue{}
+	}
+	return a.hotkeyIssues
+}
+
+func (a *App) GetSettings() (domain.Settings, error) {
+	return a.settings.Settings(a.context())
+}
+
+// SaveHotkeys persists bindings and registers them right away.
+func (a *App) SaveHotkeys(bindings []domain.HotkeyBinding) (domain.HotkeyApplyResult, error) {
+	saved, err := a.settings.SetHotkeys(a.context(), bindings)
+	if err != nil {
+		return domain.HotkeyApplyResult{}, err
+	}
+	result, err := a.ApplyHotkeys(saved)
+	if err == nil {
+		a.hotkeyIssues = result.Issues
+	}
+	return result, err
+}
+
+func (a *App) SaveScanRoots(roots []string) ([]string, error) {
+	ctx := a.context()
+	saved, err := a.settings.SetScanRoots(ctx, roots)
+	if err != nil {
+		return nil, err
+	}
+	a.startWatcher(ctx)
+	return saved, nil
+}
+
+func (a *App) SavePreferences(preferences domain.Preferences) (domain.Preferences, error) {
+	return a.settings.SetPreferences(a.context(), preferences)
+}
+
+func (a *App) SetFrecencyHalfLife(days float64) error {
+	if err := a.settings.SetFrecencyHalfLifeDays(a.context(), days); err != nil {
+		return err
+	}
+	a.items.SetFrecencyHalfLife(daysToDuration(days))
+	return nil
+}
+
+// SetBrokenItemPolicy chooses whether scans keep, hide or remove items whose
+// file or shortcut target has disappeared.
+func (a *App) SetBrokenItemPolicy(policy string) error {
+	if err := a.settings.SetBrokenItemPolicy(a.context(), domain.BrokenItemPolicy(policy)); err != nil {
+		return err
+	}
+	a.scanner.SetBrokenItemPolicy(domain.BrokenItemPolicy(policy))
+	return nil
+}
+
+// SetScanConcurrency bounds parallel scanning; zero picks a default based
+// on the number of CPUs.
+func (a *App) SetScanConcurrency(limit int) error {
+	if err := a.settings.SetScanConcurrency(a.context(), limit); err != nil {
+		return err
+	}
+	a.scanner.SetConcurrency(limit)
+	return nil
+}
+
+// SetWatchScanRoots turns watching the scan roots for changes on or off.
+func (a *App) SetWatchScanRoots(enabled bool) (domain.WatcherStatus, error) {
+	ctx := a.context()
+	if err := a.settings.SetWatchScanRoots(ctx, enabled); err != nil {
+		return domain.WatcherStatus{}, err
+	}
+	a.startWatcher(ctx)
+	return a.watcher.Status(), nil
+}
+
+func (a *App) GetWatcherStatus() domain.WatcherStatus {
+	return a.watcher.Status()
+}
+
+// PauseWatcher stops rescans during bulk file operations until
+// ResumeWatcher; changes made in between are scanned once on resume.
+func (a *App) PauseWatcher() domain.WatcherStatus {
+	a.watcher.Pause()
+	return a.watcher.Status()
+}
+
+func (a *App) ResumeWatcher() domain.WatcherStatus {
+	a.watcher.Resume()
+	return a.watcher.Status()
+}
+
+// ListBrokenItems returns the items the last scan found broken.
+func (a *App) ListBrokenItems() ([]domain.Item, error) {
+	return a.items.ListBroken(a.context())
+}
+
 func (a *App) ListGroups() ([]domain.Group, error) {
 	return a.groups.List(a.context())
 }
@@ -330,8 +704,16 @@ func (a *App) UpdateGroup(input domain.Group) (domain.Group, error) {
 	return a.groups.Update(a.context(), input)
 }
 
-func (a *App) DeleteGroup(id string) error {
-	return a.groups.Delete(a.context(), id)
+// MoveGroup nests a group below parentID, or makes it a top-level group
+// when parentID is empty.
+func (a *App) MoveGroup(id string, parentID string) (domain.Group, error) {
+	return a.groups.Move(a.context(), id, parentID)
+}
+
+// DeleteGroup deletes a group and moves, ungroups or deletes its items as
+// options.Policy says.
+func (a *App) DeleteGroup(id string, options domain.GroupDeleteOptions) (domain.GroupDeleteResult, error) {
+	return a.groups.Delete(a.context(), id, options)
 }
 
 func (a *App) context() context.Context {
@@ -340,3 +722,7 @@ func (a *App) context() context.Context {
 	}
 	return context.Background()
 }
+
+func daysToDuration(days float64) time.Duration {
+	return time.Duration(days * float64(24*time.Hour))
+}
@@ -0,0 +1,277 @@
+package backup
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"rungrid/backend/domain"
+)
+
+const (
+	manifestName = "manifest.json"
+	databaseName = "rungrid.db"
+	settingsName = "settings.json"
+	iconsDir     = "icons"
+	rulesDir     = "rules"
+
+	// archiveFormat is bumped when the archive layout changes incompatibly.
+	archiveFormat = 1
+)
+
+var (
+	ErrInvalidArchive   = errors.New("invalid backup archive")
+	ErrChecksumMismatch = errors.New("backup checksum mismatch")
+)
+
+// archiveWriter streams files into a zip and records their checksums for
+// the manifest.
+type archiveWriter struct {
+	zip   *zip.Writer
+	files []domain.BackupFile
+}
+
+func newArchiveWriter(w io.Writer) *archiveWriter {
+	return &archiveWriter{zip: zip.NewWriter(w)}
+}
+
+func (w *archiveWriter) addFile(name string, source string) error {
+	file, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return w.addReader(name, file)
+}
+
+func (w *archiveWriter) addBytes(name string, data []byte) error {
+	return w.addReader(name, strings.NewReader(string(data)))
+}
+
+func (w *archiveWriter) addReader(name string, r io.Reader) error {
+	entry, err := w.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(entry, hash), r)
+	if err != nil {
+		return err
+	}
+	w.files = append(w.files, domain.BackupFile{
+		Path:   name,
+		Size:   size,
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+	})
+	return nil
+}
+
+// addDir adds every regular file below root under the archive prefix. A
+// missing root is skipped.
+func (w *archiveWriter) addDir(prefix string, root string) error {
+	if _, err := os.Stat(root); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return filepath.WalkDir(root, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, current)
+		if err != nil {
+			return err
+		}
+		return w.addFile(path.Join(prefix, filepath.ToSlash(rel)), current)
+	})
+}
+
+func (w *archiveWriter) close(manifest domain.BackupManifest) (domain.BackupManifest, error) {
+	manifest.Files = w.files
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return domain.BackupManifest{}, err
+	}
+	entry, err := w.zip.Create(manifestName)
+	if err != nil {
+		return domain.BackupManifest{}, err
+	}
+	if _, err := entry.Write(data); err != nil {
+		return domain.BackupManifest{}, err
+	}
+	if err := w.zip.Close(); err != nil {
+		return domain.BackupManifest{}, err
+	}
+	return manifest, nil
+}
+
+// openArchive reads the manifest and checks that the archive holds exactly
+// the listed files with matching checksums.
+func openArchive(archivePath string) (*zip.ReadCloser, domain.BackupManifest, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, domain.BackupManifest{}, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	manifest, err := verifyArchive(&reader.Reader)
+	if err != nil {
+		_ = reader.Close()
+		return nil, domain.BackupManifest{}, err
+	}
+	return reader, manifest, nil
+}
+
+func verifyArchive(reader *zip.Reader) (domain.BackupManifest, error) {
+	entries := map[string]*zip.File{}
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if !isSafeName(file.Name) {
+			return domain.BackupManifest{}, fmt.Errorf("%w: unexpected entry %q", ErrInvalidArchive, file.Name)
+		}
+		entries[file.Name] = file
+	}
+
+	manifestFile, ok := entries[manifestName]
+	if !ok {
+		return domain.BackupManifest{}, fmt.Errorf("%w: missing %s", ErrInvalidArchive, manifestName)
+	}
+	var manifest domain.BackupManifest
+	if err := readJSON(manifestFile, &manifest); err != nil {
+		return domain.BackupManifest{}, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	if manifest.Format != archiveFormat {
+		return domain.BackupManifest{}, fmt.Errorf("%w: unsupported format %d", ErrInvalidArchive, manifest.Format)
+	}
+
+	listed := map[string]struct{}{manifestName: {}}
+	hasDatabase := false
+	for _, expected := range manifest.Files {
+		file, ok := entries[expected.Path]
+		if !ok {
+			return domain.BackupManifest{}, fmt.Errorf("%w: missing %s", ErrInvalidArchive, expected.Path)
+		}
+		sum, size, err := checksum(file)
+		if err != nil {
+			return domain.BackupManifest{}, err
+		}
+		if sum != expected.SHA256 || size != expected.Size {
+			return domain.BackupManifest{}, fmt.Errorf("%w: %s", ErrChecksumMismatch, expected.Path)
+		}
+		listed[expected.Path] = struct{}{}
+		if expected.Path == databaseName {
+			hasDatabase = true
+		}
+	}
+	if !hasDatabase {
+		return domain.BackupManifest{}, fmt.Errorf("%w: missing %s", ErrInvalidArchive, databaseName)
+	}
+	for name := range entries {
+		if _, ok := listed[name]; !ok {
+			return domain.BackupManifest{}, fmt.Errorf("%w: unlisted entry %q", ErrInvalidArchive, name)
+		}
+	}
+
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Path < manifest.Files[j].Path
+	})
+	return manifest, nil
+}
+
+// isSafeName accepts only the known top-level files and relative paths
+// inside the icons and rules folders.
+func isSafeName(name string) bool {
+	if name == "" || strings.Contains(name, "\\") || path.IsAbs(name) || path.Clean(name) != name {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." || part == "." || strings.Contains(part, ":") {
+			return false
+		}
+	}
+	switch name {
+	case manifestName, databaseName, settingsName:
+		return true
+	}
+	return strings.HasPrefix(name, iconsDir+"/") || strings.HasPrefix(name, rulesDir+"/")
+}
+
+func checksum(file *zip.File) (string, int64, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return "", 0, err
+	}
+	defer reader.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, reader)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+func readJSON(file *zip.File, target any) error {
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return json.NewDecoder(reader).Decode(target)
+}
+
+// extract writes the archive entries below dir, which must be empty.
+func extract(reader *zip.Reader, dir string) error {
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || file.Name == manifestName {
+			continue
+		}
+		target := filepath.Join(dir, filepath.FromSlash(file.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := extractFile(file, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractFile(file *zip.File, target string) error {
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	dest, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dest, reader); err != nil {
+		_ = dest.Close()
+		return err
+	}
+	return dest.Close()
+}
+
+func countPrefix(files []domain.BackupFile, prefix string) int {
+	count := 0
+	for _, file := range files {
+		if strings.HasPrefix(file.Path, prefix+"/") {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,359 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/storage"
+	"rungrid/backend/storage/sqlite"
+)
+
+// Manager exports the data root (database, icon cache, rule files) to a zip
+// archive and restores it into the running instance.
+type Manager struct {
+	db         *sql.DB
+	dataRoot   string
+	appVersion string
+	settings   storage.SettingsRepository
+}
+
+func NewManager(db *sql.DB, dataRoot string, appVersion string, settings storage.SettingsRepository) *Manager {
+	return &Manager{db: db, dataRoot: dataRoot, appVersion: appVersion, settings: settings}
+}
+
+// Export writes a backup archive to dest. The archive is assembled next to
+// dest and renamed into place, so a failed export leaves no partial file.
+func (m *Manager) Export(ctx context.Context, dest string) (domain.BackupManifest, error) {
+	dest = strings.TrimSpace(dest)
+	if dest == "" || !filepath.IsAbs(dest) {
+		return domain.BackupManifest{}, storage.ErrInvalidInput
+	}
+
+	scratch, err := os.MkdirTemp(m.dataRoot, ".backup-*")
+	if err != nil {
+		return domain.BackupManifest{}, err
+	}
+	defer os.RemoveAll(scratch)
+
+	snapshot := filepath.Join(scratch, databaseName)
+	if err := sqlite.VacuumInto(ctx, m.db, snapshot); err != nil {
+		return domain.BackupManifest{}, err
+	}
+	schemaVersion, err := sqlite.SchemaVersion(ctx, m.db)
+	if err != nil {
+		return domain.BackupManifest{}, err
+	}
+	settings, err := m.settingsJSON(ctx)
+	if err != nil {
+		return domain.BackupManifest{}, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return domain.BackupManifest{}, err
+	}
+	file, err := os.CreateTemp(filepath.Dir(dest), ".rungrid-backup-*.zip")
+	if err != nil {
+		return domain.BackupManifest{}, err
+	}
+	tempName := file.Name()
+	defer os.Remove(tempName)
+
+	writer := newArchiveWriter(file)
+	manifest, err := func() (domain.BackupManifest, error) {
+		if err := writer.addFile(databaseName, snapshot); err != nil {
+			return domain.BackupManifest{}, err
+		}
+		if err := writer.addBytes(settingsName, settings); err != nil {
+			return domain.BackupManifest{}, err
+		}
+		if err := writer.addDir(iconsDir, filepath.Join(m.dataRoot, iconsDir)); err != nil {
+			return domain.BackupManifest{}, err
+		}
+		if err := writer.addDir(rulesDir, filepath.Join(m.dataRoot, rulesDir)); err != nil {
+			return domain.BackupManifest{}, err
+		}
+		return writer.close(domain.BackupManifest{
+			Format:        archiveFormat,
+			AppVersion:    m.appVersion,
+			SchemaVersion: schemaVersion,
+			CreatedAt:     time.Now().UTC(),
+		})
+	}()
+	closeErr := file.Close()
+	if err != nil {
+		return domain.BackupManifest{}, err
+	}
+	if closeErr != nil {
+		return domain.BackupManifest{}, closeErr
+	}
+
+	if err := os.Rename(tempName, dest); err != nil {
+		return domain.BackupManifest{}, err
+	}
+	return manifest, nil
+}
+
+// Preview verifies the archive and reports what Import would overwrite
+// without changing anything.
+func (m *Manager) Preview(ctx context.Context, archivePath string) (domain.BackupPreview, error) {
+	reader, manifest, err := openArchive(archivePath)
+	if err != nil {
+		return domain.BackupPreview{}, err
+	}
+	defer reader.Close()
+
+	scratch, err := os.MkdirTemp(m.dataRoot, ".restore-*")
+	if err != nil {
+		return domain.BackupPreview{}, err
+	}
+	defer os.RemoveAll(scratch)
+
+	if err := extract(&reader.Reader, scratch); err != nil {
+		return domain.BackupPreview{}, err
+	}
+	return m.preview(ctx, manifest, scratch)
+}
+
+// Import replaces the current data with the archive contents. The database
+// is restored in a single transaction after a safety snapshot of the current
+// database is written to the backups folder; icon and rule folders are then
+// swapped in.
+func (m *Manager) Import(ctx context.Context, archivePath string) (domain.BackupPreview, error) {
+	reader, manifest, err := openArchive(archivePath)
+	if err != nil {
+		return domain.BackupPreview{}, err
+	}
+	defer reader.Close()
+
+	scratch, err := os.MkdirTemp(m.dataRoot, ".restore-*")
+	if err != nil {
+		return domain.BackupPreview{}, err
+	}
+	defer os.RemoveAll(scratch)
+
+	if err := extract(&reader.Reader, scratch); err != nil {
+		return domain.BackupPreview{}, err
+	}
+	preview, err := m.preview(ctx, manifest, scratch)
+	if err != nil {
+		return domain.BackupPreview{}, err
+	}
+	if latest := sqlite.LatestSchemaVersion(); manifest.SchemaVersion > latest {
+		return preview, fmt.Errorf("%w: backup is at version %d, this build supports up to %d", sqlite.ErrSchemaTooNew, manifest.SchemaVersion, latest)
+	}
+
+	if err := m.snapshotCurrent(ctx); err != nil {
+		return preview, fmt.Errorf("backup before restore: %w", err)
+	}
+	if err := sqlite.RestoreFrom(ctx, m.db, filepath.Join(scratch, databaseName)); err != nil {
+		return preview, err
+	}
+	for _, dir := range []string{iconsDir, rulesDir} {
+		if err := replaceDir(filepath.Join(scratch, dir), filepath.Join(m.dataRoot, dir)); err != nil {
+			return preview, err
+		}
+	}
+	if err := m.relocateIcons(ctx); err != nil {
+		return preview, err
+	}
+	return preview, nil
+}
+
+func (m *Manager) preview(ctx context.Context, manifest domain.BackupManifest, scratch string) (domain.BackupPreview, error) {
+	currentVersion, err := sqlite.SchemaVersion(ctx, m.db)
+	if err != nil {
+		return domain.BackupPreview{}, err
+	}
+	preview := domain.BackupPreview{
+		Manifest:             manifest,
+		CurrentSchemaVersion: currentVersion,
+		Icons: domain.BackupCount{
+			Current: countFiles(filepath.Join(m.dataRoot, iconsDir)),
+			Backup:  countPrefix(manifest.Files, iconsDir),
+		},
+		Rules: domain.BackupCount{
+			Current: countFiles(filepath.Join(m.dataRoot, rulesDir)),
+			Backup:  countPrefix(manifest.Files, rulesDir),
+		},
+	}
+
+	snapshot, err := sqlite.Open(filepath.Join(scratch, databaseName))
+	if err != nil {
+		return domain.BackupPreview{}, err
+	}
+	defer snapshot.Close()
+
+	counts := []struct {
+		table  string
+		target *domain.BackupCount
+	}{
+		{"items", &preview.Items},
+		{"groups", &preview.Groups},
+		{"launches", &preview.Launches},
+	}
+	for _, count := range counts {
+		if count.target.Current, err = sqlite.CountRows(ctx, m.db, count.table); err != nil {
+			return domain.BackupPreview{}, err
+		}
+		if count.target.Backup, err = sqlite.CountRows(ctx, snapshot, count.table); err != nil {
+			return domain.BackupPreview{}, err
+		}
+	}
+
+	preview.Settings, err = m.settingChanges(ctx, filepath.Join(scratch, settingsName))
+	if err != nil {
+		return domain.BackupPreview{}, err
+	}
+	return preview, nil
+}
+
+func (m *Manager) settingsJSON(ctx context.Context) ([]byte, error) {
+	settings := []domain.Setting{}
+	if m.settings != nil {
+		var err error
+		if settings, err = m.settings.List(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return json.MarshalIndent(settings, "", "  ")
+}
+
+// settingChanges lists settings whose value differs between the running
+// instance and the archive.
+func (m *Manager) settingChanges(ctx context.Context, path string) ([]domain.SettingChange, error) {
+	backup := map[string]string{}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		var settings []domain.Setting
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		for _, setting := range settings {
+			backup[setting.Key] = setting.Value
+		}
+	}
+
+	current := map[string]string{}
+	if m.settings != nil {
+		settings, err := m.settings.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, setting := range settings {
+			current[setting.Key] = setting.Value
+		}
+	}
+
+	changes := []domain.SettingChange{}
+	for key, value := range backup {
+		if current[key] != value {
+			changes = append(changes, domain.SettingChange{Key: key, Current: current[key], Backup: value})
+		}
+	}
+	for key, value := range current {
+		if _, ok := backup[key]; !ok {
+			changes = append(changes, domain.SettingChange{Key: key, Current: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes, nil
+}
+
+func (m *Manager) snapshotCurrent(ctx context.Context) error {
+	dir := filepath.Join(m.dataRoot, "backups")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("rungrid-pre-restore-%s.db", time.Now().Format("20060102-150405"))
+	return sqlite.VacuumInto(ctx, m.db, filepath.Join(dir, name))
+}
+
+// relocateIcons points icon paths at the current icon folder; archives
+// restored into another data root still carry the old absolute paths.
+func (m *Manager) relocateIcons(ctx context.Context) error {
+	rows, err := m.db.QueryContext(ctx, "SELECT id, icon_path FROM items WHERE icon_path != ''")
+	if err != nil {
+		return err
+	}
+	updates := map[string]string{}
+	iconRoot := filepath.Join(m.dataRoot, iconsDir)
+	for rows.Next() {
+		var id, iconPath string
+		if err := rows.Scan(&id, &iconPath); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		if filepath.Dir(iconPath) == iconRoot {
+			continue
+		}
+		relocated := filepath.Join(iconRoot, baseName(iconPath))
+		if _, err := os.Stat(relocated); err == nil {
+			updates[id] = relocated
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	for id, iconPath := range updates {
+		if _, err := m.db.ExecContext(ctx, "UPDATE items SET icon_path = ? WHERE id = ?", iconPath, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// baseName handles icon paths written on either platform.
+func baseName(path string) string {
+	if index := strings.LastIndexAny(path, `/\`); index >= 0 {
+		return path[index+1:]
+	}
+	return path
+}
+
+// replaceDir swaps source in for dest. The previous dest is only removed
+// after the new one is in place.
+func replaceDir(source, dest string) error {
+	if _, err := os.Stat(source); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(source, 0o755); err != nil {
+			return err
+		}
+	}
+
+	previous := dest + ".old"
+	_ = os.RemoveAll(previous)
+	if err := os.Rename(dest, previous); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Rename(source, dest); err != nil {
+		_ = os.Rename(previous, dest)
+		return err
+	}
+	return os.RemoveAll(previous)
+}
+
+func countFiles(root string) int {
+	count := 0
+	_ = filepath.WalkDir(root, func(_ string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.Type().IsRegular() {
+			count++
+		}
+		return nil
+	})
+	return count
+}
@@ -0,0 +1,283 @@
+package desktopentry
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+const mainGroup = "Desktop Entry"
+
+var ErrInvalidEntry = errors.New("invalid desktop entry")
+
+// Entry holds the keys of the [Desktop Entry] group of a .desktop file.
+type Entry struct {
+	Type       string
+	Name       string
+	Exec       string
+	TryExec    string
+	Icon       string
+	Path       string
+	URL        string
+	Terminal   bool
+	NoDisplay  bool
+	Hidden     bool
+	OnlyShowIn []string
+	NotShowIn  []string
+	Categories []string
+
+	values map[string]string
+}
+
+func ParseFile(path string) (Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+func Parse(r io.Reader) (Entry, error) {
+	values := map[string]string{}
+	group := ""
+	seenMain := false
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			group = line[1 : len(line)-1]
+			if group == mainGroup {
+				seenMain = true
+			}
+			continue
+		}
+		if group != mainGroup {
+			continue
+		}
+
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:eq])
+		if _, exists := values[key]; exists {
+			continue
+		}
+		values[key] = strings.TrimSpace(line[eq+1:])
+	}
+	if err := scanner.Err(); err != nil {
+		return Entry{}, err
+	}
+	if !seenMain {
+		return Entry{}, ErrInvalidEntry
+	}
+
+	entry := Entry{values: values}
+	entry.Type = entry.String("Type")
+	entry.Name = entry.String("Name")
+	entry.Exec = entry.String("Exec")
+	entry.TryExec = entry.String("TryExec")
+	entry.Icon = entry.String("Icon")
+	entry.Path = entry.String("Path")
+	entry.URL = entry.String("URL")
+	entry.Terminal = entry.Bool("Terminal")
+	entry.NoDisplay = entry.Bool("NoDisplay")
+	entry.Hidden = entry.Bool("Hidden")
+	entry.OnlyShowIn = entry.List("OnlyShowIn")
+	entry.NotShowIn = entry.List("NotShowIn")
+	entry.Categories = entry.List("Categories")
+	return entry, nil
+}
+
+// String returns the unescaped value of key, or "" when it is absent.
+func (e Entry) String(key string) string {
+	raw, ok := e.values[key]
+	if !ok {
+		return ""
+	}
+	return unescapeValue(raw)
+}
+
+// LocaleString resolves key for locale using the lookup order of the
+// Desktop Entry spec: lang_COUNTRY@MODIFIER, lang_COUNTRY, lang@MODIFIER,
+// lang, then the unlocalized value.
+func (e Entry) LocaleString(key string, locale string) string {
+	for _, candidate := range localeCandidates(locale) {
+		if raw, ok := e.values[key+"["+candidate+"]"]; ok {
+			if value := unescapeValue(raw); value != "" {
+				return value
+			}
+		}
+	}
+	return e.String(key)
+}
+
+func (e Entry) Bool(key string) bool {
+	return strings.EqualFold(strings.TrimSpace(e.values[key]), "true")
+}
+
+func (e Entry) List(key string) []string {
+	raw, ok := e.values[key]
+	if !ok {
+		return nil
+	}
+	parts := splitList(raw)
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		result = append(result, part)
+	}
+	return result
+}
+
+// ShowIn reports whether the entry should be displayed in any of the given
+// desktop environments (as listed in $XDG_CURRENT_DESKTOP).
+func (e Entry) ShowIn(desktops []string) bool {
+	if len(e.OnlyShowIn) > 0 && !intersects(e.OnlyShowIn, desktops) {
+		return false
+	}
+	if len(e.NotShowIn) > 0 && intersects(e.NotShowIn, desktops) {
+		return false
+	}
+	return true
+}
+
+// CurrentLocale returns the message locale from the environment, falling
+// back to "C" when none is set.
+func CurrentLocale() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+			return value
+		}
+	}
+	return "C"
+}
+
+// CurrentDesktops splits $XDG_CURRENT_DESKTOP into its components.
+func CurrentDesktops() []string {
+	value := os.Getenv("XDG_CURRENT_DESKTOP")
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	parts := strings.Split(value, ":")
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+func localeCandidates(locale string) []string {
+	locale = strings.TrimSpace(locale)
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return nil
+	}
+
+	modifier := ""
+	if at := strings.IndexByte(locale, '@'); at >= 0 {
+		modifier = locale[at+1:]
+		locale = locale[:at]
+	}
+	if dot := strings.IndexByte(locale, '.'); dot >= 0 {
+		locale = locale[:dot]
+	}
+
+	lang := locale
+	country := ""
+	if underscore := strings.IndexByte(locale, '_'); underscore >= 0 {
+		lang = locale[:underscore]
+		country = locale[underscore+1:]
+	}
+	if lang == "" {
+		return nil
+	}
+
+	candidates := make([]string, 0, 4)
+	if country != "" && modifier != "" {
+		candidates = append(candidates, lang+"_"+country+"@"+modifier)
+	}
+	if country != "" {
+		candidates = append(candidates, lang+"_"+country)
+	}
+	if modifier != "" {
+		candidates = append(candidates, lang+"@"+modifier)
+	}
+	return append(candidates, lang)
+}
+
+func unescapeValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 >= len(value) {
+			builder.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 's':
+			builder.WriteByte(' ')
+		case 'n':
+			builder.WriteByte('\n')
+		case 't':
+			builder.WriteByte('\t')
+		case 'r':
+			builder.WriteByte('\r')
+		case '\\':
+			builder.WriteByte('\\')
+		default:
+			builder.WriteByte('\\')
+			builder.WriteByte(value[i])
+		}
+	}
+	return builder.String()
+}
+
+func splitList(value string) []string {
+	parts := []string{}
+	var current strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value) && value[i+1] == ';':
+			current.WriteByte(';')
+			i++
+		case value[i] == ';':
+			parts = append(parts, unescapeValue(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(value[i])
+		}
+	}
+	if current.Len() > 0 {
+		parts = append(parts, unescapeValue(current.String()))
+	}
+	return parts
+}
+
+func intersects(values []string, candidates []string) bool {
+	for _, value := range values {
+		for _, candidate := range candidates {
+			if strings.EqualFold(value, candidate) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package desktopentry
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// SplitExec tokenizes an Exec value following the quoting rules of the
+// Desktop Entry spec. Field codes are kept verbatim in the result.
+func SplitExec(value string) ([]string, error) {
+	args := []string{}
+	var current strings.Builder
+	inQuotes := false
+	hasToken := false
+
+	for i := 0; i < len(value); i++ {
+		ch := value[i]
+		switch {
+		case inQuotes && ch == '\\':
+			if i+1 >= len(value) {
+				return nil, fmt.Errorf("%w: dangling escape in exec", ErrInvalidEntry)
+			}
+			next := value[i+1]
+			switch next {
+			case '"', '`', '$', '\\':
+				current.WriteByte(next)
+				i++
+			default:
+				current.WriteByte(ch)
+			}
+		case ch == '"':
+			inQuotes = !inQuotes
+			hasToken = true
+		case !inQuotes && (ch == ' ' || ch == '\t'):
+			if hasToken {
+				args = append(args, current.String())
+				current.Reset()
+				hasToken = false
+			}
+		default:
+			current.WriteByte(ch)
+			hasToken = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("%w: unterminated quote in exec", ErrInvalidEntry)
+	}
+	if hasToken {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// ExecProgram returns the program an Exec line ultimately runs, skipping
+// env wrappers and resolving flatpak launches to the application ID.
+func (e Entry) ExecProgram() string {
+	if appID := strings.TrimSpace(e.String("X-Flatpak")); appID != "" {
+		return appID
+	}
+
+	args, err := SplitExec(e.Exec)
+	if err != nil || len(args) == 0 {
+		return ""
+	}
+
+	index := 0
+	if filepath.Base(args[0]) == "env" {
+		index = 1
+		for index < len(args) && (strings.HasPrefix(args[index], "-") || strings.Contains(args[index], "=")) {
+			index++
+		}
+	}
+	if index >= len(args) {
+		return ""
+	}
+
+	program := args[index]
+	if filepath.Base(program) == "flatpak" && index+1 < len(args) && args[index+1] == "run" {
+		for _, arg := range args[index+2:] {
+			if strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "%") || strings.HasPrefix(arg, "@@") {
+				continue
+			}
+			return arg
+		}
+	}
+	return program
+}
+
+// ExpandExec builds the argument vector for launching the entry with the
+// given files or URLs, expanding the %f %F %u %U %i %c %k field codes.
+// location is the path of the .desktop file itself, used for %k.
+func (e Entry) ExpandExec(files []string, location string, locale string) ([]string, error) {
+	tokens, err := SplitExec(e.Exec)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, 0, len(tokens)+len(files))
+	for _, token := range tokens {
+		switch token {
+		case "%F", "%U":
+			args = append(args, files...)
+			continue
+		case "%i":
+			if e.Icon != "" {
+				args = append(args, "--icon", e.Icon)
+			}
+			continue
+		}
+
+		expanded, hadCode, err := expandFieldCodes(token, func(code byte) string {
+			switch code {
+			case 'f', 'u':
+				if len(files) > 0 {
+					return files[0]
+				}
+			case 'c':
+				return e.LocaleString("Name", locale)
+			case 'k':
+				return location
+			}
+			return ""
+		})
+		if err != nil {
+			return nil, err
+		}
+		if expanded == "" && hadCode {
+			continue
+		}
+		args = append(args, expanded)
+	}
+
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%w: empty exec", ErrInvalidEntry)
+	}
+	return args, nil
+}
+
+func expandFieldCodes(token string, lookup func(code byte) string) (string, bool, error) {
+	if !strings.Contains(token, "%") {
+		return token, false, nil
+	}
+
+	var builder strings.Builder
+	hadCode := false
+	for i := 0; i < len(token); i++ {
+		if token[i] != '%' {
+			builder.WriteByte(token[i])
+			continue
+		}
+		if i+1 >= len(token) {
+			return "", false, fmt.Errorf("%w: dangling field code in exec", ErrInvalidEntry)
+		}
+		i++
+		code := token[i]
+		switch code {
+		case '%':
+			builder.WriteByte('%')
+		case 'f', 'u', 'c', 'k':
+			hadCode = true
+			builder.WriteString(lookup(code))
+		case 'F', 'U', 'i':
+			return "", false, fmt.Errorf("%w: %%%c must be a standalone argument", ErrInvalidEntry, code)
+		case 'd', 'D', 'n', 'N', 'v', 'm':
+			// Deprecated field codes are ignored.
+			hadCode = true
+		default:
+			return "", false, fmt.Errorf("%w: unknown field code %%%c", ErrInvalidEntry, code)
+		}
+	}
+	return builder.String(), hadCode, nil
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+type BackupFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// BackupManifest is stored as manifest.json at the root of a backup archive.
+type BackupManifest struct {
+	Format        int          `json:"format"`
+	AppVersion    string       `json:"app_version"`
+	SchemaVersion int          `json:"schema_version"`
+	CreatedAt     time.Time    `json:"created_at"`
+	Files         []BackupFile `json:"files"`
+}
+
+// BackupCount compares how many entries of one kind exist now and in the
+// archive.
+type BackupCount struct {
+	Current int `json:"current"`
+	Backup  int `json:"backup"`
+}
+
+type SettingChange struct {
+	Key     string `json:"key"`
+	Current string `json:"current"`
+	Backup  string `json:"backup"`
+}
+
+// BackupPreview describes what importing an archive would overwrite.
+type BackupPreview struct {
+	Manifest             BackupManifest  `json:"manifest"`
+	CurrentSchemaVersion int             `json:"current_schema_version"`
+	Items                BackupCount     `json:"items"`
+	Groups               BackupCount     `json:"groups"`
+	Launches             BackupCount     `json:"launches"`
+	Icons                BackupCount     `json:"icons"`
+	Rules                BackupCount     `json:"rules"`
+	Settings             []SettingChange `json:"settings"`
+}
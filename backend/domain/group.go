@@ -1,19 +1,70 @@
 package domain
 
+// GroupKind tells how a group gets its items.
+type GroupKind string
+
+const (
+	// GroupKindManual groups hold the items assigned to them.
+	GroupKindManual GroupKind = "manual"
+	// GroupKindSmart groups hold no items of their own; their Query picks
+	// the items they show each time they are listed.
+	GroupKindSmart GroupKind = "smart"
+)
+
+// Group is a tab of the grid. ParentID names the group it is nested in and
+// is empty for top-level groups. Query is only set for smart groups.
 type Group struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Order    int    `json:"order"`
-	Color    string `json:"color"`
-	Category string `json:"category"`
-	Icon     string `json:"icon"`
+	ID       string    `json:"id"`
+	ParentID string    `json:"parent_id"`
+	Name     string    `json:"name"`
+	Order    int       `json:"order"`
+	Color    string    `json:"color"`
+	Category string    `json:"category"`
+	Icon     string    `json:"icon"`
+	Kind     GroupKind `json:"kind"`
+	Query    string    `json:"query"`
 }
 
-// GroupInput describes fields required to create a group.
+// GroupInput describes fields required to create a group. An empty
+// ParentID creates a top-level group and an empty Kind a manual one.
 type GroupInput struct {
-	Name     string `json:"name"`
-	Order    int    `json:"order"`
-	Color    string `json:"color"`
-	Category string `json:"category"`
-	Icon     string `json:"icon"`
+	ParentID string    `json:"parent_id"`
+	Name     string    `json:"name"`
+	Order    int       `json:"order"`
+	Color    string    `json:"color"`
+	Category string    `json:"category"`
+	Icon     string    `json:"icon"`
+	Kind     GroupKind `json:"kind"`
+	Query    string    `json:"query"`
+}
+
+// GroupDeletePolicy decides what happens to the items of a deleted group.
+type GroupDeletePolicy string
+
+const (
+	GroupDeleteMoveItems GroupDeletePolicy = "move"
+	GroupDeleteUngroup   GroupDeletePolicy = "ungroup"
+	GroupDeleteItems     GroupDeletePolicy = "delete_items"
+)
+
+func (p GroupDeletePolicy) IsValid() bool {
+	switch p {
+	case GroupDeleteMoveItems, GroupDeleteUngroup, GroupDeleteItems:
+		return true
+	default:
+		return false
+	}
+}
+
+// GroupDeleteOptions describes how to delete a group. TargetID names the
+// group that receives the items under the move policy.
+type GroupDeleteOptions struct {
+	Policy   GroupDeletePolicy `json:"policy"`
+	TargetID string            `json:"target_id"`
+}
+
+// GroupDeleteResult reports how many items were moved, ungrouped or
+// deleted along with the group.
+type GroupDeleteResult struct {
+	Items int `json:"items"`
 }
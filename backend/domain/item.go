@@ -12,6 +12,17 @@ const (
 	ItemTypeSystem ItemType = "system"
 )
 
+// BrokenReason tells why an item can no longer be launched. It is empty for
+// items that are fine.
+type BrokenReason string
+
+const (
+	// BrokenMissingSource means the item's own file or folder is gone.
+	BrokenMissingSource BrokenReason = "missing_source"
+	// BrokenMissingTarget means the item is a shortcut whose target is gone.
+	BrokenMissingTarget BrokenReason = "missing_target"
+)
+
 type Item struct {
 	ID          string     `json:"id"`
 	Name        string     `json:"name"`
@@ -25,6 +36,11 @@ type Item struct {
 	LaunchCount int64      `json:"launch_count"`
 	LastUsedAt  *time.Time `json:"last_used_at"`
 	Hidden      bool       `json:"hidden"`
+	// Broken is set by the checks that run after each scan.
+	Broken BrokenReason `json:"broken"`
+	// Frecency is derived from launch history when items are listed; it is
+	// not stored.
+	Frecency float64 `json:"frecency"`
 }
 
 type ItemInput struct {
@@ -39,6 +55,26 @@ type ItemInput struct {
 	Hidden     bool     `json:"hidden"`
 }
 
+// UpsertOutcome says what a batch upsert did with one input.
+type UpsertOutcome string
+
+const (
+	UpsertInserted  UpsertOutcome = "inserted"
+	UpsertUpdated   UpsertOutcome = "updated"
+	UpsertUnchanged UpsertOutcome = "unchanged"
+	// UpsertInvalid marks inputs that failed validation and were not
+	// written.
+	UpsertInvalid UpsertOutcome = "invalid"
+)
+
+// ItemUpsert is the outcome of one input of a batch upsert. Item is the
+// stored item afterwards; Before is set for updated items.
+type ItemUpsert struct {
+	Outcome UpsertOutcome `json:"outcome"`
+	Item    Item          `json:"item"`
+	Before  *Item         `json:"before,omitempty"`
+}
+
 type ItemUpdate struct {
 	ID         string   `json:"id"`
 	Name       string   `json:"name"`
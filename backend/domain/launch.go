@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+type LaunchSource string
+
+// Launch sources name the entry point an item was started from. The
+// frontend sends grid and search; the global hotkey and the tray only show
+// the window today, so nothing records hotkey or tray launches yet.
+const (
+	LaunchSourceHotkey LaunchSource = "hotkey"
+	LaunchSourceTray   LaunchSource = "tray"
+	LaunchSourceGrid   LaunchSource = "grid"
+	LaunchSourceSearch LaunchSource = "search"
+)
+
+func (s LaunchSource) IsValid() bool {
+	switch s {
+	case LaunchSourceHotkey, LaunchSourceTray, LaunchSourceGrid, LaunchSourceSearch:
+		return true
+	default:
+		return false
+	}
+}
+
+// Launch is one recorded launch attempt. Failed attempts keep the launcher
+// error so broken entries show up in the statistics.
+type Launch struct {
+	ItemID     string       `json:"item_id"`
+	LaunchedAt time.Time    `json:"launched_at"`
+	Source     LaunchSource `json:"source"`
+	Success    bool         `json:"success"`
+	Error      string       `json:"error"`
+}
+
+// FrecencyBreakdown explains how an item's frecency score was computed from
+// its most recent launches.
+type FrecencyBreakdown struct {
+	ItemID       string           `json:"item_id"`
+	Score        float64          `json:"score"`
+	LaunchCount  int64            `json:"launch_count"`
+	Sampled      int              `json:"sampled"`
+	HalfLifeDays float64          `json:"half_life_days"`
+	Buckets      []FrecencyBucket `json:"buckets"`
+}
+
+// FrecencyBucket is one age bracket of the breakdown. MaxAgeDays is zero for
+// the open-ended last bucket.
+type FrecencyBucket struct {
+	MaxAgeDays float64 `json:"max_age_days"`
+	Weight     float64 `json:"weight"`
+	Launches   int     `json:"launches"`
+	Points     float64 `json:"points"`
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+type OperationKind string
+
+const (
+	OperationRuleImport  OperationKind = "rule_import"
+	OperationClearItems  OperationKind = "clear_items"
+	OperationScan        OperationKind = "scan"
+	OperationBulkEdit    OperationKind = "bulk_edit"
+	OperationDeleteGroup OperationKind = "delete_group"
+)
+
+// Operation is a journaled change that can be undone.
+type Operation struct {
+	ID        string        `json:"id"`
+	Kind      OperationKind `json:"kind"`
+	Summary   string        `json:"summary"`
+	CreatedAt time.Time     `json:"created_at"`
+	UndoneAt  *time.Time    `json:"undone_at"`
+	Items     int           `json:"items"`
+	Groups    int           `json:"groups"`
+}
+
+type OperationEntity string
+
+const (
+	OperationEntityItem  OperationEntity = "item"
+	OperationEntityGroup OperationEntity = "group"
+)
+
+// OperationEntry is the before-image of one item or group touched by an
+// operation. A nil Item or Group means it did not exist before, so undoing
+// deletes it. Launches carries the history of deleted items. After is the
+// row as the operation left it; entries journaled by older versions have
+// none.
+type OperationEntry struct {
+	Entity   OperationEntity    `json:"entity"`
+	EntityID string             `json:"entity_id"`
+	Item     *Item              `json:"item,omitempty"`
+	Group    *Group             `json:"group,omitempty"`
+	Launches []Launch           `json:"launches,omitempty"`
+	After    *OperationSnapshot `json:"after,omitempty"`
+}
+
+// OperationSnapshot is an item or group right after an operation; both
+// fields are nil when the operation deleted it.
+type OperationSnapshot struct {
+	Item  *Item  `json:"item,omitempty"`
+	Group *Group `json:"group,omitempty"`
+}
@@ -1,7 +1,107 @@
 package domain
 
+import "time"
+
 type RuleImportResult struct {
-	GroupsCreated int `json:"groups_created"`
-	GroupsUpdated int `json:"groups_updated"`
-	ItemsUpdated  int `json:"items_updated"`
+	GroupsCreated int            `json:"groups_created"`
+	GroupsUpdated int            `json:"groups_updated"`
+	ItemsUpdated  int            `json:"items_updated"`
+	Conflicts     []RuleConflict `json:"conflicts"`
+}
+
+// RuleExportResult summarizes an exported rule file. Items without a
+// target name are exported as path rules; ItemsSkipped counts grouped
+// items that have neither, which no rule can match.
+type RuleExportResult struct {
+	Groups       int `json:"groups"`
+	Rules        int `json:"rules"`
+	Items        int `json:"items"`
+	ItemsSkipped int `json:"items_skipped"`
+}
+
+// RuleConflict reports an item matched by rules targeting different groups.
+// Group keys are the group ids written in the rule file; GroupKey won and
+// Candidates lists every eligible group in rank order.
+type RuleConflict struct {
+	ItemID     string   `json:"item_id"`
+	ItemName   string   `json:"item_name"`
+	GroupKey   string   `json:"group_key"`
+	Candidates []string `json:"candidates"`
+}
+
+type RuleGroupAction string
+
+const (
+	RuleGroupCreate    RuleGroupAction = "create"
+	RuleGroupUpdate    RuleGroupAction = "update"
+	RuleGroupUnchanged RuleGroupAction = "unchanged"
+)
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// RuleGroupPlan is the state a rule file group will have after import. The
+// group ID is empty for groups that do not exist yet, and so is the
+// ParentID of a group nested in one; ParentKey then names the parent.
+type RuleGroupPlan struct {
+	Key       string          `json:"key"`
+	ParentKey string          `json:"parent_key"`
+	Action    RuleGroupAction `json:"action"`
+	Group     Group           `json:"group"`
+	Changes   []FieldChange   `json:"changes"`
+}
+
+// RuleItemMove is an item the import would move into another group. Rule is
+// the id of the deciding rule, or its position ("#3") when it has none.
+type RuleItemMove struct {
+	ItemID        string `json:"item_id"`
+	ItemName      string `json:"item_name"`
+	FromGroupID   string `json:"from_group_id"`
+	FromGroupName string `json:"from_group_name"`
+	ToGroupKey    string `json:"to_group_key"`
+	ToGroupID     string `json:"to_group_id"`
+	ToGroupName   string `json:"to_group_name"`
+	Rule          string `json:"rule"`
+}
+
+// RuleItemSkip is a rule match that was ignored because the target group's
+// category does not accept the item type.
+type RuleItemSkip struct {
+	ItemID    string   `json:"item_id"`
+	ItemName  string   `json:"item_name"`
+	ItemType  ItemType `json:"item_type"`
+	GroupKey  string   `json:"group_key"`
+	GroupName string   `json:"group_name"`
+	Category  string   `json:"category"`
+	Rule      string   `json:"rule"`
+}
+
+// RuleImportPlan is what ImportGroupRules would do, computed without
+// changing any data.
+type RuleImportPlan struct {
+	Groups    []RuleGroupPlan `json:"groups"`
+	Moves     []RuleItemMove  `json:"moves"`
+	Skipped   []RuleItemSkip  `json:"skipped"`
+	Conflicts []RuleConflict  `json:"conflicts"`
+}
+
+// RuleSet is an imported rule file kept for re-application after scans.
+// GroupIDs maps the group ids written in the file to the groups created or
+// updated for them, so re-importing the file reuses those groups. Source
+// is the path the file was imported from, which identifies the set when
+// files in different folders share a name.
+type RuleSet struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Source     string            `json:"source"`
+	Enabled    bool              `json:"enabled"`
+	ImportedAt time.Time         `json:"imported_at"`
+	GroupIDs   map[string]string `json:"group_ids"`
+	Groups     int               `json:"groups"`
+	Rules      int               `json:"rules"`
+	// Data is the rule file as imported.
+	Data string `json:"-"`
 }
@@ -1,7 +1,72 @@
 package domain
 
+import "time"
+
 type ScanResult struct {
 	Total    int `json:"total"`
 	Inserted int `json:"inserted"`
-	Skipped  int `json:"skipped"`
+	// Updated counts existing items whose type or target changed.
+	Updated int `json:"updated"`
+	// Removed counts broken items deleted under the remove policy.
+	Removed int `json:"removed"`
+	Skipped int `json:"skipped"`
+	// Grouped counts scanned items moved into a group by stored rule sets.
+	Grouped int `json:"grouped"`
+	// Broken counts items whose file or shortcut target is missing.
+	Broken int `json:"broken"`
+}
+
+// ScanIndexEntry is what a scan remembers about one file, so the next scan
+// can skip it when its size and modification time have not changed.
+// Target and Arguments hold the resolved shortcut target, if any.
+type ScanIndexEntry struct {
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"mod_time"`
+	Target    string    `json:"target"`
+	Arguments string    `json:"arguments"`
+}
+
+// SameFile reports whether other describes the same, unmodified file.
+func (e ScanIndexEntry) SameFile(other ScanIndexEntry) bool {
+	return e.Path == other.Path && e.Size == other.Size && e.ModTime.Equal(other.ModTime)
+}
+
+// WatcherStatus describes the file watcher that rescans the scan roots when
+// files below them change.
+type WatcherStatus struct {
+	Enabled     bool `json:"enabled"`
+	Running     bool `json:"running"`
+	Paused      bool `json:"paused"`
+	Directories int  `json:"directories"`
+}
+
+type ScanJobStatus string
+
+const (
+	ScanJobRunning   ScanJobStatus = "running"
+	ScanJobCompleted ScanJobStatus = "completed"
+	ScanJobFailed    ScanJobStatus = "failed"
+	ScanJobCancelled ScanJobStatus = "cancelled"
+)
+
+// ScanSource tells what started a scan.
+type ScanSource string
+
+const (
+	ScanSourceManual  ScanSource = "manual"
+	ScanSourceWatcher ScanSource = "watcher"
+)
+
+// ScanJob is one run of the scanner. Result holds what was written before
+// the job ended, also when it failed or was cancelled.
+type ScanJob struct {
+	ID         string        `json:"id"`
+	Source     ScanSource    `json:"source"`
+	Roots      []string      `json:"roots"`
+	Status     ScanJobStatus `json:"status"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt *time.Time    `json:"finished_at"`
+	Result     ScanResult    `json:"result"`
+	Error      string        `json:"error"`
 }
@@ -0,0 +1,17 @@
+package domain
+
+// MatchRange marks a highlighted span of a matched field. Start and End are
+// UTF-16 offsets so the frontend can slice the string directly; Index is
+// the position within Tags when Field is "tags".
+type MatchRange struct {
+	Field string `json:"field"`
+	Index int    `json:"index"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+type SearchResult struct {
+	Item    Item         `json:"item"`
+	Score   float64      `json:"score"`
+	Matches []MatchRange `json:"matches"`
+}
@@ -4,3 +4,58 @@ type Setting struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
 }
+
+type PanelPositionMode string
+
+const (
+	PanelPositionCenter PanelPositionMode = "center"
+	PanelPositionLast   PanelPositionMode = "last"
+	PanelPositionCursor PanelPositionMode = "cursor"
+)
+
+type LaunchMode string
+
+const (
+	LaunchModeSingle LaunchMode = "single"
+	LaunchModeDouble LaunchMode = "double"
+)
+
+type PanelCloseMode string
+
+const (
+	PanelCloseManual       PanelCloseMode = "manual"
+	PanelCloseLaunch       PanelCloseMode = "launch"
+	PanelCloseBlur         PanelCloseMode = "blur"
+	PanelCloseLaunchOrBlur PanelCloseMode = "launch-or-blur"
+)
+
+// Preferences mirrors the window behaviour options of the settings panel.
+type Preferences struct {
+	FocusSearchOnShow  bool              `json:"focus_search_on_show"`
+	PanelPositionMode  PanelPositionMode `json:"panel_position_mode"`
+	LaunchMode         LaunchMode        `json:"launch_mode"`
+	PanelCloseMode     PanelCloseMode    `json:"panel_close_mode"`
+	LastWindowPosition *Point            `json:"last_window_position"`
+}
+
+// Settings is the typed view of all persisted settings.
+// BrokenItemPolicy decides what a scan does with items that became broken.
+type BrokenItemPolicy string
+
+const (
+	BrokenItemKeep   BrokenItemPolicy = "keep"
+	BrokenItemHide   BrokenItemPolicy = "hide"
+	BrokenItemRemove BrokenItemPolicy = "remove"
+)
+
+type Settings struct {
+	Hotkeys              []HotkeyBinding  `json:"hotkeys"`
+	ScanRoots            []string         `json:"scan_roots"`
+	Preferences          Preferences      `json:"preferences"`
+	FrecencyHalfLifeDays float64          `json:"frecency_half_life_days"`
+	BrokenItemPolicy     BrokenItemPolicy `json:"broken_item_policy"`
+	WatchScanRoots       bool             `json:"watch_scan_roots"`
+	// ScanConcurrency bounds parallel walkers and resolvers; zero means
+	// automatic.
+	ScanConcurrency int `json:"scan_concurrency"`
+}
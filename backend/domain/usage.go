@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// UsageWindow is a rolling period ending now.
+type UsageWindow string
+
+const (
+	UsageWindowDay   UsageWindow = "day"
+	UsageWindowWeek  UsageWindow = "week"
+	UsageWindowMonth UsageWindow = "month"
+)
+
+func (w UsageWindow) Duration() (time.Duration, bool) {
+	switch w {
+	case UsageWindowDay:
+		return 24 * time.Hour, true
+	case UsageWindowWeek:
+		return 7 * 24 * time.Hour, true
+	case UsageWindowMonth:
+		return 30 * 24 * time.Hour, true
+	default:
+		return 0, false
+	}
+}
+
+type ItemUsage struct {
+	ItemID         string               `json:"item_id"`
+	Name           string               `json:"name"`
+	GroupID        string               `json:"group_id"`
+	Launches       int                  `json:"launches"`
+	Failures       int                  `json:"failures"`
+	BySource       map[LaunchSource]int `json:"by_source"`
+	LastLaunchedAt *time.Time           `json:"last_launched_at"`
+}
+
+type GroupUsage struct {
+	GroupID   string `json:"group_id"`
+	Name      string `json:"name"`
+	Items     int    `json:"items"`
+	UsedItems int    `json:"used_items"`
+	Launches  int    `json:"launches"`
+	Failures  int    `json:"failures"`
+}
+
+// UsageHeatmap counts launches by local weekday (0 = Sunday) and hour.
+type UsageHeatmap struct {
+	Window UsageWindow `json:"window"`
+	ItemID string      `json:"item_id"`
+	Total  int         `json:"total"`
+	Hours  [24]int     `json:"hours"`
+	Cells  [7][24]int  `json:"cells"`
+}
@@ -0,0 +1,145 @@
+package frecency
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"rungrid/backend/domain"
+)
+
+const day = 24 * time.Hour
+
+// Bucket weights launches younger than MaxAge. A zero MaxAge matches any
+// age and should come last.
+type Bucket struct {
+	MaxAge time.Duration
+	Weight float64
+}
+
+type Config struct {
+	// HalfLife is the age at which a launch is worth half of its bucket
+	// weight.
+	HalfLife time.Duration
+	// SampleSize caps how many recent launches are weighed per item.
+	SampleSize int
+	Buckets    []Bucket
+}
+
+func DefaultConfig() Config {
+	return Config{
+		HalfLife:   14 * day,
+		SampleSize: 10,
+		Buckets: []Bucket{
+			{MaxAge: 4 * day, Weight: 100},
+			{MaxAge: 14 * day, Weight: 70},
+			{MaxAge: 31 * day, Weight: 50},
+			{MaxAge: 90 * day, Weight: 30},
+			{Weight: 10},
+		},
+	}
+}
+
+// Scorer turns launch history into a frecency score: the average decayed
+// bucket weight of the sampled launches, scaled by the total launch count.
+// Old bursts of activity fade out, and a single launch of a rarely used
+// item cannot outrank a steady favourite.
+type Scorer struct {
+	mu     sync.RWMutex
+	config Config
+}
+
+func NewScorer(config Config) *Scorer {
+	return &Scorer{config: normalize(config)}
+}
+
+func (s *Scorer) Config() Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+func (s *Scorer) SetConfig(config Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = normalize(config)
+}
+
+func (s *Scorer) SampleSize() int {
+	return s.Config().SampleSize
+}
+
+func (s *Scorer) Score(total int64, recent []time.Time, now time.Time) float64 {
+	return s.Breakdown(total, recent, now).Score
+}
+
+// Breakdown scores an item with total recorded launches, of which recent
+// holds the latest timestamps in any order.
+func (s *Scorer) Breakdown(total int64, recent []time.Time, now time.Time) domain.FrecencyBreakdown {
+	config := s.Config()
+
+	samples := append([]time.Time(nil), recent...)
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].After(samples[j])
+	})
+	if len(samples) > config.SampleSize {
+		samples = samples[:config.SampleSize]
+	}
+	if total < int64(len(samples)) {
+		total = int64(len(samples))
+	}
+
+	breakdown := domain.FrecencyBreakdown{
+		LaunchCount:  total,
+		Sampled:      len(samples),
+		HalfLifeDays: config.HalfLife.Hours() / 24,
+		Buckets:      make([]domain.FrecencyBucket, len(config.Buckets)),
+	}
+	for i, bucket := range config.Buckets {
+		breakdown.Buckets[i] = domain.FrecencyBucket{
+			MaxAgeDays: bucket.MaxAge.Hours() / 24,
+			Weight:     bucket.Weight,
+		}
+	}
+	if len(samples) == 0 {
+		return breakdown
+	}
+
+	points := 0.0
+	for _, launchedAt := range samples {
+		age := max(now.Sub(launchedAt), 0)
+		index := bucketIndex(config.Buckets, age)
+		value := config.Buckets[index].Weight * math.Exp2(-float64(age)/float64(config.HalfLife))
+		breakdown.Buckets[index].Launches++
+		breakdown.Buckets[index].Points += value
+		points += value
+	}
+
+	breakdown.Score = float64(total) * points / float64(len(samples))
+	return breakdown
+}
+
+func bucketIndex(buckets []Bucket, age time.Duration) int {
+	for i, bucket := range buckets {
+		if bucket.MaxAge <= 0 || age < bucket.MaxAge {
+			return i
+		}
+	}
+	return len(buckets) - 1
+}
+
+func normalize(config Config) Config {
+	defaults := DefaultConfig()
+	if config.HalfLife <= 0 {
+		config.HalfLife = defaults.HalfLife
+	}
+	if config.SampleSize <= 0 {
+		config.SampleSize = defaults.SampleSize
+	}
+	if len(config.Buckets) == 0 {
+		config.Buckets = defaults.Buckets
+	}
+	config.Buckets = append([]Bucket(nil), config.Buckets...)
+	return config
+}
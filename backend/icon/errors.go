@@ -3,3 +3,7 @@ package icon
 import "errors"
 
 var ErrUnsupported = errors.New("icon extractor not supported")
+
+// ErrNotFound is returned when a source names no icon the extractor can
+// locate.
+var ErrNotFound = errors.New("icon not found")
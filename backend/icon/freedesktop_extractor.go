@@ -0,0 +1,197 @@
+//go:build !windows
+
+package icon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"rungrid/backend/desktopentry"
+)
+
+// FreedesktopExtractor resolves icons through the freedesktop icon theme
+// spec and renders them to a normalized PNG without cgo.
+type FreedesktopExtractor struct {
+	resolver *themeResolver
+}
+
+func NewDefaultExtractor() Extractor {
+	return &FreedesktopExtractor{resolver: newThemeResolver()}
+}
+
+func (e *FreedesktopExtractor) Extract(ctx context.Context, source string, dest string) error {
+	if err := ValidateSource(source); err != nil {
+		return err
+	}
+
+	iconFile := e.resolveIconFile(source)
+	if iconFile == "" {
+		return ErrNotFound
+	}
+
+	img, err := decodeIconFile(ctx, iconFile)
+	if err != nil {
+		return err
+	}
+
+	return writePNG(normalizeIcon(img, normalizedIconSize), dest)
+}
+
+func (e *FreedesktopExtractor) resolveIconFile(source string) string {
+	ext := strings.ToLower(filepath.Ext(source))
+	if isImageExtension(ext) {
+		return source
+	}
+
+	if ext == ".desktop" {
+		entry, err := desktopentry.ParseFile(source)
+		if err != nil {
+			return ""
+		}
+		if path := e.resolveIconName(entry.Icon, filepath.Dir(source)); path != "" {
+			return path
+		}
+		if program := filepath.Base(entry.ExecProgram()); program != "." && program != "" {
+			if path := e.resolver.Lookup(strings.ToLower(program), normalizedIconSize); path != "" {
+				return path
+			}
+		}
+		return e.resolver.Lookup("application-x-executable", normalizedIconSize)
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return ""
+	}
+	if info.IsDir() {
+		return e.resolver.Lookup("folder", normalizedIconSize)
+	}
+	if info.Mode().Perm()&0o111 != 0 {
+		name := strings.ToLower(strings.TrimSuffix(filepath.Base(source), filepath.Ext(source)))
+		if path := e.resolver.Lookup(name, normalizedIconSize); path != "" {
+			return path
+		}
+		return e.resolver.Lookup("application-x-executable", normalizedIconSize)
+	}
+	return e.resolver.Lookup(documentIconName(ext), normalizedIconSize)
+}
+
+// resolveIconName handles the Icon key, which is either an absolute path
+// or a theme icon name (optionally with a stray extension).
+func (e *FreedesktopExtractor) resolveIconName(value string, baseDir string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	if filepath.IsAbs(value) {
+		if fileExists(value) {
+			return value
+		}
+		return ""
+	}
+	if strings.ContainsRune(value, filepath.Separator) {
+		candidate := filepath.Join(baseDir, value)
+		if fileExists(candidate) {
+			return candidate
+		}
+		return ""
+	}
+	if ext := strings.ToLower(filepath.Ext(value)); isImageExtension(ext) {
+		value = strings.TrimSuffix(value, filepath.Ext(value))
+	}
+	return e.resolver.Lookup(value, normalizedIconSize)
+}
+
+func decodeIconFile(ctx context.Context, path string) (image.Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(data, pngSignature):
+		return png.Decode(bytes.NewReader(data))
+	case bytes.HasPrefix(data, []byte{0, 0, 1, 0}):
+		return decodeICO(data)
+	case bytes.Contains(data[:min(len(data), 64)], []byte("XPM")):
+		return decodeXPM(data)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".svg") {
+		return rasterizeSVG(ctx, path)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// rasterizeSVG shells out to librsvg when it is installed; there is no
+// pure-Go SVG renderer in our dependency set.
+func rasterizeSVG(ctx context.Context, path string) (image.Image, error) {
+	program, err := exec.LookPath("rsvg-convert")
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	size := fmt.Sprint(normalizedIconSize)
+	output, err := exec.CommandContext(ctx, program, "-w", size, "-h", size, "-a", "-f", "png", path).Output()
+	if err != nil {
+		return nil, err
+	}
+	return png.Decode(bytes.NewReader(output))
+}
+
+func writePNG(img image.Image, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(dest), ".icon-*.png")
+	if err != nil {
+		return err
+	}
+	tempName := temp.Name()
+	if err := png.Encode(temp, img); err != nil {
+		_ = temp.Close()
+		_ = os.Remove(tempName)
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		_ = os.Remove(tempName)
+		return err
+	}
+	return os.Rename(tempName, dest)
+}
+
+func isImageExtension(ext string) bool {
+	switch ext {
+	case ".png", ".xpm", ".svg", ".ico", ".jpg", ".jpeg", ".gif":
+		return true
+	default:
+		return false
+	}
+}
+
+func documentIconName(ext string) string {
+	switch ext {
+	case ".pdf":
+		return "application-pdf"
+	case ".htm", ".html", ".mht", ".mhtml":
+		return "text-html"
+	case ".doc", ".docx", ".odt", ".rtf":
+		return "x-office-document"
+	case ".xls", ".xlsx", ".ods", ".csv":
+		return "x-office-spreadsheet"
+	case ".ppt", ".pptx", ".odp":
+		return "x-office-presentation"
+	default:
+		return "text-x-generic"
+	}
+}
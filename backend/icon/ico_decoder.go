@@ -0,0 +1,158 @@
+//go:build !windows
+
+package icon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+var errInvalidICO = errors.New("invalid ico data")
+
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+type icoEntry struct {
+	width  int
+	height int
+	bpp    int
+	size   int
+	offset int
+}
+
+// decodeICO returns the largest, deepest image stored in an ICO container.
+// Entries may hold either embedded PNG data or a headerless BMP (DIB).
+func decodeICO(data []byte) (image.Image, error) {
+	if len(data) < 6 || binary.LittleEndian.Uint16(data[0:2]) != 0 || binary.LittleEndian.Uint16(data[2:4]) != 1 {
+		return nil, errInvalidICO
+	}
+	count := int(binary.LittleEndian.Uint16(data[4:6]))
+	if count == 0 || len(data) < 6+count*16 {
+		return nil, errInvalidICO
+	}
+
+	var best *icoEntry
+	for i := 0; i < count; i++ {
+		raw := data[6+i*16 : 6+(i+1)*16]
+		entry := icoEntry{
+			width:  int(raw[0]),
+			height: int(raw[1]),
+			bpp:    int(binary.LittleEndian.Uint16(raw[6:8])),
+			size:   int(binary.LittleEndian.Uint32(raw[8:12])),
+			offset: int(binary.LittleEndian.Uint32(raw[12:16])),
+		}
+		if entry.width == 0 {
+			entry.width = 256
+		}
+		if entry.height == 0 {
+			entry.height = 256
+		}
+		if entry.offset < 0 || entry.size <= 0 || entry.offset+entry.size > len(data) {
+			continue
+		}
+		if best == nil || entry.width > best.width || (entry.width == best.width && entry.bpp > best.bpp) {
+			candidate := entry
+			best = &candidate
+		}
+	}
+	if best == nil {
+		return nil, errInvalidICO
+	}
+
+	payload := data[best.offset : best.offset+best.size]
+	if bytes.HasPrefix(payload, pngSignature) {
+		return png.Decode(bytes.NewReader(payload))
+	}
+	return decodeDIB(payload)
+}
+
+func decodeDIB(data []byte) (image.Image, error) {
+	if len(data) < 40 {
+		return nil, errInvalidICO
+	}
+	headerSize := int(binary.LittleEndian.Uint32(data[0:4]))
+	width := int(int32(binary.LittleEndian.Uint32(data[4:8])))
+	height := int(int32(binary.LittleEndian.Uint32(data[8:12]))) / 2
+	bpp := int(binary.LittleEndian.Uint16(data[14:16]))
+	compression := binary.LittleEndian.Uint32(data[16:20])
+	colorsUsed := int(binary.LittleEndian.Uint32(data[32:36]))
+	if width <= 0 || height <= 0 || width > 1024 || height > 1024 || compression != 0 || headerSize < 40 {
+		return nil, errInvalidICO
+	}
+
+	offset := headerSize
+	var palette []color.NRGBA
+	if bpp <= 8 {
+		if colorsUsed == 0 {
+			colorsUsed = 1 << bpp
+		}
+		if offset+colorsUsed*4 > len(data) {
+			return nil, errInvalidICO
+		}
+		palette = make([]color.NRGBA, colorsUsed)
+		for i := range palette {
+			entry := data[offset+i*4:]
+			palette[i] = color.NRGBA{R: entry[2], G: entry[1], B: entry[0], A: 0xff}
+		}
+		offset += colorsUsed * 4
+	}
+
+	stride := ((width*bpp + 31) / 32) * 4
+	maskStride := ((width + 31) / 32) * 4
+	if offset+stride*height > len(data) {
+		return nil, errInvalidICO
+	}
+	hasMask := offset+stride*height+maskStride*height <= len(data)
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	hasAlpha := false
+	for y := 0; y < height; y++ {
+		row := data[offset+(height-1-y)*stride:]
+		for x := 0; x < width; x++ {
+			var c color.NRGBA
+			switch bpp {
+			case 32:
+				p := row[x*4:]
+				c = color.NRGBA{R: p[2], G: p[1], B: p[0], A: p[3]}
+				if p[3] != 0 {
+					hasAlpha = true
+				}
+			case 24:
+				p := row[x*3:]
+				c = color.NRGBA{R: p[2], G: p[1], B: p[0], A: 0xff}
+			case 8, 4, 1:
+				bitOffset := x * bpp
+				value := int(row[bitOffset/8]>>(8-bpp-bitOffset%8)) & (1<<bpp - 1)
+				if value < len(palette) {
+					c = palette[value]
+				}
+			default:
+				return nil, errInvalidICO
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	// Images without an alpha channel rely on the 1-bit AND mask.
+	if hasMask && !hasAlpha {
+		maskOffset := offset + stride*height
+		for y := 0; y < height; y++ {
+			row := data[maskOffset+(height-1-y)*maskStride:]
+			for x := 0; x < width; x++ {
+				transparent := row[x/8]&(0x80>>(x%8)) != 0
+				c := img.NRGBAAt(x, y)
+				if transparent {
+					c.A = 0
+				} else {
+					c.A = 0xff
+				}
+				img.SetNRGBA(x, y, c)
+			}
+		}
+	}
+
+	return img, nil
+}
@@ -0,0 +1,120 @@
+//go:build !windows
+
+package icon
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+const normalizedIconSize = 128
+
+// normalizeIcon fits src into a size x size transparent canvas, keeping the
+// aspect ratio. Larger images are box-filtered, smaller ones bilinearly
+// interpolated.
+func normalizeIcon(src image.Image, size int) *image.NRGBA {
+	bounds := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	if bounds.Dx() <= 0 || bounds.Dy() <= 0 {
+		return dst
+	}
+
+	srcRGBA := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(srcRGBA, srcRGBA.Bounds(), src, bounds.Min, draw.Src)
+
+	scaledW, scaledH := size, size
+	if bounds.Dx() > bounds.Dy() {
+		scaledH = max(1, bounds.Dy()*size/bounds.Dx())
+	} else if bounds.Dy() > bounds.Dx() {
+		scaledW = max(1, bounds.Dx()*size/bounds.Dy())
+	}
+	offsetX := (size - scaledW) / 2
+	offsetY := (size - scaledH) / 2
+
+	scaleX := float64(bounds.Dx()) / float64(scaledW)
+	scaleY := float64(bounds.Dy()) / float64(scaledH)
+	downscale := scaleX >= 1 && scaleY >= 1
+
+	for y := 0; y < scaledH; y++ {
+		for x := 0; x < scaledW; x++ {
+			var c color.NRGBA
+			if downscale {
+				c = boxSample(srcRGBA, float64(x)*scaleX, float64(y)*scaleY, scaleX, scaleY)
+			} else {
+				c = bilinearSample(srcRGBA, (float64(x)+0.5)*scaleX-0.5, (float64(y)+0.5)*scaleY-0.5)
+			}
+			dst.SetNRGBA(offsetX+x, offsetY+y, c)
+		}
+	}
+	return dst
+}
+
+func boxSample(src *image.NRGBA, x0, y0, w, h float64) color.NRGBA {
+	startX, startY := int(x0), int(y0)
+	endX := min(src.Rect.Dx(), max(startX+1, int(x0+w+0.5)))
+	endY := min(src.Rect.Dy(), max(startY+1, int(y0+h+0.5)))
+
+	var r, g, b, a, count float64
+	for y := startY; y < endY; y++ {
+		for x := startX; x < endX; x++ {
+			c := src.NRGBAAt(x, y)
+			alpha := float64(c.A)
+			r += float64(c.R) * alpha
+			g += float64(c.G) * alpha
+			b += float64(c.B) * alpha
+			a += alpha
+			count++
+		}
+	}
+	if count == 0 || a == 0 {
+		return color.NRGBA{}
+	}
+	return color.NRGBA{
+		R: uint8(r/a + 0.5),
+		G: uint8(g/a + 0.5),
+		B: uint8(b/a + 0.5),
+		A: uint8(a/count + 0.5),
+	}
+}
+
+func bilinearSample(src *image.NRGBA, fx, fy float64) color.NRGBA {
+	maxX := src.Rect.Dx() - 1
+	maxY := src.Rect.Dy() - 1
+	fx = clampFloat(fx, 0, float64(maxX))
+	fy = clampFloat(fy, 0, float64(maxY))
+	x0, y0 := int(fx), int(fy)
+	x1, y1 := min(x0+1, maxX), min(y0+1, maxY)
+	dx, dy := fx-float64(x0), fy-float64(y0)
+
+	weights := [4]float64{(1 - dx) * (1 - dy), dx * (1 - dy), (1 - dx) * dy, dx * dy}
+	samples := [4]color.NRGBA{src.NRGBAAt(x0, y0), src.NRGBAAt(x1, y0), src.NRGBAAt(x0, y1), src.NRGBAAt(x1, y1)}
+
+	var r, g, b, a float64
+	for i, c := range samples {
+		weight := weights[i] * float64(c.A)
+		r += float64(c.R) * weight
+		g += float64(c.G) * weight
+		b += float64(c.B) * weight
+		a += weight
+	}
+	if a == 0 {
+		return color.NRGBA{}
+	}
+	return color.NRGBA{
+		R: uint8(r/a + 0.5),
+		G: uint8(g/a + 0.5),
+		B: uint8(b/a + 0.5),
+		A: uint8(a + 0.5),
+	}
+}
+
+func clampFloat(value, low, high float64) float64 {
+	if value < low {
+		return low
+	}
+	if value > high {
+		return high
+	}
+	return value
+}
@@ -11,6 +11,8 @@ import (
 
 	"github.com/go-ole/go-ole"
 	"github.com/go-ole/go-ole/oleutil"
+
+	"rungrid/backend/shelllink"
 )
 
 type shortcutIconInfo struct {
@@ -24,6 +26,19 @@ func resolveShortcutIcon(path string) (shortcutIconInfo, error) {
 		return info, nil
 	}
 
+	if link, err := shelllink.ParseFile(path); err == nil && !link.Advertised() {
+		iconCandidate, iconIndex := parseIconLocation(link.IconLocation)
+		if link.IconEnvironmentTarget != "" {
+			iconCandidate = strings.Trim(link.IconEnvironmentTarget, "\"'")
+		}
+		if iconCandidate != "" && !strings.Contains(link.IconLocation, ",") {
+			iconIndex = int(link.IconIndex)
+		}
+		if parsed, ok := shortcutIconFromCandidates(path, iconCandidate, iconIndex, link.Target()); ok {
+			return parsed, nil
+		}
+	}
+
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
 
@@ -74,6 +89,14 @@ func resolveShortcutIcon(path string) (shortcutIconInfo, error) {
 	}
 
 	iconCandidate, iconIndex := parseIconLocation(iconLocationValue)
+	if resolved, ok := shortcutIconFromCandidates(path, iconCandidate, iconIndex, targetValue); ok {
+		info = resolved
+	}
+
+	return info, nil
+}
+
+func shortcutIconFromCandidates(path, iconCandidate string, iconIndex int, targetValue string) (shortcutIconInfo, bool) {
 	if strings.EqualFold(filepath.Ext(iconCandidate), ".lnk") {
 		iconCandidate = ""
 		iconIndex = 0
@@ -85,12 +108,10 @@ func resolveShortcutIcon(path string) (shortcutIconInfo, error) {
 	}
 
 	resolved := resolveIconCandidate(path, iconCandidate)
-	if resolved != "" {
-		info.source = resolved
-		info.index = iconIndex
+	if resolved == "" {
+		return shortcutIconInfo{}, false
 	}
-
-	return info, nil
+	return shortcutIconInfo{source: resolved, index: iconIndex}, true
 }
 
 func parseIconLocation(value string) (string, int) {
@@ -0,0 +1,437 @@
+//go:build !windows
+
+package icon
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const fallbackThemeName = "hicolor"
+
+var rasterExtensions = []string{".png", ".xpm"}
+
+var vectorExtensions = []string{".svg"}
+
+type themeDirectory struct {
+	path      string
+	size      int
+	scale     int
+	minSize   int
+	maxSize   int
+	threshold int
+	kind      string
+}
+
+type iconTheme struct {
+	name     string
+	roots    []string
+	dirs     []themeDirectory
+	inherits []string
+}
+
+// themeResolver implements the lookup algorithm of the freedesktop icon
+// theme spec. Parsed index.theme files are cached for the resolver's life.
+type themeResolver struct {
+	baseDirs []string
+	theme    string
+
+	mu     sync.Mutex
+	themes map[string]*iconTheme
+}
+
+func newThemeResolver() *themeResolver {
+	return &themeResolver{
+		baseDirs: iconBaseDirs(),
+		theme:    currentIconTheme(),
+		themes:   map[string]*iconTheme{},
+	}
+}
+
+// Lookup returns the best file for name at size. Raster images are
+// preferred over SVG anywhere in the theme chain because SVG rendering is
+// only available through an external tool.
+func (r *themeResolver) Lookup(name string, size int) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ""
+	}
+	for _, extensions := range [][]string{rasterExtensions, vectorExtensions} {
+		for _, candidate := range iconNameFallbacks(name) {
+			if path := r.lookupInChain(candidate, size, extensions); path != "" {
+				return path
+			}
+		}
+		if path := r.lookupFallback(name, extensions); path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+func (r *themeResolver) lookupInChain(name string, size int, extensions []string) string {
+	visited := map[string]struct{}{}
+	for _, themeName := range []string{r.theme, fallbackThemeName} {
+		if path := r.lookupInTheme(themeName, name, size, extensions, visited); path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+func (r *themeResolver) lookupInTheme(themeName, name string, size int, extensions []string, visited map[string]struct{}) string {
+	if themeName == "" {
+		return ""
+	}
+	if _, ok := visited[themeName]; ok {
+		return ""
+	}
+	visited[themeName] = struct{}{}
+
+	theme := r.loadTheme(themeName)
+	if theme == nil {
+		return ""
+	}
+	if path := lookupIcon(theme, name, size, extensions); path != "" {
+		return path
+	}
+	for _, parent := range theme.inherits {
+		if path := r.lookupInTheme(parent, name, size, extensions, visited); path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+func (r *themeResolver) lookupFallback(name string, extensions []string) string {
+	for _, base := range r.baseDirs {
+		for _, ext := range extensions {
+			path := filepath.Join(base, name+ext)
+			if fileExists(path) {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+func (r *themeResolver) loadTheme(name string) *iconTheme {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if theme, ok := r.themes[name]; ok {
+		return theme
+	}
+
+	var theme *iconTheme
+	for _, base := range r.baseDirs {
+		root := filepath.Join(base, name)
+		info, err := os.Stat(root)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		if theme == nil {
+			parsed, err := parseIndexTheme(filepath.Join(root, "index.theme"))
+			if err != nil {
+				continue
+			}
+			parsed.name = name
+			theme = parsed
+		}
+		theme.roots = append(theme.roots, root)
+	}
+
+	r.themes[name] = theme
+	return theme
+}
+
+func lookupIcon(theme *iconTheme, name string, size int, extensions []string) string {
+	for _, dir := range theme.dirs {
+		if !directoryMatchesSize(dir, size) {
+			continue
+		}
+		if path := findInDirectory(theme.roots, dir.path, name, extensions); path != "" {
+			return path
+		}
+	}
+
+	best := ""
+	bestDistance := int(^uint(0) >> 1)
+	for _, dir := range theme.dirs {
+		distance := directorySizeDistance(dir, size)
+		if distance >= bestDistance {
+			continue
+		}
+		if path := findInDirectory(theme.roots, dir.path, name, extensions); path != "" {
+			best = path
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+func findInDirectory(roots []string, dir string, name string, extensions []string) string {
+	for _, root := range roots {
+		for _, ext := range extensions {
+			path := filepath.Join(root, dir, name+ext)
+			if fileExists(path) {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+func directoryMatchesSize(dir themeDirectory, size int) bool {
+	if dir.scale != 1 {
+		return false
+	}
+	switch dir.kind {
+	case "Fixed":
+		return dir.size == size
+	case "Scalable":
+		return dir.minSize <= size && size <= dir.maxSize
+	default:
+		return dir.size-dir.threshold <= size && size <= dir.size+dir.threshold
+	}
+}
+
+func directorySizeDistance(dir themeDirectory, size int) int {
+	distance := 0
+	switch dir.kind {
+	case "Fixed":
+		distance = abs(dir.size*dir.scale - size)
+	case "Scalable":
+		if size < dir.minSize*dir.scale {
+			distance = dir.minSize*dir.scale - size
+		} else if size > dir.maxSize*dir.scale {
+			distance = size - dir.maxSize*dir.scale
+		}
+	default:
+		if low := (dir.size - dir.threshold) * dir.scale; size < low {
+			distance = low - size
+		} else if high := (dir.size + dir.threshold) * dir.scale; size > high {
+			distance = size - high
+		}
+	}
+	// Downscaling a larger image looks better than blowing up a smaller one.
+	if dir.kind != "Scalable" && dir.size*dir.scale < size {
+		distance *= 2
+	}
+	return distance
+}
+
+func parseIndexTheme(path string) (*iconTheme, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	groups := map[string]map[string]string{}
+	group := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			group = line[1 : len(line)-1]
+			if groups[group] == nil {
+				groups[group] = map[string]string{}
+			}
+			continue
+		}
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 || group == "" {
+			continue
+		}
+		groups[group][strings.TrimSpace(line[:eq])] = strings.TrimSpace(line[eq+1:])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	header := groups["Icon Theme"]
+	theme := &iconTheme{
+		inherits: splitThemeList(header["Inherits"]),
+	}
+
+	dirNames := append(splitThemeList(header["Directories"]), splitThemeList(header["ScaledDirectories"])...)
+	for _, name := range dirNames {
+		values, ok := groups[name]
+		if !ok {
+			continue
+		}
+		size := atoiDefault(values["Size"], 0)
+		if size <= 0 {
+			continue
+		}
+		kind := values["Type"]
+		if kind == "" {
+			kind = "Threshold"
+		}
+		theme.dirs = append(theme.dirs, themeDirectory{
+			path:      name,
+			size:      size,
+			scale:     atoiDefault(values["Scale"], 1),
+			minSize:   atoiDefault(values["MinSize"], size),
+			maxSize:   atoiDefault(values["MaxSize"], size),
+			threshold: atoiDefault(values["Threshold"], 2),
+			kind:      kind,
+		})
+	}
+
+	return theme, nil
+}
+
+func iconBaseDirs() []string {
+	dirs := []string{}
+	home, _ := os.UserHomeDir()
+	if home != "" {
+		dirs = append(dirs, filepath.Join(home, ".icons"))
+	}
+
+	dataHome := strings.TrimSpace(os.Getenv("XDG_DATA_HOME"))
+	if dataHome == "" && home != "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	if dataHome != "" {
+		dirs = append(dirs,
+			filepath.Join(dataHome, "icons"),
+			filepath.Join(dataHome, "flatpak", "exports", "share", "icons"),
+		)
+	}
+
+	dataDirs := strings.TrimSpace(os.Getenv("XDG_DATA_DIRS"))
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range strings.Split(dataDirs, ":") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			dirs = append(dirs, filepath.Join(dir, "icons"))
+		}
+	}
+
+	return append(dirs,
+		"/var/lib/flatpak/exports/share/icons",
+		"/usr/share/pixmaps",
+	)
+}
+
+// currentIconTheme reads the icon theme configured for GTK or KDE, which
+// covers the desktops RunGrid is run on. Unknown setups fall back to hicolor.
+func currentIconTheme() string {
+	configHome := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME"))
+	if configHome == "" {
+		if home, err := os.UserHomeDir(); err == nil && home != "" {
+			configHome = filepath.Join(home, ".config")
+		}
+	}
+
+	if configHome != "" {
+		for _, candidate := range []struct {
+			file  string
+			group string
+			key   string
+		}{
+			{filepath.Join(configHome, "kdeglobals"), "Icons", "Theme"},
+			{filepath.Join(configHome, "gtk-4.0", "settings.ini"), "Settings", "gtk-icon-theme-name"},
+			{filepath.Join(configHome, "gtk-3.0", "settings.ini"), "Settings", "gtk-icon-theme-name"},
+		} {
+			if value := readIniValue(candidate.file, candidate.group, candidate.key); value != "" {
+				return value
+			}
+		}
+	}
+
+	desktop := strings.ToUpper(os.Getenv("XDG_CURRENT_DESKTOP"))
+	switch {
+	case strings.Contains(desktop, "KDE"):
+		return "breeze"
+	case strings.Contains(desktop, "GNOME"):
+		return "Adwaita"
+	default:
+		return fallbackThemeName
+	}
+}
+
+func readIniValue(path, group, key string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	current := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = line[1 : len(line)-1]
+			continue
+		}
+		if current != group {
+			continue
+		}
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 || strings.TrimSpace(line[:eq]) != key {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(line[eq+1:]), "\"'")
+	}
+	return ""
+}
+
+// iconNameFallbacks yields name followed by its dash-truncated forms, so
+// "text-x-python" also tries "text-x" and "text".
+func iconNameFallbacks(name string) []string {
+	names := []string{name}
+	for {
+		dash := strings.LastIndexByte(name, '-')
+		if dash <= 0 {
+			return names
+		}
+		name = name[:dash]
+		names = append(names, name)
+	}
+}
+
+func splitThemeList(value string) []string {
+	parts := strings.Split(value, ",")
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+func atoiDefault(value string, fallback int) int {
+	parsed, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
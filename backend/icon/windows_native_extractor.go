@@ -58,7 +58,7 @@ func (NativeExtractor) Extract(_ context.Context, source string, dest string) er
 		return nil
 	}
 
-	return fmt.Errorf("icon not found")
+	return ErrNotFound
 }
 
 func extractIconHandle(path string, index int, size int) (windows.Handle, error) {
@@ -0,0 +1,149 @@
+//go:build !windows
+
+package icon
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+var errInvalidXPM = errors.New("invalid xpm data")
+
+var xpmNamedColors = map[string]color.NRGBA{
+	"black":   {0, 0, 0, 0xff},
+	"white":   {0xff, 0xff, 0xff, 0xff},
+	"red":     {0xff, 0, 0, 0xff},
+	"green":   {0, 0xff, 0, 0xff},
+	"blue":    {0, 0, 0xff, 0xff},
+	"yellow":  {0xff, 0xff, 0, 0xff},
+	"cyan":    {0, 0xff, 0xff, 0xff},
+	"magenta": {0xff, 0, 0xff, 0xff},
+	"gray":    {0xbe, 0xbe, 0xbe, 0xff},
+	"grey":    {0xbe, 0xbe, 0xbe, 0xff},
+}
+
+// decodeXPM parses the XPM3 C-source format used by legacy pixmaps.
+func decodeXPM(data []byte) (image.Image, error) {
+	lines := xpmStrings(string(data))
+	if len(lines) == 0 {
+		return nil, errInvalidXPM
+	}
+
+	header := strings.Fields(lines[0])
+	if len(header) < 4 {
+		return nil, errInvalidXPM
+	}
+	width, errW := strconv.Atoi(header[0])
+	height, errH := strconv.Atoi(header[1])
+	colors, errC := strconv.Atoi(header[2])
+	cpp, errP := strconv.Atoi(header[3])
+	if errW != nil || errH != nil || errC != nil || errP != nil {
+		return nil, errInvalidXPM
+	}
+	if width <= 0 || height <= 0 || cpp <= 0 || width > 1024 || height > 1024 || len(lines) < 1+colors+height {
+		return nil, errInvalidXPM
+	}
+
+	palette := make(map[string]color.NRGBA, colors)
+	for _, line := range lines[1 : 1+colors] {
+		if len(line) < cpp {
+			return nil, errInvalidXPM
+		}
+		palette[line[:cpp]] = parseXPMColor(line[cpp:])
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y, line := range lines[1+colors : 1+colors+height] {
+		for x := 0; x < width && (x+1)*cpp <= len(line); x++ {
+			img.SetNRGBA(x, y, palette[line[x*cpp:(x+1)*cpp]])
+		}
+	}
+	return img, nil
+}
+
+func xpmStrings(source string) []string {
+	result := []string{}
+	for {
+		start := strings.IndexByte(source, '"')
+		if start < 0 {
+			return result
+		}
+		end := strings.IndexByte(source[start+1:], '"')
+		if end < 0 {
+			return result
+		}
+		result = append(result, source[start+1:start+1+end])
+		source = source[start+end+2:]
+	}
+}
+
+// parseXPMColor reads the color for the "c" visual, falling back to the
+// grayscale and monochrome keys when no color key is present.
+func parseXPMColor(spec string) color.NRGBA {
+	fields := strings.Fields(spec)
+	values := map[string]string{}
+	for i := 0; i+1 < len(fields); i++ {
+		switch fields[i] {
+		case "c", "g", "g4", "m", "s":
+			key := fields[i]
+			value := fields[i+1]
+			i++
+			for i+1 < len(fields) && !isXPMKey(fields[i+1]) {
+				value += " " + fields[i+1]
+				i++
+			}
+			values[key] = value
+		}
+	}
+
+	for _, key := range []string{"c", "g", "g4", "m"} {
+		if value, ok := values[key]; ok {
+			return parseColorValue(value)
+		}
+	}
+	return color.NRGBA{}
+}
+
+func isXPMKey(value string) bool {
+	switch value {
+	case "c", "g", "g4", "m", "s":
+		return true
+	default:
+		return false
+	}
+}
+
+func parseColorValue(value string) color.NRGBA {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, "none") {
+		return color.NRGBA{}
+	}
+	if strings.HasPrefix(value, "#") {
+		hex := value[1:]
+		digits := len(hex) / 3
+		if digits == 0 || len(hex)%3 != 0 {
+			return color.NRGBA{}
+		}
+		channel := func(index int) uint8 {
+			parsed, err := strconv.ParseUint(hex[index*digits:(index+1)*digits], 16, 32)
+			if err != nil {
+				return 0
+			}
+			return uint8(parsed * 255 / (1<<(4*uint(digits)) - 1))
+		}
+		return color.NRGBA{R: channel(0), G: channel(1), B: channel(2), A: 0xff}
+	}
+	if named, ok := xpmNamedColors[strings.ToLower(strings.ReplaceAll(value, " ", ""))]; ok {
+		return named
+	}
+	if strings.HasPrefix(strings.ToLower(value), "gray") || strings.HasPrefix(strings.ToLower(value), "grey") {
+		if level, err := strconv.Atoi(value[4:]); err == nil && level >= 0 && level <= 100 {
+			v := uint8(level * 255 / 100)
+			return color.NRGBA{R: v, G: v, B: v, A: 0xff}
+		}
+	}
+	return color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}
+}
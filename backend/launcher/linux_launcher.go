@@ -0,0 +1,159 @@
+//go:build linux
+
+package launcher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"rungrid/backend/desktopentry"
+)
+
+type LinuxLauncher struct{}
+
+func NewDefaultLauncher() Launcher {
+	return LinuxLauncher{}
+}
+
+func (LinuxLauncher) Open(_ context.Context, target string) error {
+	trimmed := strings.TrimSpace(target)
+	if trimmed == "" {
+		return ErrUnsupported
+	}
+
+	if strings.EqualFold(filepath.Ext(trimmed), ".desktop") {
+		if info, err := os.Stat(trimmed); err == nil && info.Mode().IsRegular() {
+			return openDesktopEntry(trimmed)
+		}
+	}
+
+	if info, err := os.Stat(trimmed); err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0 && !hasDocumentExtension(trimmed) {
+		return startDetached([]string{trimmed}, filepath.Dir(trimmed))
+	}
+
+	return openWithDefaultHandler(trimmed)
+}
+
+func openDesktopEntry(path string) error {
+	entry, err := desktopentry.ParseFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch entry.Type {
+	case "Link":
+		if strings.TrimSpace(entry.URL) == "" {
+			return ErrUnsupported
+		}
+		return openWithDefaultHandler(entry.URL)
+	case "Application", "":
+	default:
+		return ErrUnsupported
+	}
+
+	args, err := entry.ExpandExec(nil, path, desktopentry.CurrentLocale())
+	if err != nil {
+		return err
+	}
+	if entry.Terminal {
+		args, err = wrapInTerminal(args)
+		if err != nil {
+			return err
+		}
+	}
+
+	dir := strings.TrimSpace(entry.Path)
+	if dir == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = home
+		}
+	}
+	return startDetached(args, dir)
+}
+
+// openWithDefaultHandler hands target to the desktop's preferred
+// application, the equivalent of ShellExecute "open" on Windows.
+func openWithDefaultHandler(target string) error {
+	openers := [][]string{
+		{"xdg-open"},
+		{"gio", "open"},
+		{"kde-open"},
+	}
+	for _, opener := range openers {
+		program, err := exec.LookPath(opener[0])
+		if err != nil {
+			continue
+		}
+		args := append([]string{program}, opener[1:]...)
+		return startDetached(append(args, target), "")
+	}
+	return ErrUnsupported
+}
+
+func wrapInTerminal(args []string) ([]string, error) {
+	if terminal := strings.TrimSpace(os.Getenv("TERMINAL")); terminal != "" {
+		if program, err := exec.LookPath(terminal); err == nil {
+			return append([]string{program, "-e"}, args...), nil
+		}
+	}
+
+	terminals := []struct {
+		name string
+		flag string
+	}{
+		{"x-terminal-emulator", "-e"},
+		{"gnome-terminal", "--"},
+		{"konsole", "-e"},
+		{"xfce4-terminal", "-x"},
+		{"alacritty", "-e"},
+		{"kitty", ""},
+		{"xterm", "-e"},
+	}
+	for _, terminal := range terminals {
+		program, err := exec.LookPath(terminal.name)
+		if err != nil {
+			continue
+		}
+		wrapped := []string{program}
+		if terminal.flag != "" {
+			wrapped = append(wrapped, terminal.flag)
+		}
+		return append(wrapped, args...), nil
+	}
+	return nil, errors.New("no terminal emulator found")
+}
+
+// startDetached runs args in a new session so the child outlives RunGrid
+// and is not tied to the caller's context.
+func startDetached(args []string, dir string) error {
+	if len(args) == 0 {
+		return ErrUnsupported
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = nil
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() {
+		_ = cmd.Wait()
+	}()
+	return nil
+}
+
+func hasDocumentExtension(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt", ".md", ".pdf", ".html", ".htm", ".png", ".jpg", ".jpeg":
+		return true
+	default:
+		return false
+	}
+}
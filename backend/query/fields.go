@@ -0,0 +1,258 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"rungrid/backend/domain"
+)
+
+// The fields a term can name:
+//
+//	name, path, target  the text contains value, ignoring case; = compares
+//	                    the whole text
+//	type                the item type: app, url, folder, doc or system
+//	tag                 the item has the tag, ignoring case
+//	is                  favorite, hidden, broken or ungrouped
+//	launches            the launch count
+//	used                the last launch, compared with a date
+//
+// A date is never (used:never only), today, week, month or year for the
+// start of the current period, a span such as 12h, 7d or 2w counted back
+// from now, or a day such as 2024-05-01. used:X is short for used>=X, so
+// used:week matches items launched this week; items that were never
+// launched match no date comparison.
+const (
+	opContains = ":"
+	opEqual    = "="
+	opLess     = "<"
+	opLessEq   = "<="
+	opMore     = ">"
+	opMoreEq   = ">="
+)
+
+var itemTypes = map[string]domain.ItemType{
+	"app":    domain.ItemTypeApp,
+	"url":    domain.ItemTypeURL,
+	"folder": domain.ItemTypeFolder,
+	"doc":    domain.ItemTypeDoc,
+	"system": domain.ItemTypeSystem,
+}
+
+// parseTerm compiles one term. Text without a field prefix is a bare word.
+func parseTerm(text string, pos int) (matcher, error) {
+	index := strings.IndexAny(text, ":=<>")
+	if index <= 0 || !isFieldName(text[:index]) {
+		if text == "" {
+			return nil, fmt.Errorf("missing term at %d", pos+1)
+		}
+		return nameContains(text), nil
+	}
+
+	field := strings.ToLower(text[:index])
+	op := text[index : index+1]
+	if rest := text[index+1:]; (op == opLess || op == opMore) && strings.HasPrefix(rest, "=") {
+		op += "="
+	}
+	value := strings.TrimSpace(text[index+len(op):])
+	if value == "" {
+		return nil, fmt.Errorf("%s: missing value at %d", field, pos+1)
+	}
+
+	var match matcher
+	var err error
+	switch field {
+	case "name":
+		match, err = textTerm(op, value, func(item domain.Item) string { return item.Name })
+	case "path":
+		match, err = textTerm(op, value, func(item domain.Item) string { return item.Path })
+	case "target":
+		match, err = textTerm(op, value, func(item domain.Item) string { return item.TargetName })
+	case "type":
+		match, err = typeTerm(op, value)
+	case "tag":
+		match, err = tagTerm(op, value)
+	case "is":
+		match, err = flagTerm(op, value)
+	case "launches":
+		match, err = launchesTerm(op, value)
+	case "used":
+		match, err = usedTerm(op, value)
+	default:
+		return nil, fmt.Errorf("unknown field %q at %d", field, pos+1)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s at %d: %w", field, pos+1, err)
+	}
+	return match, nil
+}
+
+func isFieldName(text string) bool {
+	for _, r := range text {
+		if !unicode.IsLetter(r) && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+func nameContains(value string) matcher {
+	value = strings.ToLower(value)
+	return func(s *subject) bool {
+		return strings.Contains(strings.ToLower(s.item.Name), value)
+	}
+}
+
+func textTerm(op, value string, field func(domain.Item) string) (matcher, error) {
+	value = strings.ToLower(value)
+	switch op {
+	case opContains:
+		return func(s *subject) bool {
+			return strings.Contains(strings.ToLower(field(s.item)), value)
+		}, nil
+	case opEqual:
+		return func(s *subject) bool {
+			return strings.ToLower(field(s.item)) == value
+		}, nil
+	}
+	return nil, fmt.Errorf("%s cannot compare text", op)
+}
+
+func typeTerm(op, value string) (matcher, error) {
+	if op != opContains && op != opEqual {
+		return nil, fmt.Errorf("%s cannot compare types", op)
+	}
+	itemType, ok := itemTypes[strings.ToLower(value)]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", value)
+	}
+	return func(s *subject) bool { return s.item.Type == itemType }, nil
+}
+
+func tagTerm(op, value string) (matcher, error) {
+	if op != opContains && op != opEqual {
+		return nil, fmt.Errorf("%s cannot compare tags", op)
+	}
+	return func(s *subject) bool {
+		for _, tag := range s.item.Tags {
+			if strings.EqualFold(strings.TrimSpace(tag), value) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func flagTerm(op, value string) (matcher, error) {
+	if op != opContains {
+		return nil, fmt.Errorf("is takes no comparison")
+	}
+	switch strings.ToLower(value) {
+	case "favorite":
+		return func(s *subject) bool { return s.item.Favorite }, nil
+	case "hidden":
+		return func(s *subject) bool { return s.item.Hidden }, nil
+	case "broken":
+		return func(s *subject) bool { return s.item.Broken != "" }, nil
+	case "ungrouped":
+		return func(s *subject) bool { return s.item.GroupID == "" }, nil
+	}
+	return nil, fmt.Errorf("unknown flag %q", value)
+}
+
+func launchesTerm(op, value string) (matcher, error) {
+	count, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || count < 0 {
+		return nil, fmt.Errorf("invalid count %q", value)
+	}
+	return func(s *subject) bool {
+		return compare(op, s.item.LaunchCount, count)
+	}, nil
+}
+
+func usedTerm(op, value string) (matcher, error) {
+	if strings.EqualFold(value, "never") {
+		if op != opContains {
+			return nil, fmt.Errorf("never cannot be compared")
+		}
+		return func(s *subject) bool { return s.item.LastUsedAt == nil }, nil
+	}
+	if op == opEqual {
+		return nil, fmt.Errorf("= cannot compare dates")
+	}
+	if op == opContains {
+		op = opMoreEq
+	}
+	at, err := parseDate(value)
+	if err != nil {
+		return nil, err
+	}
+	return func(s *subject) bool {
+		if s.item.LastUsedAt == nil {
+			return false
+		}
+		return compare(op, s.item.LastUsedAt.UnixNano(), at(s.now).UnixNano())
+	}, nil
+}
+
+func compare(op string, a, b int64) bool {
+	switch op {
+	case opLess:
+		return a < b
+	case opLessEq:
+		return a <= b
+	case opMore:
+		return a > b
+	case opMoreEq:
+		return a >= b
+	default:
+		return a == b
+	}
+}
+
+// parseDate returns the instant value names, as a function of the current
+// time so that relative dates follow the clock.
+func parseDate(value string) (func(now time.Time) time.Time, error) {
+	startOfDay := func(now time.Time) time.Time {
+		year, month, day := now.Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	}
+	switch strings.ToLower(value) {
+	case "today":
+		return startOfDay, nil
+	case "week":
+		return func(now time.Time) time.Time {
+			// Weeks start on Monday.
+			days := (int(now.Weekday()) + 6) % 7
+			return startOfDay(now).AddDate(0, 0, -days)
+		}, nil
+	case "month":
+		return func(now time.Time) time.Time {
+			return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		}, nil
+	case "year":
+		return func(now time.Time) time.Time {
+			return time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+		}, nil
+	}
+
+	if unit, ok := spanUnits[value[len(value)-1]]; ok {
+		if count, err := strconv.Atoi(value[:len(value)-1]); err == nil && count >= 0 {
+			span := time.Duration(count) * unit
+			return func(now time.Time) time.Time { return now.Add(-span) }, nil
+		}
+	}
+	if day, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return func(time.Time) time.Time { return day }, nil
+	}
+	return nil, fmt.Errorf("invalid date %q", value)
+}
+
+var spanUnits = map[byte]time.Duration{
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
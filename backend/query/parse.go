@@ -0,0 +1,236 @@
+// Package query parses the expressions that define smart groups, such as
+//
+//	tag:work -is:hidden
+//	type:url OR type:doc
+//	used:week
+//	launches:0 (name:setup OR path:"C:\Temp")
+//
+// A term is a field:value pair or a bare word, which matches the item name.
+// Terms next to each other must all match, as if joined by AND; OR between
+// two terms needs only one of them and binds looser than AND. A leading - or
+// NOT negates a term or a parenthesized expression. Values containing
+// spaces or operators are written in double quotes. Numbers and dates can
+// be compared with >, >=, < and <= instead of the colon.
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"rungrid/backend/domain"
+)
+
+// Query is a parsed expression.
+type Query struct {
+	source string
+	match  matcher
+}
+
+type matcher func(*subject) bool
+
+// subject is the item a query is evaluated against, together with the time
+// relative dates such as "7d" are counted from.
+type subject struct {
+	item domain.Item
+	now  time.Time
+}
+
+// Parse compiles source. An expression that is empty or does not parse is
+// an error naming the offending position.
+func Parse(source string) (*Query, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("query is empty")
+	}
+	p := &parser{tokens: tokens}
+	match, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q at %d", token.text, token.pos+1)
+	}
+	return &Query{source: strings.TrimSpace(source), match: match}, nil
+}
+
+// Match reports whether item matches the query at the time now.
+func (q *Query) Match(item domain.Item, now time.Time) bool {
+	return q.match(&subject{item: item, now: now})
+}
+
+func (q *Query) String() string {
+	return q.source
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+	// literal is set for words that start with a quote; they are never
+	// keywords, negations or field terms.
+	literal bool
+}
+
+func tokenize(source string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(source)
+	for index := 0; index < len(runes); {
+		switch r := runes[index]; {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			index++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "(", pos: index})
+			index++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")", pos: index})
+			index++
+		default:
+			start := index
+			var text strings.Builder
+			for index < len(runes) {
+				r := runes[index]
+				if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '(' || r == ')' {
+					break
+				}
+				if r != '"' {
+					text.WriteRune(r)
+					index++
+					continue
+				}
+				end := index + 1
+				for end < len(runes) && runes[end] != '"' {
+					end++
+				}
+				if end == len(runes) {
+					return nil, fmt.Errorf("unterminated quote at %d", index+1)
+				}
+				text.WriteString(string(runes[index+1 : end]))
+				index = end + 1
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: text.String(), pos: start, literal: runes[start] == '"'})
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	next   int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.next >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.next], true
+}
+
+func (p *parser) keyword(word string) bool {
+	token, ok := p.peek()
+	return ok && token.kind == tokenWord && !token.literal && token.text == word
+}
+
+func (p *parser) parseOr() (matcher, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		p.next++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = either(left, right)
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (matcher, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token, ok := p.peek()
+		if !ok || token.kind == tokenClose || p.keyword("OR") {
+			return left, nil
+		}
+		if p.keyword("AND") {
+			p.next++
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = both(left, right)
+	}
+}
+
+func (p *parser) parseUnary() (matcher, error) {
+	token, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("query ends unexpectedly")
+	}
+	switch {
+	case token.kind == tokenOpen:
+		p.next++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.peek(); !ok || closing.kind != tokenClose {
+			return nil, fmt.Errorf("missing ) for ( at %d", token.pos+1)
+		}
+		p.next++
+		return inner, nil
+	case token.kind == tokenClose:
+		return nil, fmt.Errorf("unexpected ) at %d", token.pos+1)
+	case token.literal:
+		p.next++
+		return nameContains(token.text), nil
+	case token.text == "AND" || token.text == "OR":
+		return nil, fmt.Errorf("unexpected %s at %d", token.text, token.pos+1)
+	case token.text == "NOT" || token.text == "-":
+		p.next++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negate(inner), nil
+	case strings.HasPrefix(token.text, "-"):
+		p.next++
+		inner, err := parseTerm(token.text[1:], token.pos+1)
+		if err != nil {
+			return nil, err
+		}
+		return negate(inner), nil
+	default:
+		p.next++
+		return parseTerm(token.text, token.pos)
+	}
+}
+
+func either(left, right matcher) matcher {
+	return func(s *subject) bool { return left(s) || right(s) }
+}
+
+func both(left, right matcher) matcher {
+	return func(s *subject) bool { return left(s) && right(s) }
+}
+
+func negate(inner matcher) matcher {
+	return func(s *subject) bool { return !inner(s) }
+}
@@ -0,0 +1,41 @@
+package rules
+
+import (
+	"path/filepath"
+	"strings"
+
+	"rungrid/backend/desktopentry"
+	"rungrid/backend/shelllink"
+)
+
+// ShortcutArguments reads the arguments stored in a .lnk or .desktop
+// shortcut. Other paths, and shortcuts that cannot be read, have none.
+func ShortcutArguments(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".lnk":
+		link, err := shelllink.ParseFile(path)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(link.Arguments)
+	case ".desktop":
+		entry, err := desktopentry.ParseFile(path)
+		if err != nil {
+			return ""
+		}
+		tokens, err := desktopentry.SplitExec(entry.Exec)
+		if err != nil || len(tokens) < 2 {
+			return ""
+		}
+		args := []string{}
+		for _, token := range tokens[1:] {
+			// Field codes are placeholders for the launcher, not arguments.
+			if len(token) == 2 && token[0] == '%' {
+				continue
+			}
+			args = append(args, token)
+		}
+		return strings.Join(args, " ")
+	}
+	return ""
+}
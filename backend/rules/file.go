@@ -0,0 +1,98 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"rungrid/backend/domain"
+)
+
+// Version is written to exported rule files. Files declaring "1.0" only use
+// target_name matches, files declaring "1.1" have no nested groups and files
+// declaring "1.2" have no exact path matches; all remain valid.
+const Version = "1.3"
+
+var supportedVersions = map[string]bool{"": true, "1.0": true, "1.1": true, "1.2": true, "1.3": true}
+
+// File is the JSON document accepted by ImportGroupRules.
+type File struct {
+	Version string  `json:"version"`
+	Groups  []Group `json:"groups"`
+	Rules   []Rule  `json:"rules"`
+}
+
+// Group is a group declared by the file. Parent nests it in another group
+// of the file, or in an existing group by its ID; a group that exists
+// already stays where it is when Parent is empty.
+type Group struct {
+	ID       string `json:"id"`
+	Parent   string `json:"parent,omitempty"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Order    int    `json:"order"`
+	Color    string `json:"color"`
+	Icon     string `json:"icon"`
+}
+
+// Rule moves items matching Match, and not matching Exclude, into the group
+// GroupID. When several rules match an item the highest Priority wins and
+// ties go to the rule listed first.
+type Rule struct {
+	ID       string     `json:"id,omitempty"`
+	GroupID  string     `json:"group_id"`
+	Priority int        `json:"priority,omitempty"`
+	Match    Condition  `json:"match"`
+	Exclude  *Condition `json:"exclude,omitempty"`
+}
+
+// Condition matches an item when every field that is set matches; a field
+// matches when any of its values does. Any and All nest further conditions.
+// An empty condition matches nothing.
+type Condition struct {
+	// TargetName compares the shortcut target file name, ignoring case.
+	TargetName []string `json:"target_name,omitempty"`
+	// Name holds glob patterns (* and ?) for the display name, ignoring case.
+	Name []string `json:"name,omitempty"`
+	// NameRegex holds regular expressions for the display name.
+	NameRegex []string `json:"name_regex,omitempty"`
+	// Paths matches the whole item path, ignoring case and separator style.
+	Paths []string `json:"paths,omitempty"`
+	// PathPrefix matches the item path at a path boundary, ignoring case
+	// and separator style.
+	PathPrefix []string          `json:"path_prefix,omitempty"`
+	Type       []domain.ItemType `json:"type,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	// Extension matches the extension of the item path or the target name.
+	Extension []string `json:"extension,omitempty"`
+	// Arguments holds glob patterns for the command-line arguments stored
+	// in a .lnk or .desktop shortcut.
+	Arguments []string    `json:"arguments,omitempty"`
+	Any       []Condition `json:"any,omitempty"`
+	All       []Condition `json:"all,omitempty"`
+}
+
+// Parse decodes a rule file and checks its version.
+func Parse(data []byte) (File, error) {
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return File{}, err
+	}
+	version := strings.TrimSpace(file.Version)
+	if !supportedVersions[version] {
+		return File{}, fmt.Errorf("unsupported rule version: %s", version)
+	}
+	return file, nil
+}
+
+// Key normalizes group ids so rule files can refer to them case-insensitively.
+func Key(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// IsEmpty reports whether the condition sets no field at all.
+func (c Condition) IsEmpty() bool {
+	return len(c.TargetName) == 0 && len(c.Name) == 0 && len(c.NameRegex) == 0 &&
+		len(c.Paths) == 0 && len(c.PathPrefix) == 0 && len(c.Type) == 0 && len(c.Tags) == 0 &&
+		len(c.Extension) == 0 && len(c.Arguments) == 0 && len(c.Any) == 0 && len(c.All) == 0
+}
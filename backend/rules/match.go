@@ -0,0 +1,396 @@
+package rules
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"rungrid/backend/domain"
+)
+
+// Candidate is a rule that matched an item.
+type Candidate struct {
+	Rule     int
+	RuleID   string
+	GroupKey string
+	Priority int
+}
+
+// Label names the rule by its id, or by its position in the file.
+func (c Candidate) Label() string {
+	if c.RuleID != "" {
+		return c.RuleID
+	}
+	return fmt.Sprintf("#%d", c.Rule+1)
+}
+
+// Set is a compiled rule file.
+type Set struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	index    int
+	id       string
+	groupKey string
+	priority int
+	match    matcher
+	exclude  matcher
+}
+
+type matcher func(*subject) bool
+
+// subject resolves shortcut arguments lazily; most rule files never ask for
+// them and reading every shortcut would dominate the import.
+type subject struct {
+	item     domain.Item
+	args     string
+	argsRead bool
+}
+
+func (s *subject) arguments() string {
+	if !s.argsRead {
+		s.args = ShortcutArguments(s.item.Path)
+		s.argsRead = true
+	}
+	return s.args
+}
+
+// Compile validates every rule of file and prepares its matchers. Rules
+// without a group id are skipped, as they always have been.
+func Compile(file File) (*Set, error) {
+	set := &Set{}
+	for index, rule := range file.Rules {
+		key := Key(rule.GroupID)
+		if key == "" {
+			continue
+		}
+		match, err := compileCondition(rule.Match)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", ruleLabel(index, rule), err)
+		}
+		compiled := compiledRule{
+			index:    index,
+			id:       strings.TrimSpace(rule.ID),
+			groupKey: key,
+			priority: rule.Priority,
+			match:    match,
+		}
+		if rule.Exclude != nil {
+			if compiled.exclude, err = compileCondition(*rule.Exclude); err != nil {
+				return nil, fmt.Errorf("rule %s exclude: %w", ruleLabel(index, rule), err)
+			}
+		}
+		set.rules = append(set.rules, compiled)
+	}
+	return set, nil
+}
+
+// GroupKeys lists the normalized group ids referenced by the compiled rules.
+func (s *Set) GroupKeys() []string {
+	seen := map[string]struct{}{}
+	keys := []string{}
+	for _, rule := range s.rules {
+		if _, ok := seen[rule.groupKey]; ok {
+			continue
+		}
+		seen[rule.groupKey] = struct{}{}
+		keys = append(keys, rule.groupKey)
+	}
+	return keys
+}
+
+// Match returns the rules that apply to item, best first.
+func (s *Set) Match(item domain.Item) []Candidate {
+	target := &subject{item: item}
+	candidates := []Candidate{}
+	for _, rule := range s.rules {
+		if !rule.match(target) {
+			continue
+		}
+		if rule.exclude != nil && rule.exclude(target) {
+			continue
+		}
+		candidates = append(candidates, Candidate{
+			Rule:     rule.index,
+			RuleID:   rule.id,
+			GroupKey: rule.groupKey,
+			Priority: rule.priority,
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Priority > candidates[j].Priority
+	})
+	return candidates
+}
+
+func ruleLabel(index int, rule Rule) string {
+	if id := strings.TrimSpace(rule.ID); id != "" {
+		return id
+	}
+	return fmt.Sprintf("#%d", index+1)
+}
+
+func compileCondition(condition Condition) (matcher, error) {
+	if condition.IsEmpty() {
+		return func(*subject) bool { return false }, nil
+	}
+
+	checks := []matcher{}
+	add := func(check matcher, err error) error {
+		if err != nil {
+			return err
+		}
+		if check != nil {
+			checks = append(checks, check)
+		}
+		return nil
+	}
+
+	if err := add(compileTargetNames(condition.TargetName)); err != nil {
+		return nil, err
+	}
+	if err := add(compilePatterns("name", condition.Name, globPattern, func(s *subject) string { return s.item.Name })); err != nil {
+		return nil, err
+	}
+	if err := add(compilePatterns("name_regex", condition.NameRegex, regexp.Compile, func(s *subject) string { return s.item.Name })); err != nil {
+		return nil, err
+	}
+	if err := add(compilePaths(condition.Paths)); err != nil {
+		return nil, err
+	}
+	if err := add(compilePathPrefixes(condition.PathPrefix)); err != nil {
+		return nil, err
+	}
+	if err := add(compileTypes(condition.Type)); err != nil {
+		return nil, err
+	}
+	if err := add(compileTags(condition.Tags)); err != nil {
+		return nil, err
+	}
+	if err := add(compileExtensions(condition.Extension)); err != nil {
+		return nil, err
+	}
+	if err := add(compilePatterns("arguments", condition.Arguments, globPattern, (*subject).arguments)); err != nil {
+		return nil, err
+	}
+
+	if len(condition.Any) > 0 {
+		nested, err := compileConditions(condition.Any)
+		if err != nil {
+			return nil, fmt.Errorf("any: %w", err)
+		}
+		checks = append(checks, func(s *subject) bool {
+			for _, check := range nested {
+				if check(s) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	if len(condition.All) > 0 {
+		nested, err := compileConditions(condition.All)
+		if err != nil {
+			return nil, fmt.Errorf("all: %w", err)
+		}
+		checks = append(checks, func(s *subject) bool {
+			for _, check := range nested {
+				if !check(s) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+
+	// A field whose values were all blank rules out every item, which
+	// matches how blank target names behaved before.
+	if len(checks) == 0 {
+		return func(*subject) bool { return false }, nil
+	}
+	return func(s *subject) bool {
+		for _, check := range checks {
+			if !check(s) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func compileConditions(conditions []Condition) ([]matcher, error) {
+	matchers := make([]matcher, 0, len(conditions))
+	for _, condition := range conditions {
+		check, err := compileCondition(condition)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, check)
+	}
+	return matchers, nil
+}
+
+// fieldSet returns a matcher over a set of normalized values. A field
+// listing only blank values yields a matcher that never matches.
+func fieldSet(values []string, normalize func(string) string, lookup func(*subject, map[string]struct{}) bool) (matcher, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	set := map[string]struct{}{}
+	for _, value := range values {
+		if value = normalize(value); value != "" {
+			set[value] = struct{}{}
+		}
+	}
+	return func(s *subject) bool {
+		return len(set) > 0 && lookup(s, set)
+	}, nil
+}
+
+func compileTargetNames(values []string) (matcher, error) {
+	return fieldSet(values, normalizeName, func(s *subject, set map[string]struct{}) bool {
+		_, ok := set[normalizeName(s.item.TargetName)]
+		return ok
+	})
+}
+
+func compileTags(values []string) (matcher, error) {
+	return fieldSet(values, normalizeName, func(s *subject, set map[string]struct{}) bool {
+		for _, tag := range s.item.Tags {
+			if _, ok := set[normalizeName(tag)]; ok {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func compileExtensions(values []string) (matcher, error) {
+	return fieldSet(values, normalizeExtension, func(s *subject, set map[string]struct{}) bool {
+		for _, name := range []string{s.item.Path, s.item.TargetName} {
+			if _, ok := set[normalizeExtension(filepath.Ext(slashPath(name)))]; ok {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func compileTypes(values []domain.ItemType) (matcher, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	set := map[domain.ItemType]struct{}{}
+	for _, value := range values {
+		itemType := domain.ItemType(normalizeName(string(value)))
+		if !itemType.IsValid() {
+			return nil, fmt.Errorf("unknown item type: %s", value)
+		}
+		set[itemType] = struct{}{}
+	}
+	return func(s *subject) bool {
+		_, ok := set[s.item.Type]
+		return ok
+	}, nil
+}
+
+func compilePaths(values []string) (matcher, error) {
+	return fieldSet(values, normalizePath, func(s *subject, set map[string]struct{}) bool {
+		_, ok := set[normalizePath(s.item.Path)]
+		return ok
+	})
+}
+
+func compilePathPrefixes(values []string) (matcher, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	prefixes := []string{}
+	for _, value := range values {
+		if prefix := strings.TrimSuffix(normalizePath(value), "/"); prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return func(s *subject) bool {
+		path := normalizePath(s.item.Path)
+		for _, prefix := range prefixes {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func compilePatterns(field string, values []string, compile func(string) (*regexp.Regexp, error), value func(*subject) string) (matcher, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	patterns := []*regexp.Regexp{}
+	for _, raw := range values {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		pattern, err := compile(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %w", field, raw, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return func(s *subject) bool {
+		text := value(s)
+		for _, pattern := range patterns {
+			if pattern.MatchString(text) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// globPattern turns a glob into a case-insensitive anchored expression.
+// Only * and ? are special, so names with brackets need no escaping.
+func globPattern(glob string) (*regexp.Regexp, error) {
+	var builder strings.Builder
+	builder.WriteString("(?is)^")
+	for _, r := range strings.TrimSpace(glob) {
+		switch r {
+		case '*':
+			builder.WriteString(".*")
+		case '?':
+			builder.WriteString(".")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	builder.WriteString("$")
+	return regexp.Compile(builder.String())
+}
+
+func normalizeName(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+func normalizeExtension(value string) string {
+	value = normalizeName(value)
+	if value == "" || value == "." {
+		return ""
+	}
+	if !strings.HasPrefix(value, ".") {
+		value = "." + value
+	}
+	return value
+}
+
+func normalizePath(value string) string {
+	return strings.ToLower(slashPath(strings.TrimSpace(value)))
+}
+
+// slashPath normalizes separators so rule files written on Windows work
+// everywhere and vice versa.
+func slashPath(value string) string {
+	return strings.ReplaceAll(value, `\`, "/")
+}
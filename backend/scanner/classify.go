@@ -1,10 +1,8 @@
-//go:build windows
-
 package scanner
 
 import (
 	"os"
-	"path/filepath"
+	"path"
 	"strings"
 
 	"rungrid/backend/domain"
@@ -35,7 +33,10 @@ var webExtensions = map[string]struct{}{
 	".mhtml": {},
 }
 
-func classifyShortcutTarget(source, target, args string, fallback domain.ItemType) domain.ItemType {
+// classifyShortcutTarget picks the item type of a shortcut from its
+// target. directory is the folder flag recorded in the link, for targets
+// that cannot be checked on this machine.
+func classifyShortcutTarget(source, target, args string, directory bool, fallback domain.ItemType) domain.ItemType {
 	source = normalizePath(source)
 	target = strings.TrimSpace(target)
 	args = strings.TrimSpace(args)
@@ -44,7 +45,7 @@ func classifyShortcutTarget(source, target, args string, fallback domain.ItemTyp
 		return domain.ItemTypeURL
 	}
 
-	ext := strings.ToLower(filepath.Ext(target))
+	ext := strings.ToLower(path.Ext(slashPath(target)))
 	if _, ok := webExtensions[ext]; ok {
 		return domain.ItemTypeURL
 	}
@@ -57,6 +58,9 @@ func classifyShortcutTarget(source, target, args string, fallback domain.ItemTyp
 	}
 
 	if target != "" {
+		if directory {
+			return domain.ItemTypeFolder
+		}
 		if info, err := os.Stat(target); err == nil && info.IsDir() {
 			return domain.ItemTypeFolder
 		}
@@ -89,8 +93,8 @@ func isSystemTarget(target, args string) bool {
 	return isSystemBinaryPath(target)
 }
 
-func isSystemBinaryPath(path string) bool {
-	clean := normalizePath(path)
+func isSystemBinaryPath(value string) bool {
+	clean := normalizePath(value)
 	if clean == "" {
 		return false
 	}
@@ -103,22 +107,18 @@ func isSystemBinaryPath(path string) bool {
 	return false
 }
 
-func isSystemShortcutSource(path string) bool {
-	if path == "" {
+func isSystemShortcutSource(source string) bool {
+	if source == "" {
 		return false
 	}
 
 	for _, root := range startMenuRoots() {
-		if !hasPathPrefix(path, root) {
+		if !hasPathPrefix(source, root) || source == root {
 			continue
 		}
-		rel, err := filepath.Rel(root, path)
-		if err != nil {
-			continue
-		}
-		lowerRel := normalizePath(rel)
+		rel := strings.TrimPrefix(source, root+"/")
 		for _, folder := range systemShortcutFolders() {
-			if strings.HasPrefix(lowerRel, folder+string(os.PathSeparator)) || lowerRel == folder {
+			if hasPathPrefix(rel, folder) {
 				return true
 			}
 		}
@@ -127,22 +127,26 @@ func isSystemShortcutSource(path string) bool {
 	return false
 }
 
+// systemRoots falls back to the default Windows directory where the
+// variables are not set, which is the case for links read off Windows.
 func systemRoots() []string {
-	roots := []string{}
-	if windir := os.Getenv("WINDIR"); windir != "" {
-		roots = append(roots,
-			filepath.Join(windir, "System32"),
-			filepath.Join(windir, "SysWOW64"),
-			filepath.Join(windir, "SystemApps"),
-			filepath.Join(windir, "Explorer.exe"),
-		)
+	windirs := []string{}
+	for _, name := range []string{"WINDIR", "SystemRoot"} {
+		if dir := os.Getenv(name); dir != "" {
+			windirs = append(windirs, dir)
+		}
 	}
-	if systemRoot := os.Getenv("SystemRoot"); systemRoot != "" {
+	if len(windirs) == 0 {
+		windirs = append(windirs, `C:\Windows`)
+	}
+
+	roots := []string{}
+	for _, windir := range windirs {
 		roots = append(roots,
-			filepath.Join(systemRoot, "System32"),
-			filepath.Join(systemRoot, "SysWOW64"),
-			filepath.Join(systemRoot, "SystemApps"),
-			filepath.Join(systemRoot, "Explorer.exe"),
+			path.Join(slashPath(windir), "System32"),
+			path.Join(slashPath(windir), "SysWOW64"),
+			path.Join(slashPath(windir), "SystemApps"),
+			path.Join(slashPath(windir), "Explorer.exe"),
 		)
 	}
 
@@ -165,10 +169,10 @@ func systemRoots() []string {
 func startMenuRoots() []string {
 	roots := []string{}
 	if appData := os.Getenv("APPDATA"); appData != "" {
-		roots = append(roots, filepath.Join(appData, "Microsoft", "Windows", "Start Menu", "Programs"))
+		roots = append(roots, path.Join(slashPath(appData), "Microsoft", "Windows", "Start Menu", "Programs"))
 	}
 	if programData := os.Getenv("PROGRAMDATA"); programData != "" {
-		roots = append(roots, filepath.Join(programData, "Microsoft", "Windows", "Start Menu", "Programs"))
+		roots = append(roots, path.Join(slashPath(programData), "Microsoft", "Windows", "Start Menu", "Programs"))
 	}
 
 	normalized := make([]string, 0, len(roots))
@@ -195,20 +199,26 @@ func systemShortcutFolders() []string {
 	}
 }
 
+// normalizePath gives Windows paths the same lower-case, slash-separated
+// form on every system, so shortcuts copied off Windows classify the same
+// way as those scanned there.
 func normalizePath(value string) string {
 	value = strings.TrimSpace(value)
 	if value == "" {
 		return ""
 	}
-	value = filepath.Clean(value)
-	value = strings.ReplaceAll(value, "/", string(os.PathSeparator))
-	return strings.ToLower(value)
+	return strings.ToLower(path.Clean(slashPath(value)))
+}
+
+// slashPath turns Windows separators into slashes, which path handles on
+// every system.
+func slashPath(value string) string {
+	return strings.ReplaceAll(value, `\`, "/")
 }
 
-func hasPathPrefix(path, prefix string) bool {
-	if path == prefix {
+func hasPathPrefix(value, prefix string) bool {
+	if value == prefix {
 		return true
 	}
-	prefixWithSep := prefix + string(os.PathSeparator)
-	return strings.HasPrefix(path, prefixWithSep)
+	return strings.HasPrefix(value, prefix+"/")
 }
@@ -1,9 +1,7 @@
-//go:build windows
-
 package scanner
 
 import (
-	"path/filepath"
+	"path"
 	"strings"
 )
 
@@ -21,7 +19,7 @@ func isUninstallerEntry(name, sourcePath, targetPath, args string) bool {
 		return true
 	}
 
-	targetBase := strings.ToLower(filepath.Base(strings.Trim(targetPath, "\"'")))
+	targetBase := strings.ToLower(path.Base(slashPath(strings.Trim(targetPath, "\"'"))))
 	if targetBase == "uninstall.exe" || targetBase == "uninstaller.exe" {
 		return true
 	}
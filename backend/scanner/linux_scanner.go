@@ -0,0 +1,318 @@
+//go:build linux
+
+package scanner
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"rungrid/backend/desktopentry"
+	"rungrid/backend/domain"
+	"rungrid/backend/shelllink"
+)
+
+type LinuxScanner struct {
+	Roots []string
+	// Concurrency bounds the parallel root walkers and entry parsers; zero
+	// picks DefaultConcurrency.
+	Concurrency int
+	progress    ProgressFunc
+	indexed     []domain.ScanIndexEntry
+}
+
+func NewDefaultScanner() Scanner {
+	return &LinuxScanner{Roots: NormalizeRoots(DefaultRoots())}
+}
+
+// DefaultRoots lists application directories in XDG precedence order, so
+// that entries found earlier shadow entries with the same desktop file ID.
+func DefaultRoots() []string {
+	roots := []string{}
+	home, _ := os.UserHomeDir()
+
+	dataHome := strings.TrimSpace(os.Getenv("XDG_DATA_HOME"))
+	if dataHome == "" && home != "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	if dataHome != "" {
+		roots = append(roots,
+			filepath.Join(dataHome, "applications"),
+			filepath.Join(dataHome, "flatpak", "exports", "share", "applications"),
+		)
+	}
+
+	dataDirs := strings.TrimSpace(os.Getenv("XDG_DATA_DIRS"))
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range strings.Split(dataDirs, ":") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			roots = append(roots, filepath.Join(dir, "applications"))
+		}
+	}
+
+	roots = append(roots,
+		"/var/lib/flatpak/exports/share/applications",
+		"/var/lib/snapd/desktop/applications",
+	)
+
+	if home != "" {
+		roots = append(roots, filepath.Join(home, "Desktop"))
+	}
+
+	return roots
+}
+
+func (s *LinuxScanner) SetRoots(roots []string) {
+	s.Roots = NormalizeRoots(roots)
+}
+
+func (s *LinuxScanner) SetProgressReporter(fn ProgressFunc) {
+	s.progress = fn
+}
+
+func (s *LinuxScanner) SetConcurrency(limit int) {
+	s.Concurrency = limit
+}
+
+// SetIndex is a no-op: desktop entries and shortcuts are cheap to parse,
+// and whether an entry is shown also depends on the environment (TryExec,
+// OnlyShowIn), so every file is evaluated again.
+func (s *LinuxScanner) SetIndex(map[string]domain.ScanIndexEntry) {}
+
+func (s *LinuxScanner) Index() []domain.ScanIndexEntry {
+	return s.indexed
+}
+
+// linuxEntry is what a parser worker learned about one desktop file or
+// Windows shortcut.
+type linuxEntry struct {
+	state     domain.ScanIndexEntry
+	item      domain.ItemInput
+	keep      bool
+	dedupeKey string
+}
+
+// Scan walks the roots and parses desktop files and .lnk shortcuts copied
+// from Windows in parallel. Shadowing by desktop file ID, and by target for
+// shortcuts, is applied afterwards in root precedence order, so the result
+// does not depend on scheduling.
+func (s *LinuxScanner) Scan(ctx context.Context) ([]domain.ItemInput, error) {
+	s.indexed = []domain.ScanIndexEntry{}
+
+	roots := NormalizeRoots(s.Roots)
+	if len(roots) == 0 {
+		roots = NormalizeRoots(DefaultRoots())
+	}
+	tracker := newProgressTracker(s.progress, len(roots))
+
+	files, err := walkRoots(ctx, roots, s.Concurrency, func(name string) bool {
+		ext := filepath.Ext(name)
+		return strings.EqualFold(ext, ".desktop") || strings.EqualFold(ext, ".lnk")
+	}, tracker)
+	if err != nil {
+		return nil, err
+	}
+
+	locale := desktopentry.CurrentLocale()
+	desktops := desktopentry.CurrentDesktops()
+	results, err := processFiles(ctx, files, s.Concurrency, func() (func(walkedFile) linuxEntry, func()) {
+		return func(file walkedFile) linuxEntry {
+			if strings.EqualFold(filepath.Ext(file.path), ".lnk") {
+				return parseLinkFile(file)
+			}
+			return parseDesktopFile(file, locale, desktops)
+		}, func() {}
+	}, tracker)
+	if err != nil {
+		return nil, err
+	}
+
+	seenIDs := map[string]struct{}{}
+	seenTargets := map[string]struct{}{}
+	items := []domain.ItemInput{}
+	for index, result := range results {
+		file := files[index]
+		id := desktopFileID(file.root, file.path)
+		if _, ok := seenIDs[id]; ok {
+			continue
+		}
+		// Hidden and filtered entries still claim their ID so that they
+		// shadow lower-priority copies, as the XDG menu spec requires.
+		seenIDs[id] = struct{}{}
+		s.indexed = append(s.indexed, result.state)
+		if !result.keep {
+			continue
+		}
+		if result.dedupeKey != "" {
+			if _, ok := seenTargets[result.dedupeKey]; ok {
+				continue
+			}
+			seenTargets[result.dedupeKey] = struct{}{}
+		}
+		items = append(items, result.item)
+	}
+
+	tracker.emit(ScanProgress{Scanned: len(files), Percent: 100}, true)
+	return items, nil
+}
+
+func parseDesktopFile(file walkedFile, locale string, desktops []string) linuxEntry {
+	result := linuxEntry{state: domain.ScanIndexEntry{Path: file.path}}
+	if info, err := file.entry.Info(); err == nil {
+		result.state.Size = info.Size()
+		result.state.ModTime = info.ModTime()
+	}
+
+	parsed, err := desktopentry.ParseFile(file.path)
+	if err != nil {
+		return result
+	}
+	result.item, result.keep = desktopEntryItem(file.path, parsed, locale, desktops)
+	return result
+}
+
+// parseLinkFile reads a shortcut without COM. The target usually names a
+// Windows path that does not exist here, so it is classified by its text
+// and the folder flag stored in the link. Advertised (MSI) shortcuts keep
+// the application type.
+func parseLinkFile(file walkedFile) linuxEntry {
+	result := linuxEntry{state: domain.ScanIndexEntry{Path: file.path}}
+	if info, err := file.entry.Info(); err == nil {
+		result.state.Size = info.Size()
+		result.state.ModTime = info.ModTime()
+	}
+
+	link, err := shelllink.ParseFile(file.path)
+	if err != nil {
+		return result
+	}
+
+	name := strings.TrimSpace(strings.TrimSuffix(file.entry.Name(), filepath.Ext(file.entry.Name())))
+	if name == "" {
+		name = file.entry.Name()
+	}
+	target, args, _ := linkTarget(file.path, link)
+	result.state.Target = target
+	result.state.Arguments = args
+	if isUninstallerEntry(name, file.path, target, args) {
+		return result
+	}
+
+	result.item = domain.ItemInput{
+		Name:       name,
+		Path:       file.path,
+		TargetName: deriveTargetName(".lnk", file.path, target),
+		Type:       classifyShortcutTarget(file.path, target, args, link.Directory(), domain.ItemTypeApp),
+		IconPath:   "",
+		GroupID:    "",
+		Tags:       nil,
+		Favorite:   false,
+		Hidden:     false,
+	}
+	result.dedupeKey = shortcutDedupeKey(target, args)
+	result.keep = true
+	return result
+}
+
+func desktopEntryItem(path string, entry desktopentry.Entry, locale string, desktops []string) (domain.ItemInput, bool) {
+	if entry.Hidden || entry.NoDisplay || !entry.ShowIn(desktops) {
+		return domain.ItemInput{}, false
+	}
+
+	itemType := domain.ItemTypeApp
+	targetName := ""
+	switch entry.Type {
+	case "Application", "":
+		if strings.TrimSpace(entry.Exec) == "" {
+			return domain.ItemInput{}, false
+		}
+		if entry.TryExec != "" && !executableExists(entry.TryExec) {
+			return domain.ItemInput{}, false
+		}
+		if hasCategory(entry.Categories, "Settings") {
+			itemType = domain.ItemTypeSystem
+		}
+		targetName = targetNameFromExec(entry)
+	case "Link":
+		if strings.TrimSpace(entry.URL) == "" {
+			return domain.ItemInput{}, false
+		}
+		itemType = classifyLinkURL(entry.URL)
+	default:
+		return domain.ItemInput{}, false
+	}
+
+	name := strings.TrimSpace(entry.LocaleString("Name", locale))
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	return domain.ItemInput{
+		Name:       name,
+		Path:       path,
+		TargetName: targetName,
+		Type:       itemType,
+		IconPath:   "",
+		GroupID:    "",
+		Tags:       nil,
+		Favorite:   false,
+		Hidden:     false,
+	}, true
+}
+
+func desktopFileID(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		rel = filepath.Base(path)
+	}
+	return strings.ReplaceAll(filepath.ToSlash(rel), "/", "-")
+}
+
+func targetNameFromExec(entry desktopentry.Entry) string {
+	program := strings.TrimSpace(entry.ExecProgram())
+	if program == "" {
+		return ""
+	}
+	base := filepath.Base(program)
+	if base == "." || base == string(os.PathSeparator) {
+		return ""
+	}
+	return strings.ToLower(base)
+}
+
+func classifyLinkURL(value string) domain.ItemType {
+	parsed, err := url.Parse(strings.TrimSpace(value))
+	if err != nil || !strings.EqualFold(parsed.Scheme, "file") {
+		return domain.ItemTypeURL
+	}
+	if info, err := os.Stat(parsed.Path); err == nil && info.IsDir() {
+		return domain.ItemTypeFolder
+	}
+	if kind := ClassifyPath(parsed.Path); kind != domain.ItemTypeApp {
+		return kind
+	}
+	return domain.ItemTypeDoc
+}
+
+func executableExists(program string) bool {
+	if filepath.IsAbs(program) {
+		info, err := os.Stat(program)
+		return err == nil && !info.IsDir() && info.Mode().Perm()&0o111 != 0
+	}
+	_, err := exec.LookPath(program)
+	return err == nil
+}
+
+func hasCategory(categories []string, name string) bool {
+	for _, category := range categories {
+		if strings.EqualFold(category, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package scanner
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// maxConcurrency caps the configured limit; more parallel walkers only add
+// disk contention.
+const maxConcurrency = 16
+
+// ConcurrencySetter is implemented by scanners that walk roots and process
+// files in parallel.
+type ConcurrencySetter interface {
+	// SetConcurrency bounds the parallel walkers and workers; zero or less
+	// picks DefaultConcurrency.
+	SetConcurrency(limit int)
+}
+
+// DefaultConcurrency is used when no limit is configured.
+func DefaultConcurrency() int {
+	return min(4, max(1, runtime.NumCPU()))
+}
+
+func concurrencyLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultConcurrency()
+	}
+	return min(limit, maxConcurrency)
+}
+
+// walkedFile is a file found below one of the scan roots.
+type walkedFile struct {
+	root      string
+	rootIndex int
+	path      string
+	entry     fs.DirEntry
+}
+
+// walkRoots lists the files below roots for which match returns true,
+// walking up to limit roots at a time. The result is ordered by root and
+// then lexically, as filepath.WalkDir visits them, so it does not depend on
+// scheduling. Missing roots are skipped. While walking, progress carries
+// the number of files seen and an unknown percentage.
+func walkRoots(ctx context.Context, roots []string, limit int, match func(name string) bool, tracker *progressTracker) ([]walkedFile, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	perRoot := make([][]walkedFile, len(roots))
+	var scanned atomic.Int64
+	var firstErr error
+	var errOnce sync.Once
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrencyLimit(limit))
+
+	for index, root := range roots {
+		if root == "" {
+			continue
+		}
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-slots }()
+
+			files := []walkedFile{}
+			err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return nil
+				}
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if entry.IsDir() {
+					return nil
+				}
+
+				tracker.emit(ScanProgress{
+					Root:      root,
+					Path:      path,
+					RootIndex: index + 1,
+					Scanned:   int(scanned.Add(1)),
+					Percent:   -1,
+				}, false)
+				if match(entry.Name()) {
+					files = append(files, walkedFile{root: root, rootIndex: index, path: path, entry: entry})
+				}
+				return nil
+			})
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			perRoot[index] = files
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	files := []walkedFile{}
+	for _, rootFiles := range perRoot {
+		files = append(files, rootFiles...)
+	}
+	return files, nil
+}
+
+// processFiles runs process on every file using up to limit workers and
+// returns the results in the order of files. newWorker is called on each
+// worker goroutine before its first file, so it can set up per-thread
+// state such as a COM apartment; the returned close function runs on the
+// same goroutine when the worker is done. Progress reports the share of
+// files processed.
+func processFiles[T any](ctx context.Context, files []walkedFile, limit int, newWorker func() (func(walkedFile) T, func()), tracker *progressTracker) ([]T, error) {
+	results := make([]T, len(files))
+	if len(files) == 0 {
+		return results, ctx.Err()
+	}
+
+	next := make(chan int)
+	var done atomic.Int64
+	var wg sync.WaitGroup
+	workers := min(concurrencyLimit(limit), len(files))
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			process, closeWorker := newWorker()
+			defer closeWorker()
+			for index := range next {
+				file := files[index]
+				results[index] = process(file)
+				count := int(done.Add(1))
+				tracker.emit(ScanProgress{
+					Root:      file.root,
+					Path:      file.path,
+					RootIndex: file.rootIndex + 1,
+					Scanned:   count,
+					Percent:   count * 100 / len(files),
+				}, count == len(files))
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for index := range files {
+		select {
+		case next <- index:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,46 @@
+package scanner
+
+import (
+	"sync"
+	"time"
+)
+
+const progressInterval = 200 * time.Millisecond
+
+// progressTracker throttles progress reports coming from several
+// goroutines and keeps the percentage from going backwards when workers
+// report out of order.
+type progressTracker struct {
+	fn        ProgressFunc
+	rootTotal int
+
+	mu          sync.Mutex
+	lastEmit    time.Time
+	lastPercent int
+}
+
+func newProgressTracker(fn ProgressFunc, rootTotal int) *progressTracker {
+	return &progressTracker{fn: fn, rootTotal: rootTotal, lastPercent: -1}
+}
+
+// emit reports progress at most every progressInterval unless force is set.
+// A negative Percent means the share is not known yet.
+func (t *progressTracker) emit(progress ScanProgress, force bool) {
+	if t.fn == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !force && time.Since(t.lastEmit) < progressInterval {
+		return
+	}
+	if progress.Percent >= 0 {
+		if progress.Percent < t.lastPercent {
+			return
+		}
+		t.lastPercent = progress.Percent
+	}
+	t.lastEmit = time.Now()
+	progress.RootTotal = t.rootTotal
+	t.fn(progress)
+}
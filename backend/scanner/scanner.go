@@ -23,6 +23,15 @@ type ProgressReporter interface {
 	SetProgressReporter(fn ProgressFunc)
 }
 
+// Indexer is implemented by scanners that record the state of every file
+// they process, so that a later scan can skip the files that did not change.
+type Indexer interface {
+	// SetIndex passes the entries of the previous scan, keyed by path.
+	SetIndex(index map[string]domain.ScanIndexEntry)
+	// Index returns an entry for every file the last Scan processed.
+	Index() []domain.ScanIndexEntry
+}
+
 type ScanProgress struct {
 	Root      string `json:"root"`
 	Path      string `json:"path"`
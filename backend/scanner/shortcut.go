@@ -0,0 +1,168 @@
+package scanner
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"rungrid/backend/shelllink"
+)
+
+// linkTarget reads the target and arguments stored in a parsed .lnk file.
+// ok is false for advertised (MSI) shortcuts, which name no path, and for
+// links whose target is not a filesystem location.
+func linkTarget(source string, link shelllink.Link) (string, string, bool) {
+	if link.Advertised() {
+		return "", "", false
+	}
+	target := expandPercentEnv(strings.TrimSpace(link.Target()))
+	if link.EnvironmentTarget != "" && strings.Contains(target, "%") {
+		// A variable this system does not define, as on a link copied off
+		// Windows; the path the link was created with is the better guess.
+		stored := link
+		stored.EnvironmentTarget = ""
+		if fallback := strings.TrimSpace(stored.Target()); fallback != "" {
+			target = fallback
+		}
+	}
+	if target == "" {
+		return "", "", false
+	}
+	return finishShortcutTarget(source, target), strings.Trim(strings.TrimSpace(link.Arguments), "\"'"), true
+}
+
+func finishShortcutTarget(source, target string) string {
+	target = strings.Trim(target, "\"'")
+	if target == "" {
+		return ""
+	}
+	target = expandPercentEnv(target)
+	target = strings.TrimSpace(target)
+	if target != "" && !isAbsShortcutPath(target) && !strings.HasPrefix(target, "%") {
+		target = filepath.Join(filepath.Dir(source), target)
+	}
+	return target
+}
+
+// isAbsShortcutPath also accepts Windows drive and UNC paths where the
+// system does not, since links copied off Windows keep them.
+func isAbsShortcutPath(value string) bool {
+	if filepath.IsAbs(value) || strings.HasPrefix(value, `\\`) {
+		return true
+	}
+	if len(value) < 3 || value[1] != ':' || (value[2] != '\\' && value[2] != '/') {
+		return false
+	}
+	drive := value[0] | 0x20
+	return drive >= 'a' && drive <= 'z'
+}
+
+func shortcutDedupeKey(target, args string) string {
+	target = normalizePath(target)
+	if target == "" {
+		return ""
+	}
+	args = strings.ToLower(strings.TrimSpace(args))
+	return target + "\x00" + args
+}
+
+func expandPercentEnv(value string) string {
+	if !strings.Contains(value, "%") {
+		return value
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(value))
+	for i := 0; i < len(value); {
+		if value[i] != '%' {
+			builder.WriteByte(value[i])
+			i++
+			continue
+		}
+
+		end := strings.IndexByte(value[i+1:], '%')
+		if end == -1 {
+			builder.WriteString(value[i:])
+			break
+		}
+
+		key := value[i+1 : i+1+end]
+		if key == "" {
+			builder.WriteByte('%')
+			i += end + 2
+			continue
+		}
+
+		if val, ok := os.LookupEnv(key); ok {
+			builder.WriteString(val)
+		} else {
+			builder.WriteByte('%')
+			builder.WriteString(key)
+			builder.WriteByte('%')
+		}
+		i += end + 2
+	}
+
+	return builder.String()
+}
+
+func deriveTargetName(ext, sourcePath, targetPath string) string {
+	switch strings.ToLower(ext) {
+	case ".lnk":
+		if name := targetNameFromShortcutTarget(targetPath); name != "" {
+			return name
+		}
+		return targetNameFromShortcutSource(sourcePath)
+	case ".exe":
+		return targetNameFromPath(sourcePath)
+	default:
+		return ""
+	}
+}
+
+func targetNameFromShortcutTarget(path string) string {
+	if isIconResourcePath(path) {
+		return ""
+	}
+	return targetNameFromPath(path)
+}
+
+func targetNameFromShortcutSource(path string) string {
+	base := targetNameFromPath(path)
+	if base == "" {
+		return ""
+	}
+	switch strings.ToLower(filepath.Ext(base)) {
+	case ".lnk", ".url":
+		return strings.TrimSuffix(base, filepath.Ext(base))
+	default:
+		return base
+	}
+}
+
+func targetNameFromPath(value string) string {
+	clean := strings.TrimSpace(value)
+	if clean == "" {
+		return ""
+	}
+	clean = strings.Trim(clean, "\"'")
+	base := strings.TrimSpace(path.Base(slashPath(clean)))
+	if base == "" || base == "." || base == "/" {
+		return ""
+	}
+	return strings.ToLower(base)
+}
+
+func isIconResourcePath(value string) bool {
+	clean := strings.TrimSpace(value)
+	if clean == "" {
+		return false
+	}
+	switch strings.ToLower(path.Ext(slashPath(clean))) {
+	case ".ico", ".icl", ".dll", ".mun":
+		return true
+	default:
+		return false
+	}
+}
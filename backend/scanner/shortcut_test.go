@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/shelllink"
+)
+
+func parseLinkFixture(t *testing.T, name string) (string, shelllink.Link) {
+	t.Helper()
+	path := filepath.Join("..", "shelllink", "testdata", name)
+	link, err := shelllink.ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile(%s): %v", name, err)
+	}
+	return path, link
+}
+
+func TestClassifyLinkFixtures(t *testing.T) {
+	t.Setenv("WINDIR", `C:\Windows`)
+	t.Setenv("SystemRoot", `C:\Windows`)
+	t.Setenv("ProgramFiles", `D:\Apps`)
+
+	tests := []struct {
+		fixture    string
+		target     string
+		args       string
+		resolved   bool
+		itemType   domain.ItemType
+		targetName string
+	}{
+		{"plain.lnk", `C:\Program Files\Notepad++\notepad++.exe`, "-multiInst -nosession", true, domain.ItemTypeApp, "notepad++.exe"},
+		{"knownfolder.lnk", `C:\Users\Public\Documents`, "", true, domain.ItemTypeFolder, "documents"},
+		{"env.lnk", `D:\Apps\Tool\tool.exe`, "", true, domain.ItemTypeApp, "tool.exe"},
+		{"advertised.lnk", "", "", false, domain.ItemTypeApp, "advertised"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			source, link := parseLinkFixture(t, tt.fixture)
+			target, args, ok := linkTarget(source, link)
+			if target != tt.target || args != tt.args || ok != tt.resolved {
+				t.Fatalf("linkTarget = %q, %q, %v; want %q, %q, %v", target, args, ok, tt.target, tt.args, tt.resolved)
+			}
+			if got := classifyShortcutTarget(source, target, args, link.Directory(), domain.ItemTypeApp); got != tt.itemType {
+				t.Errorf("classifyShortcutTarget = %q, want %q", got, tt.itemType)
+			}
+			if got := deriveTargetName(".lnk", source, target); got != tt.targetName {
+				t.Errorf("deriveTargetName = %q, want %q", got, tt.targetName)
+			}
+		})
+	}
+}
+
+func TestLinkTargetFallsBackToStoredPath(t *testing.T) {
+	if _, ok := os.LookupEnv("ProgramFiles"); ok {
+		t.Skip("ProgramFiles is defined on this system")
+	}
+	source, link := parseLinkFixture(t, "env.lnk")
+	if target, _, _ := linkTarget(source, link); target != `C:\Program Files\Tool\tool.exe` {
+		t.Errorf("linkTarget = %q, want the LinkInfo path", target)
+	}
+}
+
+func TestShortcutFilters(t *testing.T) {
+	t.Setenv("WINDIR", `C:\Windows`)
+	t.Setenv("SystemRoot", `C:\Windows`)
+	t.Setenv("PROGRAMDATA", `C:\ProgramData`)
+
+	if !isUninstallerEntry("App", `C:\Start\App.lnk`, `C:\Program Files\App\unins000.exe`, "") {
+		t.Error("unins000.exe is not an uninstaller")
+	}
+	if isUninstallerEntry("App", `C:\Start\App.lnk`, `C:\Program Files\App\app.exe`, "") {
+		t.Error("app.exe is an uninstaller")
+	}
+
+	tests := []struct {
+		source string
+		target string
+		args   string
+		want   domain.ItemType
+	}{
+		{`C:\Start\cmd.lnk`, `C:\Windows\System32\cmd.exe`, "", domain.ItemTypeSystem},
+		{`C:\Start\Settings.lnk`, `C:\Windows\explorer.exe`, "ms-settings:display", domain.ItemTypeSystem},
+		{`C:\ProgramData\Microsoft\Windows\Start Menu\Programs\Administrative Tools\Tool.lnk`, `C:\Tools\tool.exe`, "", domain.ItemTypeSystem},
+		{`C:\Start\Site.lnk`, `C:\Program Files\Browser\browser.exe`, "https://example.com", domain.ItemTypeURL},
+		{`C:\Start\Notes.lnk`, `C:\Users\me\notes.md`, "", domain.ItemTypeDoc},
+	}
+	for _, tt := range tests {
+		if got := classifyShortcutTarget(tt.source, tt.target, tt.args, false, domain.ItemTypeApp); got != tt.want {
+			t.Errorf("classifyShortcutTarget(%q, %q) = %q, want %q", tt.source, tt.target, got, tt.want)
+		}
+	}
+}
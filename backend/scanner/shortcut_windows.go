@@ -3,12 +3,12 @@
 package scanner
 
 import (
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/go-ole/go-ole"
 	"github.com/go-ole/go-ole/oleutil"
+
+	"rungrid/backend/shelllink"
 )
 
 type shortcutResolver struct {
@@ -58,6 +58,14 @@ func (r *shortcutResolver) Close() {
 }
 
 func (r *shortcutResolver) Resolve(path string) (string, string, error) {
+	// Parse the link file directly first; COM is only needed for advertised
+	// (MSI) shortcuts and links whose target is not stored as a path.
+	if link, err := shelllink.ParseFile(path); err == nil {
+		if target, args, ok := linkTarget(path, link); ok {
+			return target, args, nil
+		}
+	}
+
 	if r == nil || r.shell == nil {
 		return "", "", nil
 	}
@@ -88,72 +96,6 @@ func (r *shortcutResolver) Resolve(path string) (string, string, error) {
 	targetVar.Clear()
 	argsVar.Clear()
 
-	target = strings.Trim(target, "\"'")
 	args = strings.Trim(args, "\"'")
-	if target != "" {
-		target = expandPercentEnv(target)
-		target = strings.TrimSpace(target)
-		if target != "" && !filepath.IsAbs(target) {
-			target = filepath.Join(filepath.Dir(path), target)
-		}
-	}
-
-	return target, args, nil
-}
-
-func shortcutDedupeKey(target, args string) string {
-	target = normalizeShortcutPath(target)
-	if target == "" {
-		return ""
-	}
-	args = strings.ToLower(strings.TrimSpace(args))
-	return target + "\x00" + args
-}
-
-func normalizeShortcutPath(path string) string {
-	path = strings.TrimSpace(path)
-	if path == "" {
-		return ""
-	}
-	return strings.ToLower(filepath.Clean(path))
-}
-
-func expandPercentEnv(value string) string {
-	if !strings.Contains(value, "%") {
-		return value
-	}
-
-	var builder strings.Builder
-	builder.Grow(len(value))
-	for i := 0; i < len(value); {
-		if value[i] != '%' {
-			builder.WriteByte(value[i])
-			i++
-			continue
-		}
-
-		end := strings.IndexByte(value[i+1:], '%')
-		if end == -1 {
-			builder.WriteString(value[i:])
-			break
-		}
-
-		key := value[i+1 : i+1+end]
-		if key == "" {
-			builder.WriteByte('%')
-			i += end + 2
-			continue
-		}
-
-		if val, ok := os.LookupEnv(key); ok {
-			builder.WriteString(val)
-		} else {
-			builder.WriteByte('%')
-			builder.WriteString(key)
-			builder.WriteByte('%')
-		}
-		i += end + 2
-	}
-
-	return builder.String()
+	return finishShortcutTarget(path, target), args, nil
 }
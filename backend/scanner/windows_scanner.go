@@ -4,7 +4,6 @@ package scanner
 
 import (
 	"context"
-	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -15,8 +14,13 @@ import (
 )
 
 type WindowsScanner struct {
-	Roots    []string
-	progress ProgressFunc
+	Roots []string
+	// Concurrency bounds the parallel root walkers and shortcut resolvers;
+	// zero picks DefaultConcurrency.
+	Concurrency int
+	progress    ProgressFunc
+	previous    map[string]domain.ScanIndexEntry
+	indexed     []domain.ScanIndexEntry
 }
 
 func NewDefaultScanner() Scanner {
@@ -49,9 +53,52 @@ func (s *WindowsScanner) SetProgressReporter(fn ProgressFunc) {
 	s.progress = fn
 }
 
+func (s *WindowsScanner) SetConcurrency(limit int) {
+	s.Concurrency = limit
+}
+
+func (s *WindowsScanner) SetIndex(index map[string]domain.ScanIndexEntry) {
+	s.previous = index
+}
+
+func (s *WindowsScanner) Index() []domain.ScanIndexEntry {
+	return s.indexed
+}
+
+// windowsFile is what a resolver worker learned about one file.
+type windowsFile struct {
+	state     domain.ScanIndexEntry
+	item      domain.ItemInput
+	keep      bool
+	dedupeKey string
+	timestamp time.Time
+}
+
+// Scan walks the roots in parallel, then resolves the files on a pool of
+// workers that each own a COM apartment, and finally dedupes the results
+// sequentially in walk order, so the output does not depend on which
+// worker finished first.
 func (s *WindowsScanner) Scan(ctx context.Context) ([]domain.ItemInput, error) {
-	runtime.LockOSThread()
-	defer runtime.UnlockOSThread()
+	s.indexed = []domain.ScanIndexEntry{}
+
+	roots := NormalizeRoots(s.Roots)
+	if len(roots) == 0 {
+		roots = NormalizeRoots(DefaultRoots())
+	}
+	tracker := newProgressTracker(s.progress, len(roots))
+
+	files, err := walkRoots(ctx, roots, s.Concurrency, func(name string) bool {
+		_, ok := mapExtensionType(strings.ToLower(filepath.Ext(name)))
+		return ok
+	}, tracker)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := processFiles(ctx, files, s.Concurrency, s.newWorker, tracker)
+	if err != nil {
+		return nil, err
+	}
 
 	type dedupeCandidate struct {
 		item      domain.ItemInput
@@ -60,160 +107,23 @@ func (s *WindowsScanner) Scan(ctx context.Context) ([]domain.ItemInput, error) {
 
 	candidates := map[string]dedupeCandidate{}
 	keys := []string{}
-	var resolver *shortcutResolver
-	var resolverErr error
-	defer func() {
-		if resolver != nil {
-			resolver.Close()
-		}
-	}()
-
-	roots := NormalizeRoots(s.Roots)
-	if len(roots) == 0 {
-		roots = NormalizeRoots(DefaultRoots())
-	}
-	totalRoots := len(roots)
-	scanned := 0
-	lastEmit := time.Now().Add(-time.Second)
-
-	for index, root := range roots {
-		if root == "" {
-			continue
-		}
-		info, err := os.Stat(root)
-		if err != nil || !info.IsDir() {
+	for _, result := range results {
+		s.indexed = append(s.indexed, result.state)
+		if !result.keep {
 			continue
 		}
-
-		s.emitProgress(ScanProgress{
-			Root:      root,
-			Path:      root,
-			RootIndex: index + 1,
-			RootTotal: totalRoots,
-			Scanned:   scanned,
-			Percent:   rootPercent(index, totalRoots),
-		})
-
-		walkErr := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
-			if err != nil {
-				return nil
-			}
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			default:
-			}
-
-			if entry.IsDir() {
-				return nil
-			}
-
-			scanned++
-			if time.Since(lastEmit) >= 200*time.Millisecond {
-				lastEmit = time.Now()
-				s.emitProgress(ScanProgress{
-					Root:      root,
-					Path:      path,
-					RootIndex: index + 1,
-					RootTotal: totalRoots,
-					Scanned:   scanned,
-					Percent:   -1,
-				})
-			}
-
-			ext := strings.ToLower(filepath.Ext(entry.Name()))
-			itemType, ok := mapExtensionType(ext)
-			if !ok {
-				return nil
-			}
-
-			name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
-			name = strings.TrimSpace(name)
-			if ext == ".lnk" {
-				if displayName, ok := lookupDisplayName(path); ok {
-					name = displayName
-				}
-			}
-			if name == "" {
-				name = entry.Name()
-			}
-			targetName := ""
-			targetPath := ""
-
-			timestamp := time.Time{}
-			if latest, ok := latestFileTimestamp(path); ok {
-				timestamp = latest
-			} else if info, infoErr := entry.Info(); infoErr == nil {
-				timestamp = info.ModTime()
-			}
-
-			dedupeKey := strings.ToLower(path)
-			if ext == ".lnk" {
-				if resolver == nil && resolverErr == nil {
-					resolver, resolverErr = newShortcutResolver()
-				}
-				if resolver != nil {
-					target, args, err := resolver.Resolve(path)
-					if err == nil {
-						targetPath = target
-						if isUninstallerEntry(name, path, target, args) {
-							return nil
-						}
-						itemType = classifyShortcutTarget(path, target, args, itemType)
-						if shortcutKey := shortcutDedupeKey(target, args); shortcutKey != "" {
-							dedupeKey = shortcutKey
-						}
-					}
-				}
-				targetName = deriveTargetName(ext, path, targetPath)
-			} else if ext == ".exe" {
-				if isUninstallerEntry(name, path, path, "") {
-					return nil
-				}
-				if isSystemBinaryPath(path) {
-					itemType = domain.ItemTypeSystem
-				}
-				targetName = deriveTargetName(ext, path, "")
-			}
-
-			item := domain.ItemInput{
-				Name:       name,
-				Path:       path,
-				TargetName: targetName,
-				Type:       itemType,
-				IconPath:   "",
-				GroupID:    "",
-				Tags:       nil,
-				Favorite:   false,
-				Hidden:     false,
-			}
-
-			if existing, ok := candidates[dedupeKey]; ok {
-				if timestamp.After(existing.timestamp) {
-					candidates[dedupeKey] = dedupeCandidate{item: item, timestamp: timestamp}
-				}
-				return nil
+		if existing, ok := candidates[result.dedupeKey]; ok {
+			if result.timestamp.After(existing.timestamp) {
+				candidates[result.dedupeKey] = dedupeCandidate{item: result.item, timestamp: result.timestamp}
 			}
-
-			candidates[dedupeKey] = dedupeCandidate{item: item, timestamp: timestamp}
-			keys = append(keys, dedupeKey)
-
-			return nil
-		})
-		if walkErr != nil {
-			return nil, walkErr
+			continue
 		}
-
-		s.emitProgress(ScanProgress{
-			Root:      root,
-			Path:      root,
-			RootIndex: index + 1,
-			RootTotal: totalRoots,
-			Scanned:   scanned,
-			Percent:   rootPercent(index+1, totalRoots),
-		})
+		candidates[result.dedupeKey] = dedupeCandidate{item: result.item, timestamp: result.timestamp}
+		keys = append(keys, result.dedupeKey)
 	}
 
+	tracker.emit(ScanProgress{Scanned: len(files), Percent: 100}, true)
+
 	items := make([]domain.ItemInput, 0, len(keys))
 	for _, key := range keys {
 		candidate, ok := candidates[key]
@@ -226,93 +136,122 @@ func (s *WindowsScanner) Scan(ctx context.Context) ([]domain.ItemInput, error) {
 	return items, nil
 }
 
-func (s *WindowsScanner) emitProgress(progress ScanProgress) {
-	if s.progress == nil {
-		return
-	}
-	s.progress(progress)
-}
+// newWorker runs on a resolver worker goroutine. The goroutine stays on one
+// OS thread because COM apartments and the shell APIs are per thread; the
+// apartment is only created once the worker meets a shortcut.
+func (s *WindowsScanner) newWorker() (func(walkedFile) windowsFile, func()) {
+	runtime.LockOSThread()
 
-func rootPercent(index, total int) int {
-	if total <= 0 {
-		return 0
-	}
-	if index < 0 {
-		index = 0
+	var resolver *shortcutResolver
+	var resolverErr error
+	process := func(file walkedFile) windowsFile {
+		ext := strings.ToLower(filepath.Ext(file.entry.Name()))
+		if ext == ".lnk" && resolver == nil && resolverErr == nil {
+			resolver, resolverErr = newShortcutResolver()
+		}
+		return s.processFile(file, ext, resolver)
 	}
-	if index > total {
-		index = total
+	closeWorker := func() {
+		if resolver != nil {
+			resolver.Close()
+		}
+		runtime.UnlockOSThread()
 	}
-	return index * 100 / total
+	return process, closeWorker
 }
 
-func mapExtensionType(ext string) (domain.ItemType, bool) {
-	switch ext {
-	case ".lnk", ".exe":
-		return domain.ItemTypeApp, true
-	case ".url", ".htm", ".html", ".mht", ".mhtml":
-		return domain.ItemTypeURL, true
-	default:
-		return "", false
-	}
-}
+func (s *WindowsScanner) processFile(file walkedFile, ext string, resolver *shortcutResolver) windowsFile {
+	path := file.path
+	entry := file.entry
+	itemType, _ := mapExtensionType(ext)
 
-func deriveTargetName(ext, sourcePath, targetPath string) string {
-	switch strings.ToLower(ext) {
-	case ".lnk":
-		if name := targetNameFromShortcutTarget(targetPath); name != "" {
-			return name
+	name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+	name = strings.TrimSpace(name)
+	if ext == ".lnk" {
+		if displayName, ok := lookupDisplayName(path); ok {
+			name = displayName
 		}
-		return targetNameFromShortcutSource(sourcePath)
-	case ".exe":
-		return targetNameFromPath(sourcePath)
-	default:
-		return ""
 	}
-}
+	if name == "" {
+		name = entry.Name()
+	}
+	targetName := ""
+	targetPath := ""
+
+	result := windowsFile{state: domain.ScanIndexEntry{Path: path}}
+	info, infoErr := entry.Info()
+	if infoErr == nil {
+		result.state.Size = info.Size()
+		result.state.ModTime = info.ModTime()
+	}
+	previous, cached := s.previous[path]
+	cached = cached && infoErr == nil && previous.SameFile(result.state)
 
-func targetNameFromShortcutTarget(path string) string {
-	if isIconResourcePath(path) {
-		return ""
+	if latest, ok := latestFileTimestamp(path); ok {
+		result.timestamp = latest
+	} else if infoErr == nil {
+		result.timestamp = info.ModTime()
 	}
-	return targetNameFromPath(path)
-}
 
-func targetNameFromShortcutSource(path string) string {
-	base := targetNameFromPath(path)
-	if base == "" {
-		return ""
+	result.dedupeKey = strings.ToLower(path)
+	if ext == ".lnk" {
+		// Resolving through COM is the slow part of a scan, so an
+		// unchanged shortcut reuses the target found last time. One cached
+		// without a target is resolved again, since the last attempt may
+		// have hit a passing COM failure. Resolve also works without a
+		// resolver, reading the link file directly.
+		target, args, resolved := previous.Target, previous.Arguments, cached && previous.Target != ""
+		if !resolved {
+			var err error
+			target, args, err = resolver.Resolve(path)
+			resolved = err == nil
+		}
+		if resolved {
+			result.state.Target = target
+			result.state.Arguments = args
+			targetPath = target
+			if isUninstallerEntry(name, path, target, args) {
+				return result
+			}
+			itemType = classifyShortcutTarget(path, target, args, false, itemType)
+			if shortcutKey := shortcutDedupeKey(target, args); shortcutKey != "" {
+				result.dedupeKey = shortcutKey
+			}
+		}
+		targetName = deriveTargetName(ext, path, targetPath)
 	}
-	switch strings.ToLower(filepath.Ext(base)) {
-	case ".lnk", ".url":
-		return strings.TrimSuffix(base, filepath.Ext(base))
-	default:
-		return base
+	if ext == ".exe" {
+		if isUninstallerEntry(name, path, path, "") {
+			return result
+		}
+		if isSystemBinaryPath(path) {
+			itemType = domain.ItemTypeSystem
+		}
+		targetName = deriveTargetName(ext, path, "")
 	}
-}
 
-func targetNameFromPath(path string) string {
-	clean := strings.TrimSpace(path)
-	if clean == "" {
-		return ""
-	}
-	clean = strings.Trim(clean, "\"'")
-	base := strings.TrimSpace(filepath.Base(clean))
-	if base == "" || base == "." || base == string(os.PathSeparator) {
-		return ""
+	result.item = domain.ItemInput{
+		Name:       name,
+		Path:       path,
+		TargetName: targetName,
+		Type:       itemType,
+		IconPath:   "",
+		GroupID:    "",
+		Tags:       nil,
+		Favorite:   false,
+		Hidden:     false,
 	}
-	return strings.ToLower(base)
+	result.keep = true
+	return result
 }
 
-func isIconResourcePath(path string) bool {
-	clean := strings.TrimSpace(path)
-	if clean == "" {
-		return false
-	}
-	switch strings.ToLower(filepath.Ext(clean)) {
-	case ".ico", ".icl", ".dll", ".mun":
-		return true
+func mapExtensionType(ext string) (domain.ItemType, bool) {
+	switch ext {
+	case ".lnk", ".exe":
+		return domain.ItemTypeApp, true
+	case ".url", ".htm", ".html", ".mht", ".mhtml":
+		return domain.ItemTypeURL, true
 	default:
-		return false
+		return "", false
 	}
 }
@@ -0,0 +1,147 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/mozillazg/go-pinyin"
+
+	"rungrid/backend/domain"
+)
+
+var pinyinArgs = func() pinyin.Args {
+	args := pinyin.NewArgs()
+	args.Style = pinyin.Normal
+	args.Heteronym = true
+	return args
+}()
+
+// token is one matchable unit of a name: a word of Latin text (split at
+// camel-case and digit boundaries) or a single Han character with all of
+// its pinyin readings.
+type token struct {
+	readings []string
+	start    int
+	end      int
+	han      bool
+}
+
+type text struct {
+	value   string
+	runes   []rune
+	lower   []rune
+	offsets []int
+}
+
+// document caches the derived search keys of an item. fingerprint changes
+// whenever any searchable field changes, which invalidates the entry.
+type document struct {
+	fingerprint string
+	name        text
+	tokens      []token
+	wordStarts  []bool
+	target      text
+	tags        []text
+}
+
+func newDocument(item domain.Item) *document {
+	doc := &document{
+		fingerprint: fingerprintOf(item),
+		name:        newText(item.Name),
+		target:      newText(item.TargetName),
+	}
+	doc.tokens, doc.wordStarts = tokenize(doc.name.runes, doc.name.lower)
+	for _, tag := range item.Tags {
+		doc.tags = append(doc.tags, newText(tag))
+	}
+	return doc
+}
+
+func fingerprintOf(item domain.Item) string {
+	return item.Name + "\x00" + item.TargetName + "\x00" + strings.Join(item.Tags, "\x00")
+}
+
+func newText(value string) text {
+	runes := []rune(value)
+	lower := make([]rune, len(runes))
+	offsets := make([]int, len(runes)+1)
+	offset := 0
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+		offsets[i] = offset
+		offset += utf16.RuneLen(r)
+	}
+	offsets[len(runes)] = offset
+	return text{value: value, runes: runes, lower: lower, offsets: offsets}
+}
+
+// span converts a rune range into UTF-16 offsets.
+func (t text) span(start, end int) (int, int) {
+	return t.offsets[start], t.offsets[end]
+}
+
+func tokenize(runes []rune, lower []rune) ([]token, []bool) {
+	tokens := []token{}
+	wordStarts := make([]bool, len(lower))
+
+	for i := 0; i < len(lower); {
+		r := lower[i]
+		switch {
+		case unicode.Is(unicode.Han, r):
+			tokens = append(tokens, token{readings: hanReadings(r), start: i, end: i + 1, han: true})
+			wordStarts[i] = true
+			i++
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			start := i
+			i++
+			for i < len(lower) && continuesWord(runes, i) {
+				i++
+			}
+			tokens = append(tokens, token{readings: []string{string(lower[start:i])}, start: start, end: i})
+			wordStarts[start] = true
+		default:
+			i++
+		}
+	}
+	return tokens, wordStarts
+}
+
+// continuesWord reports whether rune i extends the word before it. Words
+// break at camel-case humps ("VisualStudio", "HTMLParser") and at
+// letter/digit transitions.
+func continuesWord(runes []rune, i int) bool {
+	r := runes[i]
+	prev := runes[i-1]
+	if unicode.Is(unicode.Han, r) || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		return false
+	}
+	if unicode.IsDigit(r) != unicode.IsDigit(prev) {
+		return false
+	}
+	if unicode.IsUpper(r) && unicode.IsLower(prev) {
+		return false
+	}
+	if unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+		return false
+	}
+	return true
+}
+
+func hanReadings(r rune) []string {
+	readings := pinyin.SinglePinyin(r, pinyinArgs)
+	result := make([]string, 0, len(readings))
+	seen := map[string]struct{}{}
+	for _, reading := range readings {
+		reading = strings.ToLower(strings.TrimSpace(reading))
+		if reading == "" {
+			continue
+		}
+		if _, ok := seen[reading]; ok {
+			continue
+		}
+		seen[reading] = struct{}{}
+		result = append(result, reading)
+	}
+	return append(result, string(r))
+}
@@ -0,0 +1,148 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"rungrid/backend/domain"
+)
+
+const (
+	// maxFrecencyBoost is small next to the match scores in matcher.go, which
+	// run from about 100 for a weak fuzzy match to 1000 for an exact name.
+	maxFrecencyBoost      = 15
+	frecencyBoostMidpoint = 200
+)
+
+// Engine ranks items against a free-text query. Derived keys (pinyin
+// readings, word boundaries) are cached per item ID and rebuilt whenever
+// the item's searchable fields change.
+type Engine struct {
+	mu   sync.Mutex
+	docs map[string]*document
+}
+
+func NewEngine() *Engine {
+	return &Engine{docs: make(map[string]*document)}
+}
+
+// Search returns the items matching every whitespace-separated term of
+// query, best match first. Items are expected in their default order, which
+// breaks ties between equal scores.
+func (e *Engine) Search(items []domain.Item, query string) []domain.SearchResult {
+	terms := splitTerms(query)
+	results := []domain.SearchResult{}
+	if len(terms) == 0 {
+		for _, item := range items {
+			results = append(results, domain.SearchResult{Item: item, Matches: []domain.MatchRange{}})
+		}
+		return results
+	}
+
+	for _, item := range items {
+		doc := e.document(item)
+		total := 0.0
+		ranges := []domain.MatchRange{}
+		matched := true
+		for _, term := range terms {
+			match, ok := matchTerm(doc, term)
+			if !ok {
+				matched = false
+				break
+			}
+			total += match.score
+			ranges = append(ranges, match.ranges...)
+		}
+		if !matched {
+			continue
+		}
+		results = append(results, domain.SearchResult{
+			Item:    item,
+			Score:   total/float64(len(terms)) + frecencyBoost(item.Frecency),
+			Matches: normalizeRanges(ranges),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+func (e *Engine) Forget(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.docs, id)
+}
+
+func (e *Engine) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.docs = make(map[string]*document)
+}
+
+func (e *Engine) document(item domain.Item) *document {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if doc, ok := e.docs[item.ID]; ok && doc.fingerprint == fingerprintOf(item) {
+		return doc
+	}
+	doc := newDocument(item)
+	if item.ID != "" {
+		e.docs[item.ID] = doc
+	}
+	return doc
+}
+
+// frecencyBoost lifts frequently used items among matches of similar
+// quality. It is a heuristic, not a tier: the boost stays below
+// maxFrecencyBoost, but match scores are continuous and averaged over the
+// query terms, so a much-used item can still pass a slightly better match
+// of another kind when their scores are within the boost.
+func frecencyBoost(frecency float64) float64 {
+	if frecency <= 0 {
+		return 0
+	}
+	return maxFrecencyBoost * frecency / (frecency + frecencyBoostMidpoint)
+}
+
+func splitTerms(query string) [][]rune {
+	fields := strings.Fields(query)
+	terms := make([][]rune, 0, len(fields))
+	for _, field := range fields {
+		term := []rune(strings.ToLower(field))
+		for i, r := range term {
+			term[i] = unicode.ToLower(r)
+		}
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+func normalizeRanges(ranges []domain.MatchRange) []domain.MatchRange {
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].Field != ranges[j].Field {
+			return ranges[i].Field < ranges[j].Field
+		}
+		if ranges[i].Index != ranges[j].Index {
+			return ranges[i].Index < ranges[j].Index
+		}
+		return ranges[i].Start < ranges[j].Start
+	})
+
+	merged := make([]domain.MatchRange, 0, len(ranges))
+	for _, current := range ranges {
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			if last.Field == current.Field && last.Index == current.Index && current.Start <= last.End {
+				last.End = max(last.End, current.End)
+				continue
+			}
+		}
+		merged = append(merged, current)
+	}
+	return merged
+}
@@ -0,0 +1,295 @@
+package search
+
+import (
+	"unicode"
+
+	"rungrid/backend/domain"
+)
+
+const (
+	scoreNameExact       = 1000
+	scoreNamePrefix      = 900
+	scoreTokensFromStart = 800
+	scoreNameWordStart   = 720
+	scoreTokensInside    = 650
+	scoreNameSubstring   = 600
+	scoreFuzzyMax        = 500
+	scoreFuzzyMin        = 100
+	scoreTargetExact     = 450
+	scoreTargetPrefix    = 400
+	scoreTargetSubstring = 300
+	scoreTagExact        = 420
+	scoreTagPrefix       = 380
+	scoreTagSubstring    = 250
+)
+
+const (
+	FieldName       = "name"
+	FieldTargetName = "target_name"
+	FieldTags       = "tags"
+)
+
+type termMatch struct {
+	score  float64
+	ranges []domain.MatchRange
+}
+
+// matchTerm returns the best match of a single lowercased query term
+// against any field of doc.
+func matchTerm(doc *document, term []rune) (termMatch, bool) {
+	best := termMatch{}
+	found := false
+	consider := func(candidate termMatch, ok bool) {
+		if ok && (!found || candidate.score > best.score) {
+			best = candidate
+			found = true
+		}
+	}
+
+	consider(matchName(doc, term))
+	consider(matchPlain(doc.target, term, FieldTargetName, 0, scoreTargetExact, scoreTargetPrefix, scoreTargetSubstring))
+	for index, tag := range doc.tags {
+		consider(matchPlain(tag, term, FieldTags, index, scoreTagExact, scoreTagPrefix, scoreTagSubstring))
+	}
+	return best, found
+}
+
+func matchName(doc *document, term []rune) (termMatch, bool) {
+	name := doc.name
+	if len(name.lower) == 0 {
+		return termMatch{}, false
+	}
+
+	if index := indexRunes(name.lower, term); index >= 0 {
+		switch {
+		case index == 0 && len(term) == len(name.lower):
+			return nameMatch(name, scoreNameExact, [2]int{0, len(term)}), true
+		case index == 0:
+			return nameMatch(name, scoreNamePrefix, [2]int{0, len(term)}), true
+		}
+		if start := wordStartIndex(name.lower, doc.wordStarts, term); start >= 0 {
+			return nameMatch(name, scoreNameWordStart, [2]int{start, start + len(term)}), true
+		}
+		if spans, score, ok := matchTokens(doc.tokens, term); ok && score > scoreNameSubstring {
+			return nameMatch(name, score, spans...), true
+		}
+		return nameMatch(name, scoreNameSubstring, [2]int{index, index + len(term)}), true
+	}
+
+	if spans, score, ok := matchTokens(doc.tokens, term); ok {
+		return nameMatch(name, score, spans...), true
+	}
+
+	if spans, score, ok := fuzzyMatch(name.lower, doc.wordStarts, term); ok {
+		return nameMatch(name, score, spans...), true
+	}
+	return termMatch{}, false
+}
+
+func matchPlain(value text, term []rune, field string, index int, exact, prefix, substring float64) (termMatch, bool) {
+	position := indexRunes(value.lower, term)
+	if position < 0 {
+		return termMatch{}, false
+	}
+	score := substring
+	switch {
+	case position == 0 && len(term) == len(value.lower):
+		score = exact
+	case position == 0:
+		score = prefix
+	}
+	start, end := value.span(position, position+len(term))
+	return termMatch{
+		score:  score,
+		ranges: []domain.MatchRange{{Field: field, Index: index, Start: start, End: end}},
+	}, true
+}
+
+func nameMatch(name text, score float64, spans ...[2]int) termMatch {
+	ranges := make([]domain.MatchRange, 0, len(spans))
+	for _, span := range spans {
+		start, end := name.span(span[0], span[1])
+		ranges = append(ranges, domain.MatchRange{Field: FieldName, Start: start, End: end})
+	}
+	return termMatch{score: score, ranges: ranges}
+}
+
+// matchTokens consumes term with prefixes of consecutive tokens, which
+// covers acronyms ("vsc"), pinyin initials ("wx"), full pinyin ("weixin")
+// and any mix of the three. Matches that begin at the first token score
+// higher.
+func matchTokens(tokens []token, term []rune) ([][2]int, float64, bool) {
+	for start := range tokens {
+		failed := map[[2]int]struct{}{}
+		consumed, ok := consumeTokens(tokens, start, term, 0, failed)
+		if !ok {
+			continue
+		}
+
+		spans := make([][2]int, 0, len(consumed))
+		full := 0
+		position := 0
+		for offset, count := range consumed {
+			tok := tokens[start+offset]
+			if tok.han {
+				spans = append(spans, [2]int{tok.start, tok.end})
+				// A Han character counts as fully typed when entered as
+				// itself or with more than its initial.
+				if count > 1 || unicode.Is(unicode.Han, term[position]) {
+					full++
+				}
+			} else {
+				spans = append(spans, [2]int{tok.start, tok.start + count})
+				if count >= tok.end-tok.start {
+					full++
+				}
+			}
+			position += count
+		}
+
+		score := float64(scoreTokensInside)
+		if start == 0 {
+			score = scoreTokensFromStart
+		}
+		score += float64(full*10 - (len(consumed)-full)*2)
+		return mergeSpans(spans), score, true
+	}
+	return nil, 0, false
+}
+
+func consumeTokens(tokens []token, index int, term []rune, position int, failed map[[2]int]struct{}) ([]int, bool) {
+	if index >= len(tokens) {
+		return nil, false
+	}
+	if _, ok := failed[[2]int{index, position}]; ok {
+		return nil, false
+	}
+
+	for _, reading := range tokens[index].readings {
+		runes := []rune(reading)
+		limit := min(len(runes), len(term)-position)
+		for count := limit; count >= 1; count-- {
+			if !equalRunes(runes[:count], term[position:position+count]) {
+				continue
+			}
+			if position+count == len(term) {
+				return []int{count}, true
+			}
+			if rest, ok := consumeTokens(tokens, index+1, term, position+count, failed); ok {
+				return append([]int{count}, rest...), true
+			}
+		}
+	}
+
+	failed[[2]int{index, position}] = struct{}{}
+	return nil, false
+}
+
+// fuzzyMatch finds term as a subsequence of value, rewarding consecutive
+// runs and hits on word starts and penalizing gaps.
+func fuzzyMatch(value []rune, wordStarts []bool, term []rune) ([][2]int, float64, bool) {
+	if len(term) < 2 {
+		return nil, 0, false
+	}
+
+	var bestPositions []int
+	bestScore := 0.0
+	for start := range value {
+		if value[start] != term[0] {
+			continue
+		}
+		positions := []int{start}
+		cursor := start + 1
+		for _, r := range term[1:] {
+			for cursor < len(value) && value[cursor] != r {
+				cursor++
+			}
+			if cursor >= len(value) {
+				break
+			}
+			positions = append(positions, cursor)
+			cursor++
+		}
+		if len(positions) != len(term) {
+			break
+		}
+
+		score := 300.0 - float64(min(start, 20))
+		for i, position := range positions {
+			if wordStarts[position] {
+				score += 25
+			}
+			if i > 0 {
+				if gap := position - positions[i-1] - 1; gap == 0 {
+					score += 40
+				} else {
+					score -= float64(gap * 3)
+				}
+			}
+		}
+		if bestPositions == nil || score > bestScore {
+			bestPositions = positions
+			bestScore = score
+		}
+	}
+
+	if bestPositions == nil {
+		return nil, 0, false
+	}
+	bestScore = max(scoreFuzzyMin, min(scoreFuzzyMax, bestScore))
+
+	spans := make([][2]int, 0, len(bestPositions))
+	for _, position := range bestPositions {
+		spans = append(spans, [2]int{position, position + 1})
+	}
+	return mergeSpans(spans), bestScore, true
+}
+
+func wordStartIndex(value []rune, wordStarts []bool, term []rune) int {
+	for i := 0; i+len(term) <= len(value); i++ {
+		if wordStarts[i] && equalRunes(value[i:i+len(term)], term) {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexRunes(value []rune, term []rune) int {
+	if len(term) == 0 || len(term) > len(value) {
+		return -1
+	}
+	for i := 0; i+len(term) <= len(value); i++ {
+		if equalRunes(value[i:i+len(term)], term) {
+			return i
+		}
+	}
+	return -1
+}
+
+func equalRunes(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func mergeSpans(spans [][2]int) [][2]int {
+	if len(spans) == 0 {
+		return spans
+	}
+	merged := [][2]int{spans[0]}
+	for _, span := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if span[0] <= last[1] {
+			last[1] = max(last[1], span[1])
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"rungrid/backend/desktopentry"
+	"rungrid/backend/domain"
+	"rungrid/backend/shelllink"
+	"rungrid/backend/storage"
+)
+
+// ListBroken returns the items flagged by the last check, in display order.
+func (s *ItemService) ListBroken(ctx context.Context) ([]domain.Item, error) {
+	items, err := s.repo.List(ctx, storage.ItemFilter{})
+	if err != nil {
+		return nil, err
+	}
+	broken := []domain.Item{}
+	for _, item := range items {
+		if item.Broken != "" {
+			broken = append(broken, item)
+		}
+	}
+	return broken, nil
+}
+
+// checkBroken re-checks the items whose lower-case path is in paths, or
+// every item when paths is nil, and applies policy to the ones that broke
+// since the last check. Items that work again lose their flag but stay
+// hidden if the policy hid them. It returns how many items are broken
+// afterwards and how many were removed, plus journal entries for hidden
+// and removed items so the caller can record them with its own operation.
+func (s *ItemService) checkBroken(ctx context.Context, policy domain.BrokenItemPolicy, paths map[string]struct{}) (int, int, []domain.OperationEntry, error) {
+	items, err := s.repo.List(ctx, storage.ItemFilter{})
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	broken, removed := 0, 0
+	entries := []domain.OperationEntry{}
+	for _, item := range items {
+		if paths != nil {
+			if _, ok := paths[strings.ToLower(item.Path)]; !ok {
+				if item.Broken != "" {
+					broken++
+				}
+				continue
+			}
+		}
+		reason := brokenReason(item)
+		if reason == item.Broken {
+			if reason != "" {
+				broken++
+			}
+			continue
+		}
+		// Only act on items that just broke; a broken item the user chose
+		// to show again is left alone.
+		if reason != "" && item.Broken == "" {
+			switch policy {
+			case domain.BrokenItemRemove:
+				entry, err := s.deleteEntry(ctx, item)
+				if err != nil {
+					return broken, removed, entries, err
+				}
+				entries = append(entries, entry)
+				removed++
+				continue
+			case domain.BrokenItemHide:
+				if !item.Hidden {
+					before := item
+					item.Hidden = true
+					item.Broken = reason
+					if _, err := s.repo.Update(ctx, item); err != nil {
+						return broken, removed, entries, err
+					}
+					entries = append(entries, itemEntry(item.ID, &before))
+					broken++
+					continue
+				}
+			}
+		}
+		if err := s.repo.SetBroken(ctx, item.ID, reason); err != nil {
+			return broken, removed, entries, err
+		}
+		if reason != "" {
+			broken++
+		}
+	}
+	return broken, removed, entries, nil
+}
+
+// brokenReason checks that an item's file, and the target of a shortcut,
+// still exist. Web links, relative and network paths, and paths on a drive
+// that is not mounted are never reported, since their absence proves
+// nothing.
+func brokenReason(item domain.Item) domain.BrokenReason {
+	path := strings.TrimSpace(item.Path)
+	if !checkablePath(path) {
+		return ""
+	}
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return domain.BrokenMissingSource
+		}
+		return ""
+	}
+	if shortcutTargetMissing(path) {
+		return domain.BrokenMissingTarget
+	}
+	return ""
+}
+
+func checkablePath(path string) bool {
+	if path == "" || isWebURL(path) || isUNCPath(path) || !filepath.IsAbs(path) {
+		return false
+	}
+	volume := filepath.VolumeName(path)
+	if volume == "" {
+		return true
+	}
+	_, err := os.Stat(volume + string(filepath.Separator))
+	return err == nil
+}
+
+// shortcutTargetMissing reads .lnk files without COM and .desktop files by
+// their TryExec or Exec program. Advertised (MSI) shortcuts and flatpak
+// entries cannot be checked and count as present.
+func shortcutTargetMissing(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".lnk":
+		link, err := shelllink.ParseFile(path)
+		if err != nil || link.Advertised() {
+			return false
+		}
+		target := expandPercentEnv(strings.TrimSpace(link.Target()))
+		if !checkablePath(target) {
+			return false
+		}
+		_, err = os.Stat(target)
+		return errors.Is(err, os.ErrNotExist)
+	case ".desktop":
+		entry, err := desktopentry.ParseFile(path)
+		if err != nil || (entry.Type != "" && entry.Type != "Application") {
+			return false
+		}
+		program := strings.TrimSpace(entry.TryExec)
+		if program == "" {
+			args, err := desktopentry.SplitExec(entry.Exec)
+			if err != nil || len(args) == 0 {
+				return false
+			}
+			program = args[0]
+		}
+		if filepath.IsAbs(program) {
+			_, err := os.Stat(program)
+			return errors.Is(err, os.ErrNotExist)
+		}
+		_, err = exec.LookPath(program)
+		return err != nil
+	}
+	return false
+}
+
+// expandPercentEnv expands Windows style %NAME% references. Unknown names
+// are left as they are.
+func expandPercentEnv(value string) string {
+	var builder strings.Builder
+	for {
+		start := strings.IndexByte(value, '%')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(value[start+1:], '%')
+		if end < 0 {
+			break
+		}
+		end += start + 1
+		name := value[start+1 : end]
+		if replacement, ok := os.LookupEnv(name); ok && name != "" {
+			builder.WriteString(value[:start])
+			builder.WriteString(replacement)
+		} else {
+			builder.WriteString(value[:end+1])
+		}
+		value = value[end+1:]
+	}
+	builder.WriteString(value)
+	return builder.String()
+}
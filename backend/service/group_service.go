@@ -2,20 +2,30 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/google/uuid"
 
 	"rungrid/backend/domain"
+	"rungrid/backend/query"
 	"rungrid/backend/storage"
 )
 
+// ErrGroupCycle is returned when a group would be nested in itself or in
+// one of its subgroups.
+var ErrGroupCycle = errors.New("a group cannot be nested in itself or its subgroups")
+
 type GroupService struct {
-	repo storage.GroupRepository
+	repo  storage.GroupRepository
+	items *ItemService
 }
 
-func NewGroupService(repo storage.GroupRepository) *GroupService {
-	return &GroupService{repo: repo}
+// NewGroupService wires the group store. items is used to journal and
+// reindex the items touched when a group is deleted.
+func NewGroupService(repo storage.GroupRepository, items *ItemService) *GroupService {
+	return &GroupService{repo: repo, items: items}
 }
 
 func (s *GroupService) List(ctx context.Context) ([]domain.Group, error) {
@@ -32,30 +42,79 @@ func (s *GroupService) Create(ctx context.Context, input domain.GroupInput) (dom
 		return domain.Group{}, err
 	}
 
+	kind, source, err := normalizeGroupQuery(input.Kind, input.Query)
+	if err != nil {
+		return domain.Group{}, err
+	}
+
+	parentID := strings.TrimSpace(input.ParentID)
+	if err := s.checkParent(ctx, "", parentID, category); err != nil {
+		return domain.Group{}, err
+	}
+
 	group := domain.Group{
 		ID:       uuid.NewString(),
+		ParentID: parentID,
 		Name:     strings.TrimSpace(input.Name),
 		Order:    input.Order,
 		Color:    strings.TrimSpace(input.Color),
 		Category: category,
 		Icon:     strings.TrimSpace(input.Icon),
+		Kind:     kind,
+		Query:    source,
 	}
 
 	return s.repo.Create(ctx, group)
 }
 
+// Update changes the fields of a group. The parent and the kind are kept;
+// Move changes the parent. An empty query keeps the query of a smart group.
 func (s *GroupService) Update(ctx context.Context, group domain.Group) (domain.Group, error) {
+	return s.update(ctx, group, true)
+}
+
+// Move nests a group in parentID, or makes it a top-level group when
+// parentID is empty. The parent must have the same category, and a group
+// cannot be moved into its own subtree.
+func (s *GroupService) Move(ctx context.Context, id string, parentID string) (domain.Group, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return domain.Group{}, storage.ErrInvalidInput
+	}
+	group, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return domain.Group{}, err
+	}
+	group.ParentID = parentID
+	return s.update(ctx, group, false)
+}
+
+// update saves group, keeping the stored parent when keepParent is set. The
+// parent is checked again whenever it or the category changes, and a new
+// category is applied to the groups nested below in the same transaction.
+func (s *GroupService) update(ctx context.Context, group domain.Group, keepParent bool) (domain.Group, error) {
 	if strings.TrimSpace(group.ID) == "" || strings.TrimSpace(group.Name) == "" {
 		return domain.Group{}, storage.ErrInvalidInput
 	}
+	existing, err := s.repo.Get(ctx, group.ID)
+	if err != nil {
+		return domain.Group{}, err
+	}
+	if keepParent {
+		group.ParentID = existing.ParentID
+	}
+	group.ParentID = strings.TrimSpace(group.ParentID)
 	group.Name = strings.TrimSpace(group.Name)
 	group.Color = strings.TrimSpace(group.Color)
 	group.Icon = strings.TrimSpace(group.Icon)
+	group.Kind = existing.Kind
+	if strings.TrimSpace(group.Query) == "" {
+		group.Query = existing.Query
+	}
+	if group.Kind, group.Query, err = normalizeGroupQuery(group.Kind, group.Query); err != nil {
+		return domain.Group{}, err
+	}
 	if strings.TrimSpace(group.Category) == "" {
-		existing, err := s.repo.Get(ctx, group.ID)
-		if err != nil {
-			return domain.Group{}, err
-		}
 		group.Category = existing.Category
 	} else {
 		category, err := normalizeGroupCategory(group.Category)
@@ -64,11 +123,241 @@ func (s *GroupService) Update(ctx context.Context, group domain.Group) (domain.G
 		}
 		group.Category = category
 	}
-	return s.repo.Update(ctx, group)
+	if group.Category != existing.Category || group.ParentID != existing.ParentID {
+		if err := s.checkParent(ctx, group.ID, group.ParentID, group.Category); err != nil {
+			return domain.Group{}, err
+		}
+	}
+	if group.Category == existing.Category {
+		return s.repo.Update(ctx, group)
+	}
+
+	// Subgroups share the category of their parent, so the whole subtree
+	// moves to the new category together.
+	subtree, err := s.Subtree(ctx, group.ID)
+	if err != nil {
+		return domain.Group{}, err
+	}
+	var updated domain.Group
+	err = s.items.withinTx(ctx, func(tx storage.Tx) error {
+		groups := s.bind(tx, s.items.bind(tx))
+		var err error
+		if updated, err = groups.repo.Update(ctx, group); err != nil {
+			return err
+		}
+		for _, id := range subtree[1:] {
+			child, err := groups.repo.Get(ctx, id)
+			if err != nil {
+				return err
+			}
+			child.Category = group.Category
+			if _, err := groups.repo.Update(ctx, child); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Group{}, err
+	}
+	return updated, nil
 }
 
-func (s *GroupService) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+// Subtree returns id followed by the IDs of all groups nested below it.
+func (s *GroupService) Subtree(ctx context.Context, id string) ([]string, error) {
+	groups, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	children := map[string][]string{}
+	for _, group := range groups {
+		children[group.ParentID] = append(children[group.ParentID], group.ID)
+	}
+
+	ids := []string{id}
+	seen := map[string]struct{}{id: {}}
+	for index := 0; index < len(ids); index++ {
+		for _, child := range children[ids[index]] {
+			if _, ok := seen[child]; ok {
+				continue
+			}
+			seen[child] = struct{}{}
+			ids = append(ids, child)
+		}
+	}
+	return ids, nil
+}
+
+// ItemFilter builds the filter listing the items shown under groupID: the
+// items matching the query of a smart group, or the items of a manual group
+// and of the groups nested below it. An empty groupID or "all" lists every
+// item. text is the search text typed by the user.
+func (s *GroupService) ItemFilter(ctx context.Context, groupID string, text string) (storage.ItemFilter, error) {
+	filter := storage.ItemFilter{GroupID: groupID, Query: text}
+	if groupID == "" || groupID == "all" {
+		return filter, nil
+	}
+	group, err := s.repo.Get(ctx, groupID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return filter, nil
+	}
+	if err != nil {
+		return storage.ItemFilter{}, err
+	}
+	if group.Kind == domain.GroupKindSmart {
+		return storage.ItemFilter{Query: text, Smart: group.Query}, nil
+	}
+	ids, err := s.Subtree(ctx, groupID)
+	if err != nil {
+		return storage.ItemFilter{}, err
+	}
+	filter.GroupIDs = ids
+	return filter, nil
+}
+
+// checkParent validates parentID as the parent of the group id, which is
+// empty for a group that does not exist yet.
+func (s *GroupService) checkParent(ctx context.Context, id string, parentID string, category string) error {
+	if parentID == "" {
+		return nil
+	}
+	if parentID == id {
+		return ErrGroupCycle
+	}
+	groups, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]domain.Group, len(groups))
+	for _, group := range groups {
+		byID[group.ID] = group
+	}
+
+	parent, ok := byID[parentID]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	if parent.Category != category || parent.Kind == domain.GroupKindSmart {
+		return storage.ErrInvalidInput
+	}
+	if id == "" {
+		return nil
+	}
+	// Walk up from the new parent; seen guards against a cycle already
+	// stored in the database.
+	seen := map[string]struct{}{}
+	for current := parent.ID; current != ""; current = byID[current].ParentID {
+		if current == id {
+			return ErrGroupCycle
+		}
+		if _, ok := seen[current]; ok {
+			break
+		}
+		seen[current] = struct{}{}
+	}
+	return nil
+}
+
+// Delete removes a group. Depending on the policy its items move to
+// options.TargetID, become ungrouped, or are deleted with their launch
+// history; its subgroups move up to its parent. The group, its items and
+// the journal entry change in one transaction, so the deletion can always
+// be undone. An empty policy ungroups the items.
+func (s *GroupService) Delete(ctx context.Context, id string, options domain.GroupDeleteOptions) (domain.GroupDeleteResult, error) {
+	id = strings.TrimSpace(id)
+	policy := options.Policy
+	if policy == "" {
+		policy = domain.GroupDeleteUngroup
+	}
+	if id == "" || !policy.IsValid() {
+		return domain.GroupDeleteResult{}, storage.ErrInvalidInput
+	}
+
+	target := ""
+	if policy == domain.GroupDeleteMoveItems {
+		target = strings.TrimSpace(options.TargetID)
+		if target == "" || target == id {
+			return domain.GroupDeleteResult{}, storage.ErrInvalidInput
+		}
+		targetGroup, err := s.repo.Get(ctx, target)
+		if err != nil {
+			return domain.GroupDeleteResult{}, err
+		}
+		if targetGroup.Kind == domain.GroupKindSmart {
+			return domain.GroupDeleteResult{}, storage.ErrInvalidInput
+		}
+	}
+
+	group, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return domain.GroupDeleteResult{}, err
+	}
+	items, err := s.items.repo.List(ctx, storage.ItemFilter{GroupID: id})
+	if err != nil {
+		return domain.GroupDeleteResult{}, err
+	}
+
+	groups, err := s.repo.List(ctx)
+	if err != nil {
+		return domain.GroupDeleteResult{}, err
+	}
+
+	deleteItems := policy == domain.GroupDeleteItems
+	entries := make([]domain.OperationEntry, 0, len(items)+1)
+	entries = append(entries, groupEntry(group.ID, &group))
+	for index := range groups {
+		if groups[index].ParentID == id {
+			entries = append(entries, groupEntry(groups[index].ID, &groups[index]))
+		}
+	}
+	for index := range items {
+		entry := itemEntry(items[index].ID, &items[index])
+		if deleteItems && s.items.launches != nil {
+			launches, err := s.items.launches.List(ctx, storage.LaunchFilter{ItemID: items[index].ID})
+			if err != nil {
+				return domain.GroupDeleteResult{}, err
+			}
+			entry.Launches = launches
+		}
+		entries = append(entries, entry)
+	}
+
+	summary := fmt.Sprintf("deleted group %s (%s %d items)", group.Name, groupDeleteVerb(policy), len(items))
+	err = s.items.withinTx(ctx, func(tx storage.Tx) error {
+		items := s.items.bind(tx)
+		if err := s.bind(tx, items).repo.DeleteWithItems(ctx, id, target, deleteItems); err != nil {
+			return err
+		}
+		_, err := items.journal.Record(ctx, domain.OperationDeleteGroup, summary, entries)
+		return err
+	})
+	if err != nil {
+		return domain.GroupDeleteResult{}, err
+	}
+	if deleteItems {
+		for _, item := range items {
+			s.items.search.Forget(item.ID)
+		}
+	}
+	return domain.GroupDeleteResult{Items: len(items)}, nil
+}
+
+func groupDeleteVerb(policy domain.GroupDeletePolicy) string {
+	switch policy {
+	case domain.GroupDeleteMoveItems:
+		return "moved"
+	case domain.GroupDeleteItems:
+		return "deleted"
+	default:
+		return "ungrouped"
+	}
+}
+
+// RepairOrphans ungroups items that point at a group which no longer
+// exists, for example after a crash between deleting a group and moving its
+// items in older versions.
+func (s *GroupService) RepairOrphans(ctx context.Context) (int, error) {
+	return s.repo.UngroupOrphans(ctx)
 }
 
 func normalizeGroupCategory(category string) (string, error) {
@@ -84,3 +373,20 @@ func normalizeGroupCategory(category string) (string, error) {
 		return "", storage.ErrInvalidInput
 	}
 }
+
+// normalizeGroupQuery defaults an empty kind to manual and checks that a
+// smart group has a query that parses. Manual groups have no query.
+func normalizeGroupQuery(kind domain.GroupKind, source string) (domain.GroupKind, string, error) {
+	switch kind {
+	case "", domain.GroupKindManual:
+		return domain.GroupKindManual, "", nil
+	case domain.GroupKindSmart:
+		source = strings.TrimSpace(source)
+		if _, err := query.Parse(source); err != nil {
+			return "", "", fmt.Errorf("%w: %v", storage.ErrInvalidInput, err)
+		}
+		return domain.GroupKindSmart, source, nil
+	default:
+		return "", "", storage.ErrInvalidInput
+	}
+}
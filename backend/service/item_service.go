@@ -2,25 +2,154 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"rungrid/backend/domain"
+	"rungrid/backend/frecency"
+	"rungrid/backend/query"
+	"rungrid/backend/search"
 	"rungrid/backend/storage"
 )
 
 type ItemService struct {
-	repo storage.ItemRepository
+	repo     storage.ItemRepository
+	launches storage.LaunchRepository
+	groups   storage.GroupRepository
+	frecency *frecency.Scorer
+	search   *search.Engine
+	journal  *OperationService
+	uow      storage.UnitOfWork
 }
 
-func NewItemService(repo storage.ItemRepository) *ItemService {
-	return &ItemService{repo: repo}
+// NewItemService wires the item store. groups is used to keep items out of
+// smart groups. journal may be nil, in which case bulk changes cannot be
+// undone, and uow may be nil, in which case multi-step changes are not
+// rolled back when a later step fails.
+func NewItemService(repo storage.ItemRepository, launches storage.LaunchRepository, groups storage.GroupRepository, journal *OperationService, uow storage.UnitOfWork) *ItemService {
+	return &ItemService{
+		repo:     repo,
+		launches: launches,
+		groups:   groups,
+		journal:  journal,
+		uow:      uow,
+		frecency: frecency.NewScorer(frecency.DefaultConfig()),
+		search:   search.NewEngine(),
+	}
 }
 
 func (s *ItemService) List(ctx context.Context, filter storage.ItemFilter) ([]domain.Item, error) {
-	return s.repo.List(ctx, filter)
+	if strings.TrimSpace(filter.Query) == "" {
+		return s.listRanked(ctx, filter)
+	}
+
+	results, err := s.Search(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]domain.Item, 0, len(results))
+	for _, result := range results {
+		items = append(items, result.Item)
+	}
+	return items, nil
+}
+
+// Search ranks the items of filter.GroupID against filter.Query and reports
+// which parts of each item matched.
+func (s *ItemService) Search(ctx context.Context, filter storage.ItemFilter) ([]domain.SearchResult, error) {
+	query := filter.Query
+	filter.Query = ""
+	items, err := s.listRanked(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.search.Search(items, query), nil
+}
+
+// matchSmart keeps the items matching the query of a smart group.
+func matchSmart(items []domain.Item, source string) ([]domain.Item, error) {
+	smart, err := query.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	matched := items[:0]
+	for _, item := range items {
+		if smart.Match(item, now) {
+			matched = append(matched, item)
+		}
+	}
+	return matched, nil
+}
+
+// listRanked lists items with their frecency filled in, in SortItems order.
+func (s *ItemService) listRanked(ctx context.Context, filter storage.ItemFilter) ([]domain.Item, error) {
+	items, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Smart != "" {
+		if items, err = matchSmart(items, filter.Smart); err != nil {
+			return nil, err
+		}
+	}
+	if s.launches == nil {
+		return items, nil
+	}
+
+	recent, err := s.launches.Recent(ctx, s.frecency.SampleSize())
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range items {
+		items[i].Frecency = s.frecency.Score(items[i].LaunchCount, launchSamples(items[i], recent[items[i].ID]), now)
+	}
+	storage.SortItems(items)
+	return items, nil
+}
+
+// Frecency explains the frecency score of a single item.
+func (s *ItemService) Frecency(ctx context.Context, id string) (domain.FrecencyBreakdown, error) {
+	if strings.TrimSpace(id) == "" {
+		return domain.FrecencyBreakdown{}, storage.ErrInvalidInput
+	}
+
+	item, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return domain.FrecencyBreakdown{}, err
+	}
+
+	var recent []time.Time
+	if s.launches != nil {
+		recent, err = s.launches.RecentForItem(ctx, id, s.frecency.SampleSize())
+		if err != nil {
+			return domain.FrecencyBreakdown{}, err
+		}
+	}
+
+	breakdown := s.frecency.Breakdown(item.LaunchCount, launchSamples(item, recent), time.Now())
+	breakdown.ItemID = item.ID
+	return breakdown, nil
+}
+
+func (s *ItemService) SetFrecencyHalfLife(halfLife time.Duration) {
+	config := s.frecency.Config()
+	config.HalfLife = halfLife
+	s.frecency.SetConfig(config)
+}
+
+// launchSamples falls back to last_used_at for items launched before launch
+// history was recorded.
+func launchSamples(item domain.Item, recent []time.Time) []time.Time {
+	if len(recent) == 0 && item.LastUsedAt != nil && item.LaunchCount > 0 {
+		return []time.Time{*item.LastUsedAt}
+	}
+	return recent
 }
 
 func (s *ItemService) Get(ctx context.Context, id string) (domain.Item, error) {
@@ -65,6 +194,9 @@ func (s *ItemService) Create(ctx context.Context, input domain.ItemInput) (domai
 	if err := validateItemInput(input); err != nil {
 		return domain.Item{}, err
 	}
+	if err := s.checkGroup(ctx, input.GroupID); err != nil {
+		return domain.Item{}, err
+	}
 
 	item := domain.Item{
 		ID:       uuid.NewString(),
@@ -82,6 +214,38 @@ func (s *ItemService) Create(ctx context.Context, input domain.ItemInput) (domai
 	return s.repo.Create(ctx, item)
 }
 
+// UpsertByPath creates or refreshes items by path in one batch. Inputs that
+// fail validation are reported as invalid and the rest are written in one
+// transaction; the outcomes keep the order of inputs.
+func (s *ItemService) UpsertByPath(ctx context.Context, inputs []domain.ItemInput) ([]domain.ItemUpsert, error) {
+	results := make([]domain.ItemUpsert, len(inputs))
+	valid := make([]domain.ItemInput, 0, len(inputs))
+	positions := make([]int, 0, len(inputs))
+	for index, input := range inputs {
+		if err := validateItemInput(input); err != nil {
+			results[index] = domain.ItemUpsert{Outcome: domain.UpsertInvalid}
+			continue
+		}
+		input.Name = strings.TrimSpace(input.Name)
+		input.Path = strings.TrimSpace(input.Path)
+		input.TargetName = strings.TrimSpace(input.TargetName)
+		input.IconPath = strings.TrimSpace(input.IconPath)
+		input.GroupID = strings.TrimSpace(input.GroupID)
+		input.Tags = dedupeTags(input.Tags)
+		valid = append(valid, input)
+		positions = append(positions, index)
+	}
+
+	upserts, err := s.repo.UpsertByPath(ctx, valid)
+	if err != nil {
+		return nil, err
+	}
+	for index, upsert := range upserts {
+		results[positions[index]] = upsert
+	}
+	return results, nil
+}
+
 func (s *ItemService) Update(ctx context.Context, input domain.ItemUpdate) (domain.Item, error) {
 	if strings.TrimSpace(input.ID) == "" {
 		return domain.Item{}, storage.ErrInvalidInput
@@ -120,20 +284,224 @@ func (s *ItemService) Update(ctx context.Context, input domain.ItemUpdate) (doma
 	}
 	updated.Favorite = input.Favorite
 	updated.Hidden = input.Hidden
+	if updated.Path != current.Path {
+		updated.Broken = brokenReason(updated)
+	}
+	if updated.GroupID != current.GroupID {
+		if err := s.checkGroup(ctx, updated.GroupID); err != nil {
+			return domain.Item{}, err
+		}
+	}
 
 	return s.repo.Update(ctx, updated)
 }
 
+// checkGroup rejects a smart group as an item's group: smart groups list
+// the items matching their query, so an item assigned to one would show up
+// in no group at all.
+func (s *ItemService) checkGroup(ctx context.Context, groupID string) error {
+	groupID = strings.TrimSpace(groupID)
+	if groupID == "" || s.groups == nil {
+		return nil
+	}
+	group, err := s.groups.Get(ctx, groupID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if group.Kind == domain.GroupKindSmart {
+		return storage.ErrInvalidInput
+	}
+	return nil
+}
+
 func (s *ItemService) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	return s.withinTx(ctx, func(tx storage.Tx) error {
+		items := s.bind(tx)
+		if err := items.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		s.search.Forget(id)
+		if items.launches != nil {
+			return items.launches.DeleteByItem(ctx, id)
+		}
+		return nil
+	})
 }
 
+// deleteEntry deletes an item and returns its journal entry, launch history
+// included, for the caller to record.
+func (s *ItemService) deleteEntry(ctx context.Context, item domain.Item) (domain.OperationEntry, error) {
+	entry := itemEntry(item.ID, &item)
+	if s.journal != nil && s.launches != nil {
+		launches, err := s.launches.List(ctx, storage.LaunchFilter{ItemID: item.ID})
+		if err != nil {
+			return entry, err
+		}
+		entry.Launches = launches
+	}
+	return entry, s.Delete(ctx, item.ID)
+}
+
+// Clear deletes every item and its launch history. The deleted items are
+// journaled so the clear can be undone.
 func (s *ItemService) Clear(ctx context.Context) (int, error) {
-	return s.repo.Clear(ctx)
+	count := 0
+	err := s.withinTx(ctx, func(tx storage.Tx) error {
+		items := s.bind(tx)
+		entries, err := items.clearEntries(ctx)
+		if err != nil {
+			return err
+		}
+
+		count, err = items.repo.Clear(ctx)
+		if err != nil {
+			return err
+		}
+		if items.launches != nil {
+			if err := items.launches.Clear(ctx); err != nil {
+				return err
+			}
+		}
+		_, err = items.journal.Record(ctx, domain.OperationClearItems, fmt.Sprintf("cleared %d items", count), entries)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.search.Reset()
+	return count, nil
+}
+
+func (s *ItemService) clearEntries(ctx context.Context) ([]domain.OperationEntry, error) {
+	if s.journal == nil {
+		return nil, nil
+	}
+	items, err := s.repo.List(ctx, storage.ItemFilter{})
+	if err != nil {
+		return nil, err
+	}
+	history := map[string][]domain.Launch{}
+	if s.launches != nil {
+		launches, err := s.launches.List(ctx, storage.LaunchFilter{})
+		if err != nil {
+			return nil, err
+		}
+		for _, launch := range launches {
+			history[launch.ItemID] = append(history[launch.ItemID], launch)
+		}
+	}
+
+	entries := make([]domain.OperationEntry, 0, len(items))
+	for index := range items {
+		entry := itemEntry(items[index].ID, &items[index])
+		entry.Launches = history[items[index].ID]
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// BulkUpdate applies several updates as one undoable operation. Every
+// update is validated before any is written, and if one fails none is
+// kept.
+func (s *ItemService) BulkUpdate(ctx context.Context, updates []domain.ItemUpdate) ([]domain.Item, error) {
+	if len(updates) == 0 {
+		return []domain.Item{}, nil
+	}
+
+	entries := make([]domain.OperationEntry, 0, len(updates))
+	seen := map[string]struct{}{}
+	for _, update := range updates {
+		id := strings.TrimSpace(update.ID)
+		if id == "" || (update.Type != "" && !update.Type.IsValid()) {
+			return nil, storage.ErrInvalidInput
+		}
+		if _, ok := seen[id]; ok {
+			return nil, storage.ErrInvalidInput
+		}
+		seen[id] = struct{}{}
+		current, err := s.repo.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, itemEntry(id, &current))
+	}
+
+	updated := make([]domain.Item, 0, len(updates))
+	err := s.withinTx(ctx, func(tx storage.Tx) error {
+		items := s.bind(tx)
+		for _, update := range updates {
+			item, err := items.Update(ctx, update)
+			if err != nil {
+				return err
+			}
+			updated = append(updated, item)
+		}
+		_, err := items.journal.Record(ctx, domain.OperationBulkEdit, fmt.Sprintf("edited %d items", len(updated)), entries)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *ItemService) RecordLaunch(ctx context.Context, id string, source domain.LaunchSource) (domain.Item, error) {
+	source, err := normalizeLaunchSource(source)
+	if err != nil {
+		return domain.Item{}, err
+	}
+
+	// The launch count and the launch history are written together so they
+	// cannot drift apart.
+	now := time.Now()
+	var item domain.Item
+	err = s.withinTx(ctx, func(tx storage.Tx) error {
+		items := s.bind(tx)
+		var err error
+		if item, err = items.repo.IncrementLaunch(ctx, id, now); err != nil {
+			return err
+		}
+		if items.launches == nil {
+			return nil
+		}
+		return items.launches.Record(ctx, domain.Launch{ItemID: id, LaunchedAt: now, Source: source, Success: true})
+	})
+	if err != nil {
+		return domain.Item{}, err
+	}
+	return item, nil
 }
 
-func (s *ItemService) RecordLaunch(ctx context.Context, id string) (domain.Item, error) {
-	return s.repo.IncrementLaunch(ctx, id, time.Now())
+// RecordLaunchFailure keeps a failed attempt in the launch history without
+// touching the item's launch count.
+func (s *ItemService) RecordLaunchFailure(ctx context.Context, id string, source domain.LaunchSource, cause error) error {
+	if s.launches == nil || cause == nil {
+		return nil
+	}
+	source, err := normalizeLaunchSource(source)
+	if err != nil {
+		return err
+	}
+	return s.launches.Record(ctx, domain.Launch{
+		ItemID:     id,
+		LaunchedAt: time.Now(),
+		Source:     source,
+		Error:      cause.Error(),
+	})
+}
+
+func normalizeLaunchSource(source domain.LaunchSource) (domain.LaunchSource, error) {
+	clean := domain.LaunchSource(strings.ToLower(strings.TrimSpace(string(source))))
+	if clean == "" {
+		return domain.LaunchSourceGrid, nil
+	}
+	if !clean.IsValid() {
+		return "", storage.ErrInvalidInput
+	}
+	return clean, nil
 }
 
 func validateItemInput(input domain.ItemInput) error {
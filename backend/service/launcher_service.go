@@ -22,7 +22,7 @@ func NewLauncherService(launcher launcher.Launcher, items *ItemService) *Launche
 	return &LauncherService{launcher: launcher, items: items}
 }
 
-func (s *LauncherService) LaunchItem(ctx context.Context, id string) (domain.Item, error) {
+func (s *LauncherService) LaunchItem(ctx context.Context, id string, source domain.LaunchSource) (domain.Item, error) {
 	if strings.TrimSpace(id) == "" {
 		return domain.Item{}, storage.ErrInvalidInput
 	}
@@ -37,14 +37,16 @@ func (s *LauncherService) LaunchItem(ctx context.Context, id string) (domain.Ite
 	}
 
 	if err := validateLaunchTarget(item); err != nil {
+		_ = s.items.RecordLaunchFailure(ctx, id, source, err)
 		return domain.Item{}, err
 	}
 
 	if err := s.launcher.Open(ctx, item.Path); err != nil {
+		_ = s.items.RecordLaunchFailure(ctx, id, source, err)
 		return domain.Item{}, err
 	}
 
-	return s.items.RecordLaunch(ctx, id)
+	return s.items.RecordLaunch(ctx, id, source)
 }
 
 func (s *LauncherService) OpenItemLocation(ctx context.Context, id string) error {
@@ -125,7 +127,7 @@ func resolveLocationPath(ctx context.Context, item domain.Item) (string, error)
 	}
 
 	location := target
-	if strings.EqualFold(filepath.Ext(target), ".lnk") {
+	if ext := filepath.Ext(target); strings.EqualFold(ext, ".lnk") || strings.EqualFold(ext, ".desktop") {
 		resolved, err := resolveShortcutTarget(ctx, target)
 		if err == nil && strings.TrimSpace(resolved) != "" {
 			location = strings.TrimSpace(resolved)
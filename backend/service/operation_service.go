@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/storage"
+)
+
+var (
+	ErrOperationUndone = errors.New("operation already undone")
+	ErrUndoBlocked     = errors.New("the items or groups changed after the operation")
+)
+
+// operationHistoryLimit bounds the journal; older operations can no longer
+// be undone.
+const operationHistoryLimit = 50
+
+// OperationService journals bulk changes so they can be undone. A nil
+// service records nothing.
+type OperationService struct {
+	repo   storage.OperationRepository
+	items  storage.ItemRepository
+	groups storage.GroupRepository
+	uow    storage.UnitOfWork
+}
+
+// NewOperationService wires the journal. items and groups are read to
+// record how an operation left each row and to find later changes on undo.
+// uow may be nil, in which case that check and the restore do not share a
+// transaction.
+func NewOperationService(repo storage.OperationRepository, items storage.ItemRepository, groups storage.GroupRepository, uow storage.UnitOfWork) *OperationService {
+	return &OperationService{repo: repo, items: items, groups: groups, uow: uow}
+}
+
+// Record stores the before-images of an operation that has just been
+// applied, together with the rows as it left them. Operations without
+// entries are not journaled.
+func (s *OperationService) Record(ctx context.Context, kind domain.OperationKind, summary string, entries []domain.OperationEntry) (domain.Operation, error) {
+	if s == nil || s.repo == nil || len(entries) == 0 {
+		return domain.Operation{}, nil
+	}
+
+	entries = append([]domain.OperationEntry(nil), entries...)
+	for index := range entries {
+		after, err := s.snapshot(ctx, entries[index])
+		if err != nil {
+			return domain.Operation{}, err
+		}
+		entries[index].After = after
+	}
+
+	operation := domain.Operation{
+		ID:        uuid.NewString(),
+		Kind:      kind,
+		Summary:   strings.TrimSpace(summary),
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Create(ctx, operation, entries); err != nil {
+		return domain.Operation{}, err
+	}
+	if err := s.repo.Prune(ctx, operationHistoryLimit); err != nil {
+		return domain.Operation{}, err
+	}
+	return s.repo.Get(ctx, operation.ID)
+}
+
+func (s *OperationService) List(ctx context.Context, limit int) ([]domain.Operation, error) {
+	if limit <= 0 || limit > operationHistoryLimit {
+		limit = operationHistoryLimit
+	}
+	return s.repo.List(ctx, limit)
+}
+
+// Undo restores the state from before the operation. It refuses when the
+// items or groups changed after the operation, by a later operation that
+// is still in effect or by an edit that is not journaled, since restoring
+// would silently discard that change. The check and the restore run in one
+// transaction.
+func (s *OperationService) Undo(ctx context.Context, id string) (domain.Operation, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return domain.Operation{}, storage.ErrInvalidInput
+	}
+
+	var undone domain.Operation
+	err := s.withinTx(ctx, func(tx storage.Tx) error {
+		journal := s.bind(tx)
+		operation, err := journal.repo.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if operation.UndoneAt != nil {
+			return ErrOperationUndone
+		}
+		entries, err := journal.repo.Entries(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := journal.checkUndo(ctx, id, entries); err != nil {
+			return err
+		}
+		undone, err = journal.repo.Undo(ctx, id, time.Now())
+		return err
+	})
+	if err != nil {
+		return domain.Operation{}, err
+	}
+	return undone, nil
+}
+
+// checkUndo returns ErrUndoBlocked when a later operation that is still in
+// effect touched the same rows, when a row no longer looks the way the
+// operation left it, or when an item it would restore has a path another
+// item took since, as a rescan does after a clear. Entries journaled
+// without an after-image skip the second check.
+func (s *OperationService) checkUndo(ctx context.Context, id string, entries []domain.OperationEntry) error {
+	touched := map[string]struct{}{}
+	for _, entry := range entries {
+		touched[entryKey(entry)] = struct{}{}
+	}
+
+	operations, err := s.repo.List(ctx, 0)
+	if err != nil {
+		return err
+	}
+	for _, later := range operations {
+		if later.ID == id {
+			break
+		}
+		if later.UndoneAt != nil {
+			continue
+		}
+		laterEntries, err := s.repo.Entries(ctx, later.ID)
+		if err != nil {
+			return err
+		}
+		for _, entry := range laterEntries {
+			if _, ok := touched[entryKey(entry)]; ok {
+				return ErrUndoBlocked
+			}
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.After == nil {
+			continue
+		}
+		current, err := s.snapshot(ctx, entry)
+		if err != nil {
+			return err
+		}
+		if current != nil && !unchangedSince(entry, *entry.After, *current) {
+			return ErrUndoBlocked
+		}
+	}
+	return s.checkRestoredPaths(ctx, entries)
+}
+
+// checkRestoredPaths returns ErrUndoBlocked when an item the undo writes
+// back would share its path, ignoring case, with another item. Items the
+// undo itself deletes or moves to another path do not count.
+func (s *OperationService) checkRestoredPaths(ctx context.Context, entries []domain.OperationEntry) error {
+	if s.items == nil {
+		return nil
+	}
+	restored := map[string]*domain.Item{}
+	for _, entry := range entries {
+		if entry.Entity == domain.OperationEntityItem {
+			restored[entry.EntityID] = entry.Item
+		}
+	}
+	for _, entry := range entries {
+		if entry.Entity != domain.OperationEntityItem || entry.Item == nil || strings.TrimSpace(entry.Item.Path) == "" {
+			continue
+		}
+		other, err := s.items.GetByPath(ctx, entry.Item.Path)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if other.ID == entry.EntityID {
+			continue
+		}
+		if before, ok := restored[other.ID]; ok && (before == nil || !strings.EqualFold(before.Path, entry.Item.Path)) {
+			continue
+		}
+		return ErrUndoBlocked
+	}
+	return nil
+}
+
+// snapshot reads the row entry refers to as it is now. It returns nil when
+// the service cannot read rows of that kind.
+func (s *OperationService) snapshot(ctx context.Context, entry domain.OperationEntry) (*domain.OperationSnapshot, error) {
+	switch entry.Entity {
+	case domain.OperationEntityItem:
+		if s.items == nil {
+			return nil, nil
+		}
+		item, err := s.items.Get(ctx, entry.EntityID)
+		if errors.Is(err, storage.ErrNotFound) {
+			return &domain.OperationSnapshot{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		item.Frecency = 0
+		return &domain.OperationSnapshot{Item: &item}, nil
+	case domain.OperationEntityGroup:
+		if s.groups == nil {
+			return nil, nil
+		}
+		group, err := s.groups.Get(ctx, entry.EntityID)
+		if errors.Is(err, storage.ErrNotFound) {
+			return &domain.OperationSnapshot{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &domain.OperationSnapshot{Group: &group}, nil
+	}
+	return nil, nil
+}
+
+// unchangedSince reports whether current still matches after, the row as
+// the operation left it. Icons and broken marks are maintained by the app
+// and are not compared. Launches only count when undoing deletes the item:
+// an item that is restored keeps its launch counters.
+func unchangedSince(entry domain.OperationEntry, after, current domain.OperationSnapshot) bool {
+	switch entry.Entity {
+	case domain.OperationEntityItem:
+		if after.Item == nil || current.Item == nil {
+			return after.Item == nil && current.Item == nil
+		}
+		left, now := *after.Item, *current.Item
+		if entry.Item != nil {
+			now.LaunchCount, now.LastUsedAt = left.LaunchCount, left.LastUsedAt
+		}
+		return left.Name == now.Name &&
+			left.Path == now.Path &&
+			left.TargetName == now.TargetName &&
+			left.Type == now.Type &&
+			left.GroupID == now.GroupID &&
+			slices.Equal(left.Tags, now.Tags) &&
+			left.Favorite == now.Favorite &&
+			left.Hidden == now.Hidden &&
+			left.LaunchCount == now.LaunchCount &&
+			sameTime(left.LastUsedAt, now.LastUsedAt)
+	case domain.OperationEntityGroup:
+		if after.Group == nil || current.Group == nil {
+			return after.Group == nil && current.Group == nil
+		}
+		return *after.Group == *current.Group
+	}
+	return true
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+func entryKey(entry domain.OperationEntry) string {
+	return string(entry.Entity) + ":" + entry.EntityID
+}
+
+// itemEntry records an item as it was before an operation; pass nil for
+// items the operation creates.
+func itemEntry(id string, before *domain.Item) domain.OperationEntry {
+	if before != nil {
+		copied := *before
+		copied.Frecency = 0
+		before = &copied
+	}
+	return domain.OperationEntry{Entity: domain.OperationEntityItem, EntityID: id, Item: before}
+}
+
+func groupEntry(id string, before *domain.Group) domain.OperationEntry {
+	return domain.OperationEntry{Entity: domain.OperationEntityGroup, EntityID: id, Group: before}
+}
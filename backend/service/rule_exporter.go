@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/rules"
+	"rungrid/backend/storage"
+)
+
+// exportGroupRules describes the current grouping as a rule file: every
+// manual group, and for each group one rule matching the target names of
+// its items. Items without a target name, such as URLs, folders and
+// documents, get a second rule matching their exact paths, so an item
+// below another exported path is not claimed by that item's group.
+// Smart groups have no rule file form and are left out. Group ids are kept
+// as the file keys, so importing the file on the same machine updates the
+// groups instead of duplicating them.
+func exportGroupRules(ctx context.Context, groups *GroupService, items *ItemService) (rules.File, domain.RuleExportResult, error) {
+	groupList, err := groups.List(ctx)
+	if err != nil {
+		return rules.File{}, domain.RuleExportResult{}, err
+	}
+	itemList, err := items.List(ctx, storage.ItemFilter{})
+	if err != nil {
+		return rules.File{}, domain.RuleExportResult{}, err
+	}
+
+	targets := map[string][]string{}
+	paths := map[string][]string{}
+	seen := map[string]map[string]struct{}{}
+	result := domain.RuleExportResult{}
+	for _, item := range itemList {
+		if item.GroupID == "" {
+			continue
+		}
+		target := strings.TrimSpace(item.TargetName)
+		path := strings.TrimSpace(item.Path)
+		if target == "" && path == "" {
+			result.ItemsSkipped++
+			continue
+		}
+		key := "target\x00" + strings.ToLower(target)
+		if target == "" {
+			key = "path\x00" + strings.ToLower(path)
+		}
+		if seen[item.GroupID] == nil {
+			seen[item.GroupID] = map[string]struct{}{}
+		}
+		result.Items++
+		if _, ok := seen[item.GroupID][key]; ok {
+			continue
+		}
+		seen[item.GroupID][key] = struct{}{}
+		if target != "" {
+			targets[item.GroupID] = append(targets[item.GroupID], target)
+			continue
+		}
+		paths[item.GroupID] = append(paths[item.GroupID], path)
+	}
+
+	// Name and ID break ties so equal orders export the same file each time.
+	sort.SliceStable(groupList, func(i, j int) bool {
+		a, b := groupList[i], groupList[j]
+		if a.Order != b.Order {
+			return a.Order < b.Order
+		}
+		if nameA, nameB := strings.ToLower(a.Name), strings.ToLower(b.Name); nameA != nameB {
+			return nameA < nameB
+		}
+		return a.ID < b.ID
+	})
+	file := rules.File{Version: rules.Version, Groups: []rules.Group{}, Rules: []rules.Rule{}}
+	for _, group := range groupList {
+		if group.Kind == domain.GroupKindSmart {
+			continue
+		}
+		file.Groups = append(file.Groups, rules.Group{
+			ID:       group.ID,
+			Parent:   group.ParentID,
+			Name:     group.Name,
+			Category: group.Category,
+			Order:    group.Order,
+			Color:    group.Color,
+			Icon:     group.Icon,
+		})
+		if names := targets[group.ID]; len(names) > 0 {
+			sortFold(names)
+			file.Rules = append(file.Rules, rules.Rule{
+				GroupID: group.ID,
+				Match:   rules.Condition{TargetName: names},
+			})
+		}
+		if exact := paths[group.ID]; len(exact) > 0 {
+			sortFold(exact)
+			file.Rules = append(file.Rules, rules.Rule{
+				GroupID: group.ID,
+				Match:   rules.Condition{Paths: exact},
+			})
+		}
+	}
+	result.Groups = len(file.Groups)
+	result.Rules = len(file.Rules)
+	return file, result, nil
+}
+
+func sortFold(values []string) {
+	sort.Slice(values, func(i, j int) bool {
+		return strings.ToLower(values[i]) < strings.ToLower(values[j])
+	})
+}
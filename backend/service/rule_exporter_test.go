@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/storage"
+	"rungrid/backend/storage/memory"
+)
+
+func newRuleTestServices() (*ItemService, *GroupService, *RuleSetService) {
+	itemRepo := memory.NewItemRepository()
+	launches := memory.NewLaunchRepository()
+	groupRepo := memory.NewGroupRepository(itemRepo, launches)
+	operations := memory.NewOperationRepository(itemRepo, groupRepo, launches)
+	ruleSets := memory.NewRuleSetRepository()
+	uow := memory.NewUnitOfWork(itemRepo, groupRepo, launches, operations, ruleSets, memory.NewScanIndexRepository())
+	journal := NewOperationService(operations, itemRepo, groupRepo, uow)
+	items := NewItemService(itemRepo, launches, groupRepo, journal, uow)
+	groups := NewGroupService(groupRepo, items)
+	return items, groups, NewRuleSetService(ruleSets, groups, items, "")
+}
+
+// Nested paths of the same type in different groups must come back in their
+// own groups, whichever group the file lists first.
+func TestExportGroupRulesRoundTripsNestedPaths(t *testing.T) {
+	ctx := context.Background()
+	inputs := []struct {
+		item  domain.ItemInput
+		group string
+	}{
+		{domain.ItemInput{Name: "Projects", Path: `C:\Projects`, Type: domain.ItemTypeFolder}, "Outer folders"},
+		{domain.ItemInput{Name: "Sub", Path: `C:\Projects\Sub`, Type: domain.ItemTypeFolder}, "Inner folders"},
+		{domain.ItemInput{Name: "Site", Path: "https://example.com", Type: domain.ItemTypeURL}, "Outer sites"},
+		{domain.ItemInput{Name: "Docs", Path: "https://example.com/docs", Type: domain.ItemTypeURL}, "Inner sites"},
+	}
+	categories := map[string]string{
+		"Outer folders": "folder",
+		"Inner folders": "folder",
+		"Outer sites":   "url",
+		"Inner sites":   "url",
+	}
+
+	items, groups, ruleSets := newRuleTestServices()
+	groupIDs := map[string]string{}
+	for index, name := range []string{"Outer folders", "Outer sites", "Inner folders", "Inner sites"} {
+		group, err := groups.Create(ctx, domain.GroupInput{Name: name, Category: categories[name], Order: index})
+		if err != nil {
+			t.Fatalf("create group %s: %v", name, err)
+		}
+		groupIDs[name] = group.ID
+	}
+	for _, input := range inputs {
+		item := input.item
+		item.GroupID = groupIDs[input.group]
+		if _, err := items.Create(ctx, item); err != nil {
+			t.Fatalf("create item %s: %v", item.Name, err)
+		}
+	}
+
+	file, result, err := exportGroupRules(ctx, groups, items)
+	if err != nil {
+		t.Fatalf("exportGroupRules: %v", err)
+	}
+	if result.Items != len(inputs) || result.ItemsSkipped != 0 {
+		t.Fatalf("result = %+v, want %d items and none skipped", result, len(inputs))
+	}
+	data, err := json.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, groups, ruleSets = newRuleTestServices()
+	for _, input := range inputs {
+		if _, err := items.Create(ctx, input.item); err != nil {
+			t.Fatalf("create item %s: %v", input.item.Name, err)
+		}
+	}
+	if _, err := ruleSets.Import(ctx, "exported.json", data); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	groupList, err := groups.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]string{}
+	for _, group := range groupList {
+		names[group.ID] = group.Name
+	}
+	itemList, err := items.List(ctx, storage.ItemFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{}
+	for _, input := range inputs {
+		want[input.item.Path] = input.group
+	}
+	for _, item := range itemList {
+		if got := names[item.GroupID]; got != want[item.Path] {
+			t.Errorf("%s is in group %q, want %q", item.Path, got, want[item.Path])
+		}
+	}
+}
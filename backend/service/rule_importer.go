@@ -2,183 +2,353 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"rungrid/backend/domain"
+	"rungrid/backend/rules"
 	"rungrid/backend/storage"
 )
 
-type ruleConfig struct {
-	Version string        `json:"version"`
-	Groups  []ruleGroup   `json:"groups"`
-	Rules   []ruleMapping `json:"rules"`
+// rulePlan keeps the items and groups the plan was computed from, so
+// applying it does not reset fields the plan does not touch and the
+// previous state can be journaled.
+type rulePlan struct {
+	domain.RuleImportPlan
+	items  map[string]domain.Item
+	groups map[string]domain.Group
 }
 
-type ruleGroup struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Category string `json:"category"`
-	Order    int    `json:"order"`
-	Color    string `json:"color"`
-	Icon     string `json:"icon"`
-}
-
-type ruleMapping struct {
-	GroupID string    `json:"group_id"`
-	Match   ruleMatch `json:"match"`
-}
-
-type ruleMatch struct {
-	TargetName []string `json:"target_name"`
-}
-
-func ImportGroupRules(ctx context.Context, data []byte, groups *GroupService, items *ItemService) (domain.RuleImportResult, error) {
-	if groups == nil || items == nil {
-		return domain.RuleImportResult{}, fmt.Errorf("service unavailable")
+// planGroupRules works out what importing data would change. known maps rule
+// file group ids to groups created by an earlier import of the same file.
+func planGroupRules(ctx context.Context, data []byte, groups *GroupService, items *ItemService, known map[string]string) (rulePlan, error) {
+	config, err := rules.Parse(data)
+	if err != nil {
+		return rulePlan{}, err
 	}
-
-	var config ruleConfig
-	if err := json.Unmarshal(data, &config); err != nil {
-		return domain.RuleImportResult{}, err
+	ruleSet, err := rules.Compile(config)
+	if err != nil {
+		return rulePlan{}, err
 	}
 
 	existingGroups, err := groups.List(ctx)
 	if err != nil {
-		return domain.RuleImportResult{}, err
+		return rulePlan{}, err
 	}
 
-	groupByKey := map[string]domain.Group{}
+	plan := rulePlan{
+		RuleImportPlan: domain.RuleImportPlan{
+			Groups:    []domain.RuleGroupPlan{},
+			Moves:     []domain.RuleItemMove{},
+			Skipped:   []domain.RuleItemSkip{},
+			Conflicts: []domain.RuleConflict{},
+		},
+		items:  map[string]domain.Item{},
+		groups: map[string]domain.Group{},
+	}
+	targets := map[string]domain.Group{}
 	for _, group := range existingGroups {
-		groupByKey[normalizeRuleKey(group.ID)] = group
+		plan.groups[group.ID] = group
+		targets[rules.Key(group.ID)] = group
 	}
-
-	groupIDMap := map[string]string{}
-	groupCategoryMap := map[string]string{}
-	for key, group := range groupByKey {
-		groupIDMap[key] = group.ID
-		groupCategoryMap[group.ID] = group.Category
+	for key, id := range known {
+		if group, ok := plan.groups[id]; ok {
+			targets[key] = group
+		}
 	}
 
-	result := domain.RuleImportResult{}
+	planned := map[string]struct{}{}
+	parents := map[string]string{}
 	for _, group := range config.Groups {
-		key := normalizeRuleKey(group.ID)
+		key := rules.Key(group.ID)
 		if key == "" {
-			return result, fmt.Errorf("group id is required")
+			return plan, fmt.Errorf("group id is required")
+		}
+		if _, ok := planned[key]; ok {
+			return plan, fmt.Errorf("duplicate group id: %s", group.ID)
 		}
+		planned[key] = struct{}{}
+		parents[key] = rules.Key(group.Parent)
+
 		name := strings.TrimSpace(group.Name)
 		if name == "" {
-			return result, fmt.Errorf("group name is required")
+			return plan, fmt.Errorf("group name is required")
+		}
+		next := domain.Group{
+			Name:  name,
+			Order: group.Order,
+			Color: strings.TrimSpace(group.Color),
+			Icon:  strings.TrimSpace(group.Icon),
 		}
 
-		if existing, ok := groupByKey[key]; ok {
-			updated, err := groups.Update(ctx, domain.Group{
-				ID:       existing.ID,
-				Name:     name,
-				Order:    group.Order,
-				Color:    group.Color,
-				Category: group.Category,
-				Icon:     group.Icon,
-			})
-			if err != nil {
-				return result, err
-			}
-			groupIDMap[key] = updated.ID
-			groupCategoryMap[updated.ID] = updated.Category
-			result.GroupsUpdated++
-			continue
+		existing, ok := targets[key]
+		if ok && strings.TrimSpace(group.Category) == "" {
+			next.Category = existing.Category
+		} else if next.Category, err = normalizeGroupCategory(group.Category); err != nil {
+			return plan, fmt.Errorf("group %s: invalid category %q", group.ID, group.Category)
 		}
 
-		created, err := groups.Create(ctx, domain.GroupInput{
-			Name:     name,
-			Order:    group.Order,
-			Color:    group.Color,
-			Category: group.Category,
-			Icon:     group.Icon,
-		})
-		if err != nil {
-			return result, err
+		change := domain.RuleGroupPlan{Key: key, Action: domain.RuleGroupCreate, Changes: []domain.FieldChange{}}
+		if ok {
+			next.ID = existing.ID
+			next.ParentID = existing.ParentID
+			next.Kind = existing.Kind
+			next.Query = existing.Query
+			change.Changes = diffGroup(existing, next)
+			change.Action = domain.RuleGroupUnchanged
+			if len(change.Changes) > 0 {
+				change.Action = domain.RuleGroupUpdate
+			}
 		}
-		groupIDMap[key] = created.ID
-		groupCategoryMap[created.ID] = created.Category
-		result.GroupsCreated++
+		change.Group = next
+		targets[key] = next
+		plan.Groups = append(plan.Groups, change)
+	}
+	if err := planGroupParents(&plan, targets, parents); err != nil {
+		return plan, err
 	}
 
-	matchToGroup := map[string]string{}
-	for _, rule := range config.Rules {
-		groupKey := normalizeRuleKey(rule.GroupID)
-		if groupKey == "" {
-			continue
-		}
-		groupID, ok := groupIDMap[groupKey]
+	for _, key := range ruleSet.GroupKeys() {
+		target, ok := targets[key]
 		if !ok {
-			return result, fmt.Errorf("unknown group id: %s", rule.GroupID)
+			return plan, fmt.Errorf("unknown group id: %s", key)
+		}
+		if target.Kind == domain.GroupKindSmart {
+			return plan, fmt.Errorf("group %s is a smart group and cannot hold items", key)
 		}
+	}
+
+	itemsList, err := items.List(ctx, storage.ItemFilter{})
+	if err != nil {
+		return plan, err
+	}
 
-		for _, value := range rule.Match.TargetName {
-			targetName := normalizeTargetName(value)
-			if targetName == "" {
+	for _, item := range itemsList {
+		var winner *rules.Candidate
+		candidates := []string{}
+		seen := map[string]struct{}{}
+		for _, candidate := range ruleSet.Match(item) {
+			target := targets[candidate.GroupKey]
+			if target.Category != "" && !matchesItemCategory(item.Type, target.Category) {
+				if winner == nil {
+					plan.Skipped = append(plan.Skipped, domain.RuleItemSkip{
+						ItemID:    item.ID,
+						ItemName:  item.Name,
+						ItemType:  item.Type,
+						GroupKey:  candidate.GroupKey,
+						GroupName: target.Name,
+						Category:  target.Category,
+						Rule:      candidate.Label(),
+					})
+				}
 				continue
 			}
-			if _, exists := matchToGroup[targetName]; exists {
-				continue
+			if winner == nil {
+				winner = &candidate
+			}
+			if _, ok := seen[candidate.GroupKey]; !ok {
+				seen[candidate.GroupKey] = struct{}{}
+				candidates = append(candidates, candidate.GroupKey)
 			}
-			matchToGroup[targetName] = groupID
 		}
-	}
+		if winner == nil {
+			continue
+		}
 
-	if len(matchToGroup) == 0 {
-		return result, nil
+		if len(candidates) > 1 {
+			plan.Conflicts = append(plan.Conflicts, domain.RuleConflict{
+				ItemID:     item.ID,
+				ItemName:   item.Name,
+				GroupKey:   winner.GroupKey,
+				Candidates: candidates,
+			})
+		}
+		target := targets[winner.GroupKey]
+		if target.ID != "" && item.GroupID == target.ID {
+			continue
+		}
+		plan.items[item.ID] = item
+		plan.Moves = append(plan.Moves, domain.RuleItemMove{
+			ItemID:        item.ID,
+			ItemName:      item.Name,
+			FromGroupID:   item.GroupID,
+			FromGroupName: plan.groups[item.GroupID].Name,
+			ToGroupKey:    winner.GroupKey,
+			ToGroupID:     target.ID,
+			ToGroupName:   target.Name,
+			Rule:          winner.Label(),
+		})
 	}
 
-	itemsList, err := items.List(ctx, storage.ItemFilter{})
-	if err != nil {
-		return result, err
+	return plan, nil
+}
+
+// planGroupParents nests the planned groups as parents says, checks that
+// each parent has the category of its subgroups and that no group ends up
+// inside itself, and orders plan.Groups so parents are applied first.
+func planGroupParents(plan *rulePlan, targets map[string]domain.Group, parents map[string]string) error {
+	// Groups are identified by ID, or by file key until they are created.
+	node := func(key string) string {
+		if id := targets[key].ID; id != "" {
+			return id
+		}
+		return "key:" + key
+	}
+	groups := map[string]domain.Group{}
+	up := map[string]string{}
+	for id, group := range plan.groups {
+		groups[id] = group
+		up[id] = group.ParentID
+	}
+	for _, change := range plan.Groups {
+		groups[node(change.Key)] = change.Group
 	}
 
-	for _, item := range itemsList {
-		targetName := normalizeTargetName(item.TargetName)
-		if targetName == "" {
+	for i := range plan.Groups {
+		change := &plan.Groups[i]
+		parentKey := parents[change.Key]
+		if parentKey == "" {
 			continue
 		}
-		groupID, ok := matchToGroup[targetName]
+		parent, ok := targets[parentKey]
 		if !ok {
-			continue
+			return fmt.Errorf("group %s: unknown parent %s", change.Key, parentKey)
 		}
-		if item.GroupID == groupID {
-			continue
+		if parentKey == change.Key {
+			return fmt.Errorf("group %s: a group cannot be its own parent", change.Key)
 		}
-		if category, ok := groupCategoryMap[groupID]; ok && category != "" {
-			if !matchesItemCategory(item.Type, category) {
-				continue
+		change.ParentKey = parentKey
+		change.Group.ParentID = parent.ID
+		up[node(change.Key)] = node(parentKey)
+	}
+
+	depth := map[string]int{}
+	for i := range plan.Groups {
+		change := &plan.Groups[i]
+		self := node(change.Key)
+		parentNode := up[self]
+		if parentNode != "" && groups[parentNode].Category != change.Group.Category {
+			return fmt.Errorf("group %s: parent %s has category %q", change.Key, groups[parentNode].Name, groups[parentNode].Category)
+		}
+		if parentNode != "" && groups[parentNode].Kind == domain.GroupKindSmart {
+			return fmt.Errorf("group %s: parent %s is a smart group", change.Key, groups[parentNode].Name)
+		}
+
+		seen := map[string]struct{}{}
+		for current := parentNode; current != ""; current = up[current] {
+			if current == self {
+				return fmt.Errorf("group %s: nested in itself", change.Key)
+			}
+			if _, ok := seen[current]; ok {
+				break
+			}
+			seen[current] = struct{}{}
+		}
+		depth[change.Key] = len(seen)
+
+		if previous, ok := plan.groups[change.Group.ID]; ok && previous.ParentID != parentNode {
+			change.Changes = append(change.Changes, domain.FieldChange{
+				Field: "parent",
+				From:  plan.groups[previous.ParentID].Name,
+				To:    groups[parentNode].Name,
+			})
+			change.Action = domain.RuleGroupUpdate
+		}
+	}
+
+	sort.SliceStable(plan.Groups, func(i, j int) bool {
+		return depth[plan.Groups[i].Key] < depth[plan.Groups[j].Key]
+	})
+	return nil
+}
+
+// applyRulePlan writes the plan, journals it and returns the group each
+// rule file group id ended up as. groups and items should be bound to one
+// transaction, so a failed import leaves nothing behind.
+func applyRulePlan(ctx context.Context, plan rulePlan, groups *GroupService, items *ItemService) (domain.RuleImportResult, map[string]string, error) {
+	result := domain.RuleImportResult{Conflicts: plan.Conflicts}
+	entries := []domain.OperationEntry{}
+	groupIDs := map[string]string{}
+	for _, change := range plan.Groups {
+		parentID := change.Group.ParentID
+		if parentID == "" && change.ParentKey != "" {
+			parentID = groupIDs[change.ParentKey]
+		}
+		switch change.Action {
+		case domain.RuleGroupCreate:
+			created, err := groups.Create(ctx, domain.GroupInput{
+				ParentID: parentID,
+				Name:     change.Group.Name,
+				Order:    change.Group.Order,
+				Color:    change.Group.Color,
+				Category: change.Group.Category,
+				Icon:     change.Group.Icon,
+			})
+			if err != nil {
+				return result, groupIDs, err
 			}
+			groupIDs[change.Key] = created.ID
+			entries = append(entries, groupEntry(created.ID, nil))
+			result.GroupsCreated++
+		case domain.RuleGroupUpdate:
+			change.Group.ParentID = parentID
+			updated, err := groups.update(ctx, change.Group, false)
+			if err != nil {
+				return result, groupIDs, err
+			}
+			groupIDs[change.Key] = updated.ID
+			before := plan.groups[updated.ID]
+			entries = append(entries, groupEntry(updated.ID, &before))
+			result.GroupsUpdated++
+		default:
+			groupIDs[change.Key] = change.Group.ID
 		}
+	}
 
+	for _, move := range plan.Moves {
+		groupID := move.ToGroupID
+		if groupID == "" {
+			groupID = groupIDs[move.ToGroupKey]
+		}
+		item := plan.items[move.ItemID]
 		if _, err := items.Update(ctx, domain.ItemUpdate{
 			ID:       item.ID,
 			GroupID:  groupID,
 			Favorite: item.Favorite,
 			Hidden:   item.Hidden,
 		}); err != nil {
-			return result, err
+			return result, groupIDs, err
 		}
+		entries = append(entries, itemEntry(item.ID, &item))
 		result.ItemsUpdated++
 	}
 
-	return result, nil
-}
-
-func normalizeRuleKey(value string) string {
-	return strings.ToLower(strings.TrimSpace(value))
+	summary := fmt.Sprintf("%d groups created, %d updated, %d items moved", result.GroupsCreated, result.GroupsUpdated, result.ItemsUpdated)
+	if _, err := items.journal.Record(ctx, domain.OperationRuleImport, summary, entries); err != nil {
+		return result, groupIDs, err
+	}
+	return result, groupIDs, nil
 }
 
-func normalizeTargetName(value string) string {
-	return strings.ToLower(strings.TrimSpace(value))
+func diffGroup(current, next domain.Group) []domain.FieldChange {
+	changes := []domain.FieldChange{}
+	add := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, domain.FieldChange{Field: field, From: from, To: to})
+		}
+	}
+	add("name", current.Name, next.Name)
+	add("category", current.Category, next.Category)
+	add("order", strconv.Itoa(current.Order), strconv.Itoa(next.Order))
+	add("color", current.Color, next.Color)
+	add("icon", current.Icon, next.Icon)
+	return changes
 }
 
 func matchesItemCategory(itemType domain.ItemType, groupCategory string) bool {
-	category := normalizeRuleKey(groupCategory)
+	category := strings.ToLower(strings.TrimSpace(groupCategory))
 	switch itemType {
 	case domain.ItemTypeApp:
 		return category == "app"
@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/rules"
+	"rungrid/backend/storage"
+)
+
+// ErrRuleSetAmbiguous is returned when a rule set imported before sources
+// were recorded has the name of the file being imported but different
+// data, so it is unclear whether the file replaces it. Deleting the old set
+// resolves it.
+var ErrRuleSetAmbiguous = errors.New("a rule set with this name came from an unknown file")
+
+// RuleSetService imports rule files, keeps them in the database and in the
+// rules folder of the data root, and applies the enabled ones to items
+// found by later scans.
+type RuleSetService struct {
+	repo   storage.RuleSetRepository
+	groups *GroupService
+	items  *ItemService
+	dir    string
+}
+
+func NewRuleSetService(repo storage.RuleSetRepository, groups *GroupService, items *ItemService, dir string) *RuleSetService {
+	return &RuleSetService{repo: repo, groups: groups, items: items, dir: dir}
+}
+
+// Preview reports what Import would change without writing anything.
+func (s *RuleSetService) Preview(ctx context.Context, source string, data []byte) (domain.RuleImportPlan, error) {
+	set, err := s.lookup(ctx, source, data)
+	if err != nil {
+		return domain.RuleImportPlan{}, err
+	}
+	plan, err := planGroupRules(ctx, data, s.groups, s.items, set.GroupIDs)
+	if err != nil {
+		return domain.RuleImportPlan{}, err
+	}
+	return plan.RuleImportPlan, nil
+}
+
+// Import applies the rule file read from source and keeps it as a rule set
+// named after the file. Importing the same file again replaces the stored
+// set and reuses the groups created for it; a file from another folder
+// with the same name gets a set of its own. The groups, moved items, rule
+// set and journal entry are written in one transaction.
+func (s *RuleSetService) Import(ctx context.Context, source string, data []byte) (domain.RuleImportResult, error) {
+	set, err := s.lookup(ctx, source, data)
+	if err != nil {
+		return domain.RuleImportResult{}, err
+	}
+	plan, err := planGroupRules(ctx, data, s.groups, s.items, set.GroupIDs)
+	if err != nil {
+		return domain.RuleImportResult{}, err
+	}
+
+	var result domain.RuleImportResult
+	err = s.items.withinTx(ctx, func(tx storage.Tx) error {
+		items := s.items.bind(tx)
+		rules := s.bind(tx, items)
+
+		applied, groupIDs, err := applyRulePlan(ctx, plan, rules.groups, items)
+		if err != nil {
+			return err
+		}
+		result = applied
+
+		set, err := rules.lookup(ctx, source, data)
+		if err != nil {
+			return err
+		}
+		if set.ID == "" {
+			set.ID = uuid.NewString()
+		}
+		set.Enabled = true
+		set.Data = string(data)
+		set.GroupIDs = groupIDs
+		set.ImportedAt = time.Now()
+		if err := rules.repo.Save(ctx, set); err != nil {
+			return err
+		}
+		// Written last, so a failure before it leaves no file behind.
+		return s.writeFile(set.Name, data)
+	})
+	if err != nil {
+		return domain.RuleImportResult{}, err
+	}
+	return result, nil
+}
+
+// Export writes the current grouping to path as a rule file that Import
+// can reproduce on another machine.
+func (s *RuleSetService) Export(ctx context.Context, path string) (domain.RuleExportResult, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return domain.RuleExportResult{}, storage.ErrInvalidInput
+	}
+	file, result, err := exportGroupRules(ctx, s.groups, s.items)
+	if err != nil {
+		return domain.RuleExportResult{}, err
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return domain.RuleExportResult{}, err
+	}
+	if err := writeFileAtomic(path, append(data, '\n')); err != nil {
+		return domain.RuleExportResult{}, err
+	}
+	return result, nil
+}
+
+func (s *RuleSetService) List(ctx context.Context) ([]domain.RuleSet, error) {
+	sets, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for index := range sets {
+		if config, err := rules.Parse([]byte(sets[index].Data)); err == nil {
+			sets[index].Groups = len(config.Groups)
+			sets[index].Rules = len(config.Rules)
+		}
+	}
+	return sets, nil
+}
+
+func (s *RuleSetService) SetEnabled(ctx context.Context, id string, enabled bool) (domain.RuleSet, error) {
+	set, err := s.repo.Get(ctx, strings.TrimSpace(id))
+	if err != nil {
+		return domain.RuleSet{}, err
+	}
+	set.Enabled = enabled
+	if err := s.repo.Save(ctx, set); err != nil {
+		return domain.RuleSet{}, err
+	}
+	return set, nil
+}
+
+// Delete forgets a rule set and removes its file. Groups it created and
+// items it moved are left as they are.
+func (s *RuleSetService) Delete(ctx context.Context, id string) error {
+	set, err := s.repo.Get(ctx, strings.TrimSpace(id))
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, set.ID); err != nil {
+		return err
+	}
+	if s.dir == "" {
+		return nil
+	}
+	if err := os.Remove(s.filePath(set.Name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ApplyToItems moves items into the group chosen by the enabled rule sets
+// and returns how many moved. Only existing groups are used; a rule whose
+// group was deleted is ignored. Across rule sets the highest priority
+// wins, and ties go to the set imported first.
+func (s *RuleSetService) ApplyToItems(ctx context.Context, items []domain.Item) (int, error) {
+	if s == nil || len(items) == 0 {
+		return 0, nil
+	}
+	sets, err := s.repo.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	groupList, err := s.groups.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	groups := map[string]domain.Group{}
+	for _, group := range groupList {
+		groups[group.ID] = group
+	}
+
+	type compiledSet struct {
+		rules    *rules.Set
+		groupIDs map[string]string
+	}
+	compiled := []compiledSet{}
+	for _, set := range sets {
+		if !set.Enabled {
+			continue
+		}
+		config, err := rules.Parse([]byte(set.Data))
+		if err != nil {
+			continue
+		}
+		ruleSet, err := rules.Compile(config)
+		if err != nil {
+			continue
+		}
+		compiled = append(compiled, compiledSet{rules: ruleSet, groupIDs: set.GroupIDs})
+	}
+	if len(compiled) == 0 {
+		return 0, nil
+	}
+
+	moved := 0
+	for _, item := range items {
+		groupID := ""
+		priority := 0
+		for _, set := range compiled {
+			for _, candidate := range set.rules.Match(item) {
+				id, ok := set.groupIDs[candidate.GroupKey]
+				if !ok {
+					id = candidate.GroupKey
+				}
+				group, ok := groups[id]
+				if !ok || group.Kind == domain.GroupKindSmart || (group.Category != "" && !matchesItemCategory(item.Type, group.Category)) {
+					continue
+				}
+				if groupID == "" || candidate.Priority > priority {
+					groupID = group.ID
+					priority = candidate.Priority
+				}
+				break
+			}
+		}
+		if groupID == "" || groupID == item.GroupID {
+			continue
+		}
+		if _, err := s.items.Update(ctx, domain.ItemUpdate{
+			ID:       item.ID,
+			GroupID:  groupID,
+			Favorite: item.Favorite,
+			Hidden:   item.Hidden,
+		}); err != nil {
+			return moved, err
+		}
+		moved++
+	}
+	return moved, nil
+}
+
+// lookup returns the rule set imported from source before, or a new one
+// without an ID. A new set is named after the file, with a number added
+// when another set already has that name. A set imported before sources
+// were kept is adopted only when it holds exactly the data being imported;
+// otherwise lookup cannot tell whether source is the file it came from and
+// returns ErrRuleSetAmbiguous.
+func (s *RuleSetService) lookup(ctx context.Context, source string, data []byte) (domain.RuleSet, error) {
+	source = strings.TrimSpace(source)
+	base := ruleSetName(source)
+	if base == "" {
+		return domain.RuleSet{}, storage.ErrInvalidInput
+	}
+	source = filepath.Clean(source)
+
+	sets, err := s.repo.List(ctx)
+	if err != nil {
+		return domain.RuleSet{}, err
+	}
+	for _, set := range sets {
+		if set.Source != "" && sameRuleSource(set.Source, source) {
+			return set, nil
+		}
+	}
+
+	legacy := []domain.RuleSet{}
+	matching := []domain.RuleSet{}
+	for _, set := range sets {
+		if set.Source != "" || !strings.EqualFold(set.Name, base) {
+			continue
+		}
+		legacy = append(legacy, set)
+		if set.Data == string(data) {
+			matching = append(matching, set)
+		}
+	}
+	if len(matching) == 1 {
+		set := matching[0]
+		set.Source = source
+		return set, nil
+	}
+	if len(legacy) > 0 {
+		return domain.RuleSet{}, fmt.Errorf("%w: %s", ErrRuleSetAmbiguous, legacy[0].Name)
+	}
+
+	taken := map[string]struct{}{}
+	for _, set := range sets {
+		taken[strings.ToLower(set.Name)] = struct{}{}
+	}
+	name := base
+	for suffix := 2; ; suffix++ {
+		if _, ok := taken[strings.ToLower(name)]; !ok {
+			return domain.RuleSet{Name: name, Source: source}, nil
+		}
+		name = fmt.Sprintf("%s (%d)", base, suffix)
+	}
+}
+
+func (s *RuleSetService) filePath(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+func (s *RuleSetService) writeFile(name string, data []byte) error {
+	if s.dir == "" {
+		return nil
+	}
+	if err := writeFileAtomic(s.filePath(name), data); err != nil {
+		return fmt.Errorf("save rule file: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data next to path and renames it into place, so
+// readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(dir, ".rules-*.json")
+	if err != nil {
+		return err
+	}
+	tempName := temp.Name()
+	if _, err := temp.Write(data); err != nil {
+		_ = temp.Close()
+		_ = os.Remove(tempName)
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		_ = os.Remove(tempName)
+		return err
+	}
+	if err := os.Rename(tempName, path); err != nil {
+		_ = os.Remove(tempName)
+		return err
+	}
+	return nil
+}
+
+// ruleSetName derives a file-safe rule set name from a file name or path.
+func ruleSetName(value string) string {
+	value = strings.TrimSpace(value)
+	if index := strings.LastIndexAny(value, `/\`); index >= 0 {
+		value = value[index+1:]
+	}
+	value = strings.TrimSuffix(value, filepath.Ext(value))
+	return strings.Trim(strings.TrimSpace(value), ".")
+}
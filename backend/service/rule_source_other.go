@@ -0,0 +1,9 @@
+//go:build !windows
+
+package service
+
+// sameRuleSource reports whether two rule file paths name the same file.
+// Paths that differ only in case are different files here.
+func sameRuleSource(a, b string) bool {
+	return a == b
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package service
+
+import "strings"
+
+// sameRuleSource reports whether two rule file paths name the same file.
+// Windows paths ignore case.
+func sameRuleSource(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/scanner"
+)
+
+// scanHistoryLimit bounds how many finished scans are kept in memory.
+const scanHistoryLimit = 20
+
+// ScanJobService runs scans as jobs: one at a time, each with an ID that
+// can be cancelled, and a short history of finished jobs. Every job emits
+// scan:finished with the final job when it ends.
+type ScanJobService struct {
+	scanner *ScannerService
+
+	mu      sync.Mutex
+	current *scanJob
+	history []domain.ScanJob
+}
+
+type scanJob struct {
+	job    domain.ScanJob
+	roots  string
+	err    error
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewScanJobService(scanner *ScannerService) *ScanJobService {
+	return &ScanJobService{scanner: scanner}
+}
+
+// Run scans roots and waits for the job to end. A manual scan requested
+// while another scan of the same roots runs joins that job instead of
+// starting a second one. Any other scan waits for the running job and runs
+// afterwards: a watcher scan because the running job may already have
+// passed the changed paths, a manual scan of other roots because the
+// running job does not cover them.
+//
+// A cancelled job is returned with a nil error; a failed one with its
+// error.
+func (s *ScanJobService) Run(ctx context.Context, source domain.ScanSource, roots []string, changed []string) (domain.ScanJob, error) {
+	key := rootsKey(roots)
+	for {
+		s.mu.Lock()
+		running := s.current
+		if running == nil {
+			break
+		}
+		s.mu.Unlock()
+
+		if source != domain.ScanSourceWatcher && running.roots == key {
+			<-running.done
+			return running.job, running.err
+		}
+		select {
+		case <-running.done:
+		case <-ctx.Done():
+			return domain.ScanJob{}, ctx.Err()
+		}
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	running := &scanJob{
+		job: domain.ScanJob{
+			ID:        uuid.NewString(),
+			Source:    source,
+			Roots:     nonNilRoots(append([]string(nil), roots...)),
+			Status:    domain.ScanJobRunning,
+			StartedAt: time.Now(),
+		},
+		roots:  key,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	s.current = running
+	s.mu.Unlock()
+
+	result, err := s.scanner.ScanChanged(jobCtx, roots, changed)
+	cancelled := err != nil && jobCtx.Err() != nil
+	cancel()
+
+	finishedAt := time.Now()
+	job := running.job
+	job.Result = result
+	job.FinishedAt = &finishedAt
+	switch {
+	case err == nil:
+		job.Status = domain.ScanJobCompleted
+	case cancelled:
+		job.Status = domain.ScanJobCancelled
+		err = nil
+	default:
+		job.Status = domain.ScanJobFailed
+		job.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	running.job = job
+	running.err = err
+	s.current = nil
+	s.history = append([]domain.ScanJob{job}, s.history...)
+	if len(s.history) > scanHistoryLimit {
+		s.history = s.history[:scanHistoryLimit]
+	}
+	close(running.done)
+	s.mu.Unlock()
+
+	runtime.EventsEmit(ctx, "scan:finished", job)
+	return job, err
+}
+
+// rootsKey identifies the directories a scan covers. No roots means the
+// default roots, as in ScannerService.
+func rootsKey(roots []string) string {
+	normalized := scanner.NormalizeRoots(roots)
+	if len(normalized) == 0 {
+		normalized = scanner.NormalizeRoots(scanner.DefaultRoots())
+	}
+	keys := make([]string, len(normalized))
+	for index, root := range normalized {
+		keys[index] = strings.ToLower(root)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "\x00")
+}
+
+// Cancel stops the running job. An empty id matches any job. It reports
+// whether a job was cancelled.
+func (s *ScanJobService) Cancel(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == nil || (id != "" && id != s.current.job.ID) {
+		return false
+	}
+	s.current.cancel()
+	return true
+}
+
+// Current returns the running job, if any.
+func (s *ScanJobService) Current() (domain.ScanJob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == nil {
+		return domain.ScanJob{}, false
+	}
+	return s.current.job, true
+}
+
+// History returns finished jobs, newest first.
+func (s *ScanJobService) History() []domain.ScanJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]domain.ScanJob{}, s.history...)
+}
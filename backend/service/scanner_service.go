@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	"rungrid/backend/domain"
 	"rungrid/backend/scanner"
@@ -16,24 +20,82 @@ type ScannerService struct {
 	scanner scanner.Scanner
 	items   *ItemService
 	icons   *IconService
+	rules   *RuleSetService
+	index   storage.ScanIndexRepository
+
+	// scanning serializes scans; the scanner keeps per-scan state and the
+	// watcher may start a scan while one from the UI is running.
+	scanning sync.Mutex
+
+	mu          sync.RWMutex
+	policy      domain.BrokenItemPolicy
+	concurrency int
+}
+
+// NewScannerService wires a scanner. rules may be nil, in which case scanned
+// items are left ungrouped, and index may be nil to process every file on
+// every scan.
+func NewScannerService(scanner scanner.Scanner, items *ItemService, icons *IconService, rules *RuleSetService, index storage.ScanIndexRepository) *ScannerService {
+	return &ScannerService{scanner: scanner, items: items, icons: icons, rules: rules, index: index, policy: domain.BrokenItemKeep}
+}
+
+// SetBrokenItemPolicy decides what later scans do with items whose file or
+// shortcut target has disappeared.
+func (s *ScannerService) SetBrokenItemPolicy(policy domain.BrokenItemPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = policy
+}
+
+func (s *ScannerService) brokenItemPolicy() domain.BrokenItemPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.policy
+}
+
+// SetConcurrency bounds how many roots later scans walk and how many files
+// they resolve at the same time; zero lets the scanner choose.
+func (s *ScannerService) SetConcurrency(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.concurrency = limit
 }
 
-func NewScannerService(scanner scanner.Scanner, items *ItemService, icons *IconService) *ScannerService {
-	return &ScannerService{scanner: scanner, items: items, icons: icons}
+func (s *ScannerService) concurrencyLimit() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.concurrency
 }
 
 func (s *ScannerService) Scan(ctx context.Context) (domain.ScanResult, error) {
-	return s.scan(ctx, nil)
+	return s.scan(ctx, nil, nil)
 }
 
 func (s *ScannerService) ScanWithRoots(ctx context.Context, roots []string) (domain.ScanResult, error) {
-	return s.scan(ctx, roots)
+	return s.scan(ctx, roots, nil)
 }
 
-func (s *ScannerService) scan(ctx context.Context, roots []string) (domain.ScanResult, error) {
+// ScanChanged rescans roots after the files or directories in changed were
+// modified. The scan index still skips everything else; changed paths are
+// processed again even if their size and time look the same, since a
+// rewritten shortcut can keep both. Only the items of files that changed
+// or disappeared are checked for broken targets; Scan checks them all.
+func (s *ScannerService) ScanChanged(ctx context.Context, roots []string, changed []string) (domain.ScanResult, error) {
+	return s.scan(ctx, roots, changed)
+}
+
+// scan walks the roots and syncs the items with what it found. Files whose
+// size and modification time match the scan index, and whose item still
+// exists, are not touched unless they are in changed. Afterwards items are
+// checked for a missing file or shortcut target and the broken item policy
+// is applied: every item after a full scan, and after a scan for changed
+// paths only the items whose files the scan saw change or disappear.
+func (s *ScannerService) scan(ctx context.Context, roots []string, changed []string) (domain.ScanResult, error) {
 	if s.scanner == nil {
 		return domain.ScanResult{}, scanner.ErrUnsupported
 	}
+	s.scanning.Lock()
+	defer s.scanning.Unlock()
 
 	if setter, ok := s.scanner.(scanner.RootSetter); ok {
 		if roots == nil {
@@ -42,6 +104,9 @@ func (s *ScannerService) scan(ctx context.Context, roots []string) (domain.ScanR
 			setter.SetRoots(roots)
 		}
 	}
+	if setter, ok := s.scanner.(scanner.ConcurrencySetter); ok {
+		setter.SetConcurrency(s.concurrencyLimit())
+	}
 	if reporter, ok := s.scanner.(scanner.ProgressReporter); ok {
 		reporter.SetProgressReporter(func(progress scanner.ScanProgress) {
 			runtime.EventsEmit(ctx, "scan:progress", progress)
@@ -49,57 +114,144 @@ func (s *ScannerService) scan(ctx context.Context, roots []string) (domain.ScanR
 		defer reporter.SetProgressReporter(nil)
 	}
 
+	indexer, _ := s.scanner.(scanner.Indexer)
+	if s.index == nil {
+		indexer = nil
+	}
+	previous := map[string]domain.ScanIndexEntry{}
+	// reusable is previous without the changed paths; previous itself is
+	// still needed to find index entries of deleted files.
+	reusable := map[string]domain.ScanIndexEntry{}
+	if indexer != nil {
+		entries, err := s.index.List(ctx)
+		if err != nil {
+			return domain.ScanResult{}, err
+		}
+		for _, entry := range entries {
+			previous[entry.Path] = entry
+			if !changedPath(entry.Path, changed) {
+				reusable[entry.Path] = entry
+			}
+		}
+		indexer.SetIndex(reusable)
+	}
+
 	inputs, err := s.scanner.Scan(ctx)
 	if err != nil {
 		return domain.ScanResult{}, err
 	}
 
-	result := domain.ScanResult{Total: len(inputs)}
-	for _, input := range inputs {
-		if input.Path == "" || input.Name == "" {
-			result.Skipped++
-			continue
+	current := map[string]domain.ScanIndexEntry{}
+	removed := []string{}
+	if indexer != nil {
+		for _, entry := range indexer.Index() {
+			current[entry.Path] = entry
 		}
+		removed = removedPaths(previous, current, scannedRoots(roots))
+	}
 
-		existing, err := s.items.GetByPath(ctx, input.Path)
-		if err == nil {
-			needsUpdate := false
-			update := domain.ItemUpdate{
-				ID:       existing.ID,
-				Favorite: existing.Favorite,
-				Hidden:   existing.Hidden,
-			}
-			if existing.Type != input.Type && input.Type.IsValid() {
-				update.Type = input.Type
-				needsUpdate = true
-			}
-			if strings.TrimSpace(input.TargetName) != "" && !strings.EqualFold(existing.TargetName, input.TargetName) {
-				update.TargetName = strings.TrimSpace(input.TargetName)
-				needsUpdate = true
+	// checkPaths stays nil, checking every item, unless the scan was for
+	// changed paths and the index tells which files it touched.
+	var checkPaths map[string]struct{}
+	if len(changed) > 0 && indexer != nil {
+		checkPaths = map[string]struct{}{}
+		for path := range current {
+			if !unchangedFile(reusable, current, path) {
+				checkPaths[strings.ToLower(path)] = struct{}{}
 			}
+		}
+		for _, path := range removed {
+			checkPaths[strings.ToLower(path)] = struct{}{}
+		}
+	}
+
+	// Unchanged files are only skipped while their item exists; one query
+	// tells which paths have items.
+	itemList, err := s.items.List(ctx, storage.ItemFilter{})
+	if err != nil {
+		return domain.ScanResult{}, err
+	}
+	existingPaths := make(map[string]struct{}, len(itemList))
+	for _, item := range itemList {
+		existingPaths[strings.ToLower(item.Path)] = struct{}{}
+	}
 
-			if needsUpdate {
-				_, updateErr := s.items.Update(ctx, update)
-				if updateErr != nil && !errors.Is(updateErr, storage.ErrInvalidInput) {
-					return result, updateErr
-				}
+	// Everything the scan writes, its journal entry included, is one
+	// transaction, so a failed or cancelled scan leaves the items as they
+	// were.
+	var result domain.ScanResult
+	err = s.items.withinTx(ctx, func(tx storage.Tx) error {
+		items := s.items.bind(tx)
+		result = domain.ScanResult{Total: len(inputs)}
+		entries := []domain.OperationEntry{}
+		// Rule sets only place new items and ungrouped ones, so manual
+		// group choices survive rescans.
+		ungrouped := []domain.Item{}
+
+		pending := make([]domain.ItemInput, 0, len(inputs))
+		for _, input := range inputs {
+			if input.Path == "" || input.Name == "" {
+				result.Skipped++
+				continue
 			}
-			result.Skipped++
-			continue
-		}
-		if err != nil && !errors.Is(err, storage.ErrNotFound) {
-			return result, err
+			if _, found := existingPaths[strings.ToLower(input.Path)]; found && unchangedFile(reusable, current, input.Path) {
+				result.Skipped++
+				continue
+			}
+			pending = append(pending, input)
 		}
 
-		if _, err := s.items.Create(ctx, input); err != nil {
-			if errors.Is(err, storage.ErrInvalidInput) {
+		upserts, err := items.UpsertByPath(ctx, pending)
+		if err != nil {
+			return err
+		}
+		for _, upsert := range upserts {
+			switch upsert.Outcome {
+			case domain.UpsertInserted:
+				entries = append(entries, itemEntry(upsert.Item.ID, nil))
+				result.Inserted++
+			case domain.UpsertUpdated:
+				entries = append(entries, itemEntry(upsert.Item.ID, upsert.Before))
+				result.Updated++
+			default:
 				result.Skipped++
 				continue
 			}
-			return result, err
+			if upsert.Item.GroupID == "" {
+				ungrouped = append(ungrouped, upsert.Item)
+			}
+		}
+
+		if indexer != nil {
+			index := txRepo(tx.ScanIndex, s.index)
+			if err := index.Delete(ctx, removed); err != nil {
+				return err
+			}
+			if err := index.Save(ctx, indexer.Index()); err != nil {
+				return err
+			}
+		}
+
+		grouped, err := s.rules.bind(tx, items).ApplyToItems(ctx, ungrouped)
+		if err != nil {
+			return err
 		}
+		result.Grouped = grouped
 
-		result.Inserted++
+		broken, deleted, brokenEntries, err := items.checkBroken(ctx, s.brokenItemPolicy(), checkPaths)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, brokenEntries...)
+		result.Broken = broken
+		result.Removed = deleted
+
+		summary := fmt.Sprintf("inserted %d, updated %d, removed %d items", result.Inserted, result.Updated, result.Removed)
+		_, err = items.journal.Record(ctx, domain.OperationScan, summary, entries)
+		return err
+	})
+	if err != nil {
+		return domain.ScanResult{}, err
 	}
 
 	if s.icons != nil {
@@ -110,3 +262,72 @@ func (s *ScannerService) scan(ctx context.Context, roots []string) (domain.ScanR
 
 	return result, nil
 }
+
+// unchangedFile reports whether the file at path is the same as in the
+// previous scan, including its resolved shortcut target.
+func unchangedFile(previous, current map[string]domain.ScanIndexEntry, path string) bool {
+	before, ok := previous[path]
+	if !ok {
+		return false
+	}
+	now, ok := current[path]
+	if !ok {
+		return false
+	}
+	return before.SameFile(now) && before.Target == now.Target && before.Arguments == now.Arguments
+}
+
+// changedPath reports whether path is one of changed or lies below one of
+// them, which covers files inside a directory that was moved in or out.
+func changedPath(path string, changed []string) bool {
+	for _, candidate := range changed {
+		if strings.EqualFold(path, candidate) || withinRoot(path, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// scannedRoots returns the roots the scanner walked that exist. Entries of
+// roots that are missing, such as an unplugged drive, are kept rather than
+// treated as removed.
+func scannedRoots(roots []string) []string {
+	normalized := scanner.NormalizeRoots(roots)
+	if len(normalized) == 0 {
+		normalized = scanner.NormalizeRoots(scanner.DefaultRoots())
+	}
+	existing := make([]string, 0, len(normalized))
+	for _, root := range normalized {
+		if info, err := os.Stat(root); err == nil && info.IsDir() {
+			existing = append(existing, root)
+		}
+	}
+	return existing
+}
+
+// removedPaths lists index entries under roots that the latest scan did not
+// see again.
+func removedPaths(previous, current map[string]domain.ScanIndexEntry, roots []string) []string {
+	removed := []string{}
+	for path := range previous {
+		if _, ok := current[path]; ok {
+			continue
+		}
+		for _, root := range roots {
+			if withinRoot(path, root) {
+				removed = append(removed, path)
+				break
+			}
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+func withinRoot(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/scanner"
+	"rungrid/backend/storage"
+)
+
+const (
+	settingHotkeys              = "hotkeys"
+	settingScanRoots            = "scan_roots"
+	settingPreferences          = "preferences"
+	settingFrecencyHalfLifeDays = "frecency_half_life_days"
+	settingBrokenItemPolicy     = "broken_item_policy"
+	settingWatchScanRoots       = "watch_scan_roots"
+	settingScanConcurrency      = "scan_concurrency"
+)
+
+const (
+	minFrecencyHalfLifeDays     = 0.5
+	maxFrecencyHalfLifeDays     = 365
+	defaultFrecencyHalfLifeDays = 14
+	maxScanConcurrency          = 16
+)
+
+// SettingsService stores typed settings as JSON values keyed by name.
+// Missing or unreadable values fall back to defaults.
+type SettingsService struct {
+	repo storage.SettingsRepository
+}
+
+func NewSettingsService(repo storage.SettingsRepository) *SettingsService {
+	return &SettingsService{repo: repo}
+}
+
+func DefaultHotkeys() []domain.HotkeyBinding {
+	return []domain.HotkeyBinding{{ID: "toggle-app", Keys: "F1"}}
+}
+
+func DefaultPreferences() domain.Preferences {
+	return domain.Preferences{
+		FocusSearchOnShow: true,
+		PanelPositionMode: domain.PanelPositionCenter,
+		LaunchMode:        domain.LaunchModeSingle,
+		PanelCloseMode:    domain.PanelCloseLaunch,
+	}
+}
+
+func (s *SettingsService) Settings(ctx context.Context) (domain.Settings, error) {
+	hotkeys, err := s.Hotkeys(ctx)
+	if err != nil {
+		return domain.Settings{}, err
+	}
+	roots, err := s.ScanRoots(ctx)
+	if err != nil {
+		return domain.Settings{}, err
+	}
+	preferences, err := s.Preferences(ctx)
+	if err != nil {
+		return domain.Settings{}, err
+	}
+	halfLife, err := s.FrecencyHalfLifeDays(ctx)
+	if err != nil {
+		return domain.Settings{}, err
+	}
+	policy, err := s.BrokenItemPolicy(ctx)
+	if err != nil {
+		return domain.Settings{}, err
+	}
+	watch, err := s.WatchScanRoots(ctx)
+	if err != nil {
+		return domain.Settings{}, err
+	}
+	concurrency, err := s.ScanConcurrency(ctx)
+	if err != nil {
+		return domain.Settings{}, err
+	}
+	return domain.Settings{
+		Hotkeys:              hotkeys,
+		ScanRoots:            roots,
+		Preferences:          preferences,
+		FrecencyHalfLifeDays: halfLife,
+		BrokenItemPolicy:     policy,
+		WatchScanRoots:       watch,
+		ScanConcurrency:      concurrency,
+	}, nil
+}
+
+// Hotkeys returns the stored bindings merged over the defaults, so actions
+// added in later versions get their default keys.
+func (s *SettingsService) Hotkeys(ctx context.Context) ([]domain.HotkeyBinding, error) {
+	bindings := DefaultHotkeys()
+	var stored []domain.HotkeyBinding
+	found, err := s.load(ctx, settingHotkeys, &stored)
+	if err != nil || !found {
+		return bindings, err
+	}
+
+	for _, binding := range stored {
+		replaced := false
+		for i := range bindings {
+			if bindings[i].ID == binding.ID {
+				bindings[i].Keys = binding.Keys
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			bindings = append(bindings, binding)
+		}
+	}
+	return bindings, nil
+}
+
+func (s *SettingsService) SetHotkeys(ctx context.Context, bindings []domain.HotkeyBinding) ([]domain.HotkeyBinding, error) {
+	clean := make([]domain.HotkeyBinding, 0, len(bindings))
+	seen := map[string]struct{}{}
+	for _, binding := range bindings {
+		id := strings.TrimSpace(binding.ID)
+		if id == "" {
+			return nil, storage.ErrInvalidInput
+		}
+		if _, ok := seen[id]; ok {
+			return nil, storage.ErrInvalidInput
+		}
+		seen[id] = struct{}{}
+		clean = append(clean, domain.HotkeyBinding{ID: id, Keys: strings.TrimSpace(binding.Keys)})
+	}
+	if err := s.store(ctx, settingHotkeys, clean); err != nil {
+		return nil, err
+	}
+	return s.Hotkeys(ctx)
+}
+
+// ScanRoots returns the saved scan roots, or the platform defaults when the
+// user has never saved any.
+func (s *SettingsService) ScanRoots(ctx context.Context) ([]string, error) {
+	var roots []string
+	found, err := s.load(ctx, settingScanRoots, &roots)
+	if err != nil || !found {
+		return nonNilRoots(scanner.NormalizeRoots(scanner.DefaultRoots())), err
+	}
+	return nonNilRoots(scanner.NormalizeRoots(roots)), nil
+}
+
+func (s *SettingsService) SetScanRoots(ctx context.Context, roots []string) ([]string, error) {
+	clean := nonNilRoots(scanner.NormalizeRoots(roots))
+	if err := s.store(ctx, settingScanRoots, clean); err != nil {
+		return nil, err
+	}
+	return clean, nil
+}
+
+func (s *SettingsService) Preferences(ctx context.Context) (domain.Preferences, error) {
+	preferences := DefaultPreferences()
+	var stored domain.Preferences
+	found, err := s.load(ctx, settingPreferences, &stored)
+	if err != nil || !found {
+		return preferences, err
+	}
+
+	preferences.FocusSearchOnShow = stored.FocusSearchOnShow
+	preferences.LastWindowPosition = stored.LastWindowPosition
+	if isValidPanelPositionMode(stored.PanelPositionMode) {
+		preferences.PanelPositionMode = stored.PanelPositionMode
+	}
+	if isValidLaunchMode(stored.LaunchMode) {
+		preferences.LaunchMode = stored.LaunchMode
+	}
+	if isValidPanelCloseMode(stored.PanelCloseMode) {
+		preferences.PanelCloseMode = stored.PanelCloseMode
+	}
+	return preferences, nil
+}
+
+func (s *SettingsService) SetPreferences(ctx context.Context, preferences domain.Preferences) (domain.Preferences, error) {
+	if !isValidPanelPositionMode(preferences.PanelPositionMode) ||
+		!isValidLaunchMode(preferences.LaunchMode) ||
+		!isValidPanelCloseMode(preferences.PanelCloseMode) {
+		return domain.Preferences{}, storage.ErrInvalidInput
+	}
+	if err := s.store(ctx, settingPreferences, preferences); err != nil {
+		return domain.Preferences{}, err
+	}
+	return preferences, nil
+}
+
+func (s *SettingsService) FrecencyHalfLifeDays(ctx context.Context) (float64, error) {
+	var days float64
+	found, err := s.load(ctx, settingFrecencyHalfLifeDays, &days)
+	if err != nil || !found || days < minFrecencyHalfLifeDays || days > maxFrecencyHalfLifeDays {
+		return defaultFrecencyHalfLifeDays, err
+	}
+	return days, nil
+}
+
+func (s *SettingsService) SetFrecencyHalfLifeDays(ctx context.Context, days float64) error {
+	if days < minFrecencyHalfLifeDays || days > maxFrecencyHalfLifeDays {
+		return storage.ErrInvalidInput
+	}
+	return s.store(ctx, settingFrecencyHalfLifeDays, days)
+}
+
+// BrokenItemPolicy defaults to keeping broken items with a badge, so that
+// nothing is hidden or removed unless the user asks for it.
+func (s *SettingsService) BrokenItemPolicy(ctx context.Context) (domain.BrokenItemPolicy, error) {
+	var policy domain.BrokenItemPolicy
+	found, err := s.load(ctx, settingBrokenItemPolicy, &policy)
+	if err != nil || !found || !isValidBrokenItemPolicy(policy) {
+		return domain.BrokenItemKeep, err
+	}
+	return policy, nil
+}
+
+func (s *SettingsService) SetBrokenItemPolicy(ctx context.Context, policy domain.BrokenItemPolicy) error {
+	if !isValidBrokenItemPolicy(policy) {
+		return storage.ErrInvalidInput
+	}
+	return s.store(ctx, settingBrokenItemPolicy, policy)
+}
+
+// WatchScanRoots reports whether the scan roots are watched for changes,
+// which is on unless the user turned it off.
+func (s *SettingsService) WatchScanRoots(ctx context.Context) (bool, error) {
+	watch := true
+	found, err := s.load(ctx, settingWatchScanRoots, &watch)
+	if err != nil || !found {
+		return true, err
+	}
+	return watch, nil
+}
+
+func (s *SettingsService) SetWatchScanRoots(ctx context.Context, watch bool) error {
+	return s.store(ctx, settingWatchScanRoots, watch)
+}
+
+// ScanConcurrency returns the saved scan concurrency limit; zero, the
+// default, lets the scanner choose.
+func (s *SettingsService) ScanConcurrency(ctx context.Context) (int, error) {
+	var limit int
+	found, err := s.load(ctx, settingScanConcurrency, &limit)
+	if err != nil || !found || limit < 0 || limit > maxScanConcurrency {
+		return 0, err
+	}
+	return limit, nil
+}
+
+func (s *SettingsService) SetScanConcurrency(ctx context.Context, limit int) error {
+	if limit < 0 || limit > maxScanConcurrency {
+		return storage.ErrInvalidInput
+	}
+	return s.store(ctx, settingScanConcurrency, limit)
+}
+
+// load decodes the setting stored under key into target. A value that no
+// longer decodes is treated as missing rather than failing the caller.
+func (s *SettingsService) load(ctx context.Context, key string, target any) (bool, error) {
+	setting, err := s.repo.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := json.Unmarshal([]byte(setting.Value), target); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (s *SettingsService) store(ctx context.Context, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.repo.Set(ctx, domain.Setting{Key: key, Value: string(data)})
+}
+
+func nonNilRoots(roots []string) []string {
+	if roots == nil {
+		return []string{}
+	}
+	return roots
+}
+
+func isValidPanelPositionMode(mode domain.PanelPositionMode) bool {
+	switch mode {
+	case domain.PanelPositionCenter, domain.PanelPositionLast, domain.PanelPositionCursor:
+		return true
+	default:
+		return false
+	}
+}
+
+func isValidLaunchMode(mode domain.LaunchMode) bool {
+	switch mode {
+	case domain.LaunchModeSingle, domain.LaunchModeDouble:
+		return true
+	default:
+		return false
+	}
+}
+
+func isValidPanelCloseMode(mode domain.PanelCloseMode) bool {
+	switch mode {
+	case domain.PanelCloseManual, domain.PanelCloseLaunch, domain.PanelCloseBlur, domain.PanelCloseLaunchOrBlur:
+		return true
+	default:
+		return false
+	}
+}
+
+func isValidBrokenItemPolicy(policy domain.BrokenItemPolicy) bool {
+	switch policy {
+	case domain.BrokenItemKeep, domain.BrokenItemHide, domain.BrokenItemRemove:
+		return true
+	default:
+		return false
+	}
+}
@@ -2,8 +2,33 @@
 
 package service
 
-import "context"
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
-func resolveShortcutTarget(_ context.Context, _ string) (string, error) {
-	return "", nil
+	"rungrid/backend/desktopentry"
+)
+
+func resolveShortcutTarget(_ context.Context, source string) (string, error) {
+	if !strings.EqualFold(filepath.Ext(source), ".desktop") {
+		return "", nil
+	}
+	entry, err := desktopentry.ParseFile(source)
+	if err != nil {
+		return "", err
+	}
+	program := strings.TrimSpace(entry.ExecProgram())
+	if program == "" || strings.TrimSpace(entry.String("X-Flatpak")) != "" {
+		return "", nil
+	}
+	if filepath.IsAbs(program) {
+		return program, nil
+	}
+	resolved, err := exec.LookPath(program)
+	if err != nil {
+		return "", nil
+	}
+	return resolved, nil
 }
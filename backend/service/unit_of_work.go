@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+
+	"rungrid/backend/storage"
+)
+
+// withinTx runs fn in one transaction of the item service's unit of work.
+// The services fn works with must be bound to tx, since the sqlite backend
+// blocks any other database access until fn returns. Without a unit of
+// work, and inside services that are already bound, fn gets an empty Tx
+// and the bind methods keep the services' own repositories, so nested
+// calls join the outer transaction.
+func (s *ItemService) withinTx(ctx context.Context, fn func(tx storage.Tx) error) error {
+	if s.uow == nil {
+		return fn(storage.Tx{})
+	}
+	return s.uow.WithinTx(ctx, fn)
+}
+
+func (s *OperationService) withinTx(ctx context.Context, fn func(tx storage.Tx) error) error {
+	if s.uow == nil {
+		return fn(storage.Tx{})
+	}
+	return s.uow.WithinTx(ctx, fn)
+}
+
+// bind returns a copy of the service that reads and writes through tx.
+// The copy shares the search index and frecency scorer.
+func (s *ItemService) bind(tx storage.Tx) *ItemService {
+	return &ItemService{
+		repo:     txRepo(tx.Items, s.repo),
+		launches: txRepo(tx.Launches, s.launches),
+		groups:   txRepo(tx.Groups, s.groups),
+		frecency: s.frecency,
+		search:   s.search,
+		journal:  s.journal.bind(tx),
+	}
+}
+
+func (s *OperationService) bind(tx storage.Tx) *OperationService {
+	if s == nil {
+		return nil
+	}
+	return &OperationService{
+		repo:   txRepo(tx.Operations, s.repo),
+		items:  txRepo(tx.Items, s.items),
+		groups: txRepo(tx.Groups, s.groups),
+	}
+}
+
+func (s *GroupService) bind(tx storage.Tx, items *ItemService) *GroupService {
+	return &GroupService{repo: txRepo(tx.Groups, s.repo), items: items}
+}
+
+func (s *RuleSetService) bind(tx storage.Tx, items *ItemService) *RuleSetService {
+	if s == nil {
+		return nil
+	}
+	return &RuleSetService{repo: txRepo(tx.RuleSets, s.repo), groups: s.groups.bind(tx, items), items: items, dir: s.dir}
+}
+
+// txRepo returns the repository of the transaction, or fallback when the
+// Tx is empty.
+func txRepo[T comparable](repo T, fallback T) T {
+	var zero T
+	if repo == zero {
+		return fallback
+	}
+	return repo
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/storage"
+)
+
+// UsageService aggregates the launch history into per-item and per-group
+// statistics.
+type UsageService struct {
+	launches storage.LaunchRepository
+	items    *ItemService
+	groups   *GroupService
+}
+
+func NewUsageService(launches storage.LaunchRepository, items *ItemService, groups *GroupService) *UsageService {
+	return &UsageService{launches: launches, items: items, groups: groups}
+}
+
+// ItemUsage lists every item with its launches in window, most used first.
+// Items that were never launched are included so unused entries stand out.
+func (s *UsageService) ItemUsage(ctx context.Context, window domain.UsageWindow) ([]domain.ItemUsage, error) {
+	launches, err := s.windowLaunches(ctx, window, "")
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.items.List(ctx, storage.ItemFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]domain.ItemUsage, 0, len(items))
+	index := make(map[string]int, len(items))
+	for _, item := range items {
+		index[item.ID] = len(usage)
+		usage = append(usage, domain.ItemUsage{
+			ItemID:   item.ID,
+			Name:     item.Name,
+			GroupID:  item.GroupID,
+			BySource: map[domain.LaunchSource]int{},
+		})
+	}
+
+	for _, launch := range launches {
+		position, ok := index[launch.ItemID]
+		if !ok {
+			continue
+		}
+		entry := &usage[position]
+		if !launch.Success {
+			entry.Failures++
+			continue
+		}
+		entry.Launches++
+		if launch.Source != "" {
+			entry.BySource[launch.Source]++
+		}
+		if entry.LastLaunchedAt == nil || launch.LaunchedAt.After(*entry.LastLaunchedAt) {
+			launchedAt := launch.LaunchedAt
+			entry.LastLaunchedAt = &launchedAt
+		}
+	}
+
+	// items arrive in frecency order, which breaks ties.
+	sort.SliceStable(usage, func(i, j int) bool {
+		return usage[i].Launches > usage[j].Launches
+	})
+	return usage, nil
+}
+
+func (s *UsageService) TopItems(ctx context.Context, window domain.UsageWindow, limit int) ([]domain.ItemUsage, error) {
+	if limit <= 0 {
+		return nil, storage.ErrInvalidInput
+	}
+
+	usage, err := s.ItemUsage(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+
+	top := make([]domain.ItemUsage, 0, limit)
+	for _, entry := range usage {
+		if len(top) == limit || entry.Launches == 0 {
+			break
+		}
+		top = append(top, entry)
+	}
+	return top, nil
+}
+
+// GroupUsage sums item usage by the group each item currently belongs to.
+// Items without a group are reported under an empty group ID.
+func (s *UsageService) GroupUsage(ctx context.Context, window domain.UsageWindow) ([]domain.GroupUsage, error) {
+	itemUsage, err := s.ItemUsage(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.groups.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]domain.GroupUsage, 0, len(groups)+1)
+	index := make(map[string]int, len(groups)+1)
+	for _, group := range groups {
+		index[group.ID] = len(usage)
+		usage = append(usage, domain.GroupUsage{GroupID: group.ID, Name: group.Name})
+	}
+
+	for _, entry := range itemUsage {
+		position, ok := index[entry.GroupID]
+		if !ok {
+			position, ok = index[""]
+			if !ok {
+				position = len(usage)
+				index[""] = position
+				usage = append(usage, domain.GroupUsage{})
+			}
+		}
+		group := &usage[position]
+		group.Items++
+		group.Launches += entry.Launches
+		group.Failures += entry.Failures
+		if entry.Launches > 0 {
+			group.UsedItems++
+		}
+	}
+
+	sort.SliceStable(usage, func(i, j int) bool {
+		return usage[i].Launches > usage[j].Launches
+	})
+	return usage, nil
+}
+
+// Heatmap buckets successful launches by local weekday and hour. An empty
+// itemID covers all items.
+func (s *UsageService) Heatmap(ctx context.Context, window domain.UsageWindow, itemID string) (domain.UsageHeatmap, error) {
+	itemID = strings.TrimSpace(itemID)
+	launches, err := s.windowLaunches(ctx, window, itemID)
+	if err != nil {
+		return domain.UsageHeatmap{}, err
+	}
+
+	heatmap := domain.UsageHeatmap{Window: window, ItemID: itemID}
+	for _, launch := range launches {
+		if !launch.Success {
+			continue
+		}
+		local := launch.LaunchedAt.Local()
+		heatmap.Cells[local.Weekday()][local.Hour()]++
+		heatmap.Hours[local.Hour()]++
+		heatmap.Total++
+	}
+	return heatmap, nil
+}
+
+func (s *UsageService) windowLaunches(ctx context.Context, window domain.UsageWindow, itemID string) ([]domain.Launch, error) {
+	if s.launches == nil {
+		return []domain.Launch{}, nil
+	}
+
+	span, ok := window.Duration()
+	if !ok {
+		return nil, storage.ErrInvalidInput
+	}
+
+	return s.launches.List(ctx, storage.LaunchFilter{
+		ItemID: itemID,
+		Since:  time.Now().Add(-span),
+	})
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/watcher"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// WatcherService rescans the scan roots when files below them change, so
+// that added, renamed and deleted shortcuts show up without a manual scan.
+// Each batch of changes runs one incremental scan job and emits
+// items:changed with its result.
+type WatcherService struct {
+	jobs    *ScanJobService
+	watcher *watcher.Watcher
+
+	mu      sync.Mutex
+	ctx     context.Context
+	roots   []string
+	enabled bool
+}
+
+func NewWatcherService(jobs *ScanJobService) *WatcherService {
+	s := &WatcherService{jobs: jobs}
+	s.watcher = watcher.New(watcher.DefaultDelay, s.handleChanges, s.handleError)
+	return s
+}
+
+// Start watches roots when enabled is set and stops watching otherwise.
+// Calling it again replaces the roots, for example after the user edited
+// them.
+func (s *WatcherService) Start(ctx context.Context, roots []string, enabled bool) error {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.roots = append([]string(nil), roots...)
+	s.enabled = enabled
+	s.mu.Unlock()
+
+	if !enabled {
+		s.watcher.Stop()
+		return nil
+	}
+	return s.watcher.Start(roots)
+}
+
+func (s *WatcherService) Stop() {
+	s.watcher.Stop()
+}
+
+// Pause holds back rescans during bulk file operations; the changes made
+// meanwhile are scanned once after Resume.
+func (s *WatcherService) Pause() {
+	s.watcher.Pause()
+}
+
+func (s *WatcherService) Resume() {
+	s.watcher.Resume()
+}
+
+func (s *WatcherService) Status() domain.WatcherStatus {
+	s.mu.Lock()
+	enabled := s.enabled
+	s.mu.Unlock()
+	return domain.WatcherStatus{
+		Enabled:     enabled,
+		Running:     s.watcher.Running(),
+		Paused:      s.watcher.Paused(),
+		Directories: s.watcher.Directories(),
+	}
+}
+
+func (s *WatcherService) handleChanges(paths []string) {
+	s.mu.Lock()
+	ctx := s.ctx
+	roots := s.roots
+	s.mu.Unlock()
+	if ctx == nil {
+		return
+	}
+
+	job, err := s.jobs.Run(ctx, domain.ScanSourceWatcher, roots, paths)
+	if err != nil {
+		runtime.LogWarningf(ctx, "watch scan: %v", err)
+		return
+	}
+	if job.Status == domain.ScanJobCompleted {
+		runtime.EventsEmit(ctx, "items:changed", job.Result)
+	}
+}
+
+func (s *WatcherService) handleError(err error) {
+	s.mu.Lock()
+	ctx := s.ctx
+	s.mu.Unlock()
+	if ctx != nil {
+		runtime.LogWarningf(ctx, "watch: %v", err)
+	}
+}
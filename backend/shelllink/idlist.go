@@ -0,0 +1,117 @@
+package shelllink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+)
+
+const myComputerCLSID = "{20D04FE0-3AEA-1069-A2D8-08002B30309D}"
+
+var fileEntryExtensionSignature = []byte{0x04, 0x00, 0xEF, 0xBE}
+
+// parseIDList rebuilds a filesystem path from the shell items of a
+// LinkTargetIDList. Items that do not map to a path (control panel
+// entries, URIs, delegate folders) make the result empty.
+func parseIDList(data []byte) string {
+	parts := []string{}
+	for len(data) >= 2 {
+		size := int(binary.LittleEndian.Uint16(data[0:2]))
+		if size == 0 {
+			break
+		}
+		if size < 3 || size > len(data) {
+			return ""
+		}
+		item := data[2:size]
+		data = data[size:]
+
+		kind := item[0]
+		switch {
+		case kind == 0x1F:
+			if len(item) >= 18 && formatGUID(item[2:18]) == myComputerCLSID {
+				continue
+			}
+			return ""
+		case kind&0x70 == 0x20:
+			volume := ansiCString(item[1:])
+			if volume == "" {
+				return ""
+			}
+			parts = append(parts, strings.TrimSuffix(volume, `\`))
+		case kind&0x70 == 0x30:
+			name := fileEntryName(item)
+			if name == "" {
+				return ""
+			}
+			parts = append(parts, name)
+		case kind&0x70 == 0x40:
+			if len(item) < 4 {
+				return ""
+			}
+			location := ansiCString(item[3:])
+			if location == "" {
+				return ""
+			}
+			parts = append(parts, strings.TrimSuffix(location, `\`))
+		default:
+			return ""
+		}
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	path := strings.Join(parts, `\`)
+	if len(parts) == 1 && strings.HasSuffix(parts[0], ":") {
+		path += `\`
+	}
+	return path
+}
+
+// fileEntryName prefers the long name from the 0xBEEF0004 extension block
+// over the 8.3 primary name.
+func fileEntryName(item []byte) string {
+	if index := bytes.Index(item, fileEntryExtensionSignature); index >= 4 {
+		if name := extensionLongName(item[index-4:]); name != "" {
+			return name
+		}
+	}
+	if len(item) <= 12 {
+		return ""
+	}
+	if item[0]&0x04 != 0 {
+		return utf16CString(item[12:])
+	}
+	return ansiCString(item[12:])
+}
+
+func extensionLongName(block []byte) string {
+	if len(block) < 8 {
+		return ""
+	}
+	size := int(binary.LittleEndian.Uint16(block[0:2]))
+	version := binary.LittleEndian.Uint16(block[2:4])
+	if size > len(block) {
+		size = len(block)
+	}
+	block = block[:size]
+
+	offset := 18
+	if version >= 7 {
+		offset += 18
+	}
+	if version >= 3 {
+		offset += 2
+	}
+	if version >= 9 {
+		offset += 4
+	}
+	if version >= 8 {
+		offset += 4
+	}
+	if offset >= len(block) {
+		return ""
+	}
+	return utf16CString(block[offset:])
+}
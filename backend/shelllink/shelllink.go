@@ -0,0 +1,447 @@
+package shelllink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+var ErrInvalidLink = errors.New("invalid shell link")
+
+const headerSize = 0x4C
+
+var linkCLSID = []byte{
+	0x01, 0x14, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46,
+}
+
+const (
+	FlagHasLinkTargetIDList uint32 = 1 << 0
+	FlagHasLinkInfo         uint32 = 1 << 1
+	FlagHasName             uint32 = 1 << 2
+	FlagHasRelativePath     uint32 = 1 << 3
+	FlagHasWorkingDir       uint32 = 1 << 4
+	FlagHasArguments        uint32 = 1 << 5
+	FlagHasIconLocation     uint32 = 1 << 6
+	FlagIsUnicode           uint32 = 1 << 7
+	FlagForceNoLinkInfo     uint32 = 1 << 8
+	FlagHasExpString        uint32 = 1 << 9
+	FlagHasDarwinID         uint32 = 1 << 12
+	FlagHasExpIcon          uint32 = 1 << 14
+)
+
+const (
+	signatureEnvironment   uint32 = 0xA0000001
+	signatureSpecialFolder uint32 = 0xA0000005
+	signatureDarwin        uint32 = 0xA0000006
+	signatureIconEnv       uint32 = 0xA0000007
+	signatureKnownFolder   uint32 = 0xA000000B
+)
+
+// Link is a decoded MS-SHLLINK (.lnk) file.
+type Link struct {
+	Flags          uint32    `json:"flags"`
+	FileAttributes uint32    `json:"file_attributes"`
+	CreationTime   time.Time `json:"creation_time"`
+	AccessTime     time.Time `json:"access_time"`
+	WriteTime      time.Time `json:"write_time"`
+	FileSize       uint32    `json:"file_size"`
+	IconIndex      int32     `json:"icon_index"`
+	ShowCommand    uint32    `json:"show_command"`
+	HotKey         uint16    `json:"hot_key"`
+
+	// IDListPath is the path reconstructed from LinkTargetIDList, when the
+	// items describe a filesystem location.
+	IDListPath string    `json:"id_list_path"`
+	LinkInfo   *LinkInfo `json:"link_info"`
+
+	Name         string `json:"name"`
+	RelativePath string `json:"relative_path"`
+	WorkingDir   string `json:"working_dir"`
+	Arguments    string `json:"arguments"`
+	IconLocation string `json:"icon_location"`
+
+	// EnvironmentTarget and IconEnvironmentTarget hold unexpanded paths
+	// such as %ProgramFiles%\App\app.exe.
+	EnvironmentTarget     string     `json:"environment_target"`
+	IconEnvironmentTarget string     `json:"icon_environment_target"`
+	DarwinID              string     `json:"darwin_id"`
+	KnownFolder           *FolderRef `json:"known_folder"`
+	SpecialFolder         *FolderRef `json:"special_folder"`
+}
+
+// LinkInfo carries the volume and network information of the link target.
+type LinkInfo struct {
+	DriveType         uint32 `json:"drive_type"`
+	DriveSerialNumber uint32 `json:"drive_serial_number"`
+	VolumeLabel       string `json:"volume_label"`
+	LocalBasePath     string `json:"local_base_path"`
+	NetName           string `json:"net_name"`
+	DeviceName        string `json:"device_name"`
+	CommonPathSuffix  string `json:"common_path_suffix"`
+}
+
+// FolderRef points at the item in the IDList where a known or special
+// folder begins.
+type FolderRef struct {
+	ID     string `json:"id"`
+	Offset uint32 `json:"offset"`
+}
+
+func ParseFile(path string) (Link, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Link{}, err
+	}
+	return Parse(data)
+}
+
+func Parse(data []byte) (Link, error) {
+	if len(data) < headerSize || binary.LittleEndian.Uint32(data[0:4]) != headerSize || !bytes.Equal(data[4:20], linkCLSID) {
+		return Link{}, ErrInvalidLink
+	}
+
+	link := Link{
+		Flags:          binary.LittleEndian.Uint32(data[20:24]),
+		FileAttributes: binary.LittleEndian.Uint32(data[24:28]),
+		CreationTime:   fileTime(data[28:36]),
+		AccessTime:     fileTime(data[36:44]),
+		WriteTime:      fileTime(data[44:52]),
+		FileSize:       binary.LittleEndian.Uint32(data[52:56]),
+		IconIndex:      int32(binary.LittleEndian.Uint32(data[56:60])),
+		ShowCommand:    binary.LittleEndian.Uint32(data[60:64]),
+		HotKey:         binary.LittleEndian.Uint16(data[64:66]),
+	}
+
+	reader := &byteReader{data: data, offset: headerSize}
+
+	if link.Flags&FlagHasLinkTargetIDList != 0 {
+		size, err := reader.uint16()
+		if err != nil {
+			return Link{}, err
+		}
+		idList, err := reader.bytes(int(size))
+		if err != nil {
+			return Link{}, err
+		}
+		link.IDListPath = parseIDList(idList)
+	}
+
+	if link.Flags&FlagHasLinkInfo != 0 && link.Flags&FlagForceNoLinkInfo == 0 {
+		start := reader.offset
+		size, err := reader.uint32()
+		if err != nil {
+			return Link{}, err
+		}
+		if size < 4 || start+int(size) > len(data) {
+			return Link{}, fmt.Errorf("%w: link info out of range", ErrInvalidLink)
+		}
+		info, err := parseLinkInfo(data[start : start+int(size)])
+		if err != nil {
+			return Link{}, err
+		}
+		link.LinkInfo = &info
+		reader.offset = start + int(size)
+	}
+
+	unicode := link.Flags&FlagIsUnicode != 0
+	for _, field := range []struct {
+		flag   uint32
+		target *string
+	}{
+		{FlagHasName, &link.Name},
+		{FlagHasRelativePath, &link.RelativePath},
+		{FlagHasWorkingDir, &link.WorkingDir},
+		{FlagHasArguments, &link.Arguments},
+		{FlagHasIconLocation, &link.IconLocation},
+	} {
+		if link.Flags&field.flag == 0 {
+			continue
+		}
+		value, err := reader.stringData(unicode)
+		if err != nil {
+			return Link{}, err
+		}
+		*field.target = value
+	}
+
+	parseExtraData(&link, data[reader.offset:])
+	return link, nil
+}
+
+// Target returns the best available target path, in the order Windows
+// itself prefers: the environment block, the local LinkInfo path, the
+// IDList, then the network path and finally the relative path.
+func (l Link) Target() string {
+	if l.EnvironmentTarget != "" {
+		return l.EnvironmentTarget
+	}
+	if l.LinkInfo != nil {
+		if l.LinkInfo.LocalBasePath != "" {
+			return joinWindowsPath(l.LinkInfo.LocalBasePath, l.LinkInfo.CommonPathSuffix)
+		}
+	}
+	if l.IDListPath != "" {
+		return l.IDListPath
+	}
+	if l.LinkInfo != nil && l.LinkInfo.NetName != "" {
+		return joinWindowsPath(l.LinkInfo.NetName, l.LinkInfo.CommonPathSuffix)
+	}
+	return l.RelativePath
+}
+
+// Advertised reports whether the link is an MSI advertised shortcut, whose
+// real target can only be resolved by Windows Installer.
+func (l Link) Advertised() bool {
+	return l.Flags&FlagHasDarwinID != 0 || l.DarwinID != ""
+}
+
+// Directory reports whether the target was a folder when the link was
+// saved. It lets a link be classified where its target does not exist.
+func (l Link) Directory() bool {
+	const fileAttributeDirectory = 0x10
+	return l.FileAttributes&fileAttributeDirectory != 0
+}
+
+func parseLinkInfo(data []byte) (LinkInfo, error) {
+	if len(data) < 0x1C {
+		return LinkInfo{}, fmt.Errorf("%w: short link info", ErrInvalidLink)
+	}
+	infoHeaderSize := binary.LittleEndian.Uint32(data[4:8])
+	flags := binary.LittleEndian.Uint32(data[8:12])
+	volumeIDOffset := binary.LittleEndian.Uint32(data[12:16])
+	localBasePathOffset := binary.LittleEndian.Uint32(data[16:20])
+	networkOffset := binary.LittleEndian.Uint32(data[20:24])
+	suffixOffset := binary.LittleEndian.Uint32(data[24:28])
+
+	info := LinkInfo{}
+	const (
+		volumeIDAndLocalBasePath               = 1 << 0
+		commonNetworkRelativeLinkAndPathSuffix = 1 << 1
+	)
+
+	if flags&volumeIDAndLocalBasePath != 0 {
+		if volume := sliceFrom(data, volumeIDOffset); len(volume) >= 0x10 {
+			info.DriveType = binary.LittleEndian.Uint32(volume[4:8])
+			info.DriveSerialNumber = binary.LittleEndian.Uint32(volume[8:12])
+			labelOffset := binary.LittleEndian.Uint32(volume[12:16])
+			if labelOffset == 0x14 && len(volume) >= 0x14 {
+				info.VolumeLabel = utf16CString(sliceFrom(volume, binary.LittleEndian.Uint32(volume[16:20])))
+			} else {
+				info.VolumeLabel = ansiCString(sliceFrom(volume, labelOffset))
+			}
+		}
+		info.LocalBasePath = ansiCString(sliceFrom(data, localBasePathOffset))
+		if infoHeaderSize >= 0x24 && len(data) >= 0x24 {
+			if unicodePath := utf16CString(sliceFrom(data, binary.LittleEndian.Uint32(data[28:32]))); unicodePath != "" {
+				info.LocalBasePath = unicodePath
+			}
+		}
+	}
+
+	if flags&commonNetworkRelativeLinkAndPathSuffix != 0 {
+		if network := sliceFrom(data, networkOffset); len(network) >= 0x14 {
+			netNameOffset := binary.LittleEndian.Uint32(network[8:12])
+			deviceNameOffset := binary.LittleEndian.Uint32(network[12:16])
+			info.NetName = ansiCString(sliceFrom(network, netNameOffset))
+			if deviceNameOffset != 0 {
+				info.DeviceName = ansiCString(sliceFrom(network, deviceNameOffset))
+			}
+			if netNameOffset > 0x14 && len(network) >= 0x1C {
+				if value := utf16CString(sliceFrom(network, binary.LittleEndian.Uint32(network[20:24]))); value != "" {
+					info.NetName = value
+				}
+				if value := utf16CString(sliceFrom(network, binary.LittleEndian.Uint32(network[24:28]))); value != "" {
+					info.DeviceName = value
+				}
+			}
+		}
+	}
+
+	info.CommonPathSuffix = ansiCString(sliceFrom(data, suffixOffset))
+	if infoHeaderSize >= 0x24 && len(data) >= 0x24 {
+		if unicodeSuffix := utf16CString(sliceFrom(data, binary.LittleEndian.Uint32(data[32:36]))); unicodeSuffix != "" {
+			info.CommonPathSuffix = unicodeSuffix
+		}
+	}
+
+	return info, nil
+}
+
+func parseExtraData(link *Link, data []byte) {
+	for len(data) >= 8 {
+		size := binary.LittleEndian.Uint32(data[0:4])
+		if size < 8 || int(size) > len(data) {
+			return
+		}
+		block := data[:size]
+		switch binary.LittleEndian.Uint32(block[4:8]) {
+		case signatureEnvironment:
+			link.EnvironmentTarget = targetBlockValue(block)
+		case signatureIconEnv:
+			link.IconEnvironmentTarget = targetBlockValue(block)
+		case signatureDarwin:
+			link.DarwinID = targetBlockValue(block)
+		case signatureKnownFolder:
+			if len(block) >= 0x1C {
+				link.KnownFolder = &FolderRef{
+					ID:     formatGUID(block[8:24]),
+					Offset: binary.LittleEndian.Uint32(block[24:28]),
+				}
+			}
+		case signatureSpecialFolder:
+			if len(block) >= 0x10 {
+				link.SpecialFolder = &FolderRef{
+					ID:     fmt.Sprintf("%d", binary.LittleEndian.Uint32(block[8:12])),
+					Offset: binary.LittleEndian.Uint32(block[12:16]),
+				}
+			}
+		}
+		data = data[size:]
+	}
+}
+
+// targetBlockValue decodes the ANSI/Unicode pair shared by the
+// environment, icon environment and darwin data blocks.
+func targetBlockValue(block []byte) string {
+	const ansiLength = 260
+	if len(block) >= 8+ansiLength+2*ansiLength {
+		if value := utf16CString(block[8+ansiLength : 8+3*ansiLength]); value != "" {
+			return value
+		}
+	}
+	if len(block) >= 8+ansiLength {
+		return ansiCString(block[8 : 8+ansiLength])
+	}
+	return ""
+}
+
+type byteReader struct {
+	data   []byte
+	offset int
+}
+
+func (r *byteReader) bytes(n int) ([]byte, error) {
+	if n < 0 || r.offset+n > len(r.data) {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidLink)
+	}
+	value := r.data[r.offset : r.offset+n]
+	r.offset += n
+	return value, nil
+}
+
+func (r *byteReader) uint16() (uint16, error) {
+	value, err := r.bytes(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint16(value), nil
+}
+
+func (r *byteReader) uint32() (uint32, error) {
+	value, err := r.bytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(value), nil
+}
+
+func (r *byteReader) stringData(unicode bool) (string, error) {
+	count, err := r.uint16()
+	if err != nil {
+		return "", err
+	}
+	if unicode {
+		raw, err := r.bytes(int(count) * 2)
+		if err != nil {
+			return "", err
+		}
+		return decodeUTF16(raw), nil
+	}
+	raw, err := r.bytes(int(count))
+	if err != nil {
+		return "", err
+	}
+	return decodeANSI(raw), nil
+}
+
+func sliceFrom(data []byte, offset uint32) []byte {
+	if offset == 0 || int(offset) >= len(data) {
+		return nil
+	}
+	return data[offset:]
+}
+
+func ansiCString(data []byte) string {
+	if end := bytes.IndexByte(data, 0); end >= 0 {
+		data = data[:end]
+	}
+	return decodeANSI(data)
+}
+
+func utf16CString(data []byte) string {
+	for i := 0; i+1 < len(data); i += 2 {
+		if data[i] == 0 && data[i+1] == 0 {
+			return decodeUTF16(data[:i])
+		}
+	}
+	return decodeUTF16(data)
+}
+
+func decodeUTF16(data []byte) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// decodeANSI maps bytes as Latin-1. The code page of the machine that wrote
+// the link is unknown; Unicode fields are preferred wherever present.
+func decodeANSI(data []byte) string {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+func fileTime(data []byte) time.Time {
+	value := binary.LittleEndian.Uint64(data)
+	if value == 0 {
+		return time.Time{}
+	}
+	const epochDelta = 116444736000000000
+	if value < epochDelta {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(value-epochDelta)*100).UTC()
+}
+
+func formatGUID(data []byte) string {
+	if len(data) < 16 {
+		return ""
+	}
+	return fmt.Sprintf("{%08X-%04X-%04X-%X-%X}",
+		binary.LittleEndian.Uint32(data[0:4]),
+		binary.LittleEndian.Uint16(data[4:6]),
+		binary.LittleEndian.Uint16(data[6:8]),
+		data[8:10],
+		data[10:16],
+	)
+}
+
+func joinWindowsPath(base, suffix string) string {
+	if suffix == "" {
+		return base
+	}
+	if strings.HasSuffix(base, `\`) {
+		return base + suffix
+	}
+	return base + `\` + suffix
+}
@@ -0,0 +1,135 @@
+package shelllink
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func parseFixture(t *testing.T, name string) Link {
+	t.Helper()
+	link, err := ParseFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("ParseFile(%s): %v", name, err)
+	}
+	return link
+}
+
+func TestParsePlainTarget(t *testing.T) {
+	link := parseFixture(t, "plain.lnk")
+
+	const target = `C:\Program Files\Notepad++\notepad++.exe`
+	if got := link.Target(); got != target {
+		t.Errorf("Target() = %q, want %q", got, target)
+	}
+	if link.IDListPath != target {
+		t.Errorf("IDListPath = %q, want %q", link.IDListPath, target)
+	}
+	if link.LinkInfo == nil || link.LinkInfo.LocalBasePath != target {
+		t.Errorf("LinkInfo = %+v, want local base path %q", link.LinkInfo, target)
+	}
+	if link.LinkInfo != nil && (link.LinkInfo.DriveType != 3 || link.LinkInfo.VolumeLabel != "OS") {
+		t.Errorf("volume = %d %q, want fixed drive OS", link.LinkInfo.DriveType, link.LinkInfo.VolumeLabel)
+	}
+	if link.Arguments != "-multiInst -nosession" {
+		t.Errorf("Arguments = %q", link.Arguments)
+	}
+	if link.WorkingDir != `C:\Program Files\Notepad++` {
+		t.Errorf("WorkingDir = %q", link.WorkingDir)
+	}
+	if link.RelativePath != `..\..\..\..\..\..\Program Files\Notepad++\notepad++.exe` {
+		t.Errorf("RelativePath = %q", link.RelativePath)
+	}
+	if link.IconLocation != target {
+		t.Errorf("IconLocation = %q", link.IconLocation)
+	}
+	if link.FileSize != 6713344 {
+		t.Errorf("FileSize = %d", link.FileSize)
+	}
+	if want := time.Date(2023, time.March, 14, 9, 26, 53, 0, time.UTC); !link.WriteTime.Equal(want) {
+		t.Errorf("WriteTime = %v, want %v", link.WriteTime, want)
+	}
+	if link.Advertised() || link.Directory() {
+		t.Errorf("Advertised() = %v, Directory() = %v, want false", link.Advertised(), link.Directory())
+	}
+}
+
+func TestParseKnownFolder(t *testing.T) {
+	link := parseFixture(t, "knownfolder.lnk")
+
+	if got, want := link.Target(), `C:\Users\Public\Documents`; got != want {
+		t.Errorf("Target() = %q, want %q", got, want)
+	}
+	if link.IDListPath != `C:\Users\Public\Documents` {
+		t.Errorf("IDListPath = %q", link.IDListPath)
+	}
+	if link.KnownFolder == nil || link.KnownFolder.ID != "{ED4824AF-DCE4-45A8-81E2-FC7965083634}" {
+		t.Fatalf("KnownFolder = %+v, want FOLDERID_PublicDocuments", link.KnownFolder)
+	}
+	if link.SpecialFolder == nil || link.SpecialFolder.ID != "46" {
+		t.Fatalf("SpecialFolder = %+v, want CSIDL_COMMON_DOCUMENTS", link.SpecialFolder)
+	}
+	if link.KnownFolder.Offset != link.SpecialFolder.Offset || link.KnownFolder.Offset == 0 {
+		t.Errorf("folder offsets = %d and %d", link.KnownFolder.Offset, link.SpecialFolder.Offset)
+	}
+	if !link.Directory() {
+		t.Error("Directory() = false, want true")
+	}
+}
+
+func TestParseEnvironmentBlock(t *testing.T) {
+	link := parseFixture(t, "env.lnk")
+
+	const expandable = `%ProgramFiles%\Tool\tool.exe`
+	if link.EnvironmentTarget != expandable {
+		t.Errorf("EnvironmentTarget = %q, want %q", link.EnvironmentTarget, expandable)
+	}
+	if link.IconEnvironmentTarget != expandable {
+		t.Errorf("IconEnvironmentTarget = %q, want %q", link.IconEnvironmentTarget, expandable)
+	}
+	// The environment block wins over the expanded LinkInfo path.
+	if got := link.Target(); got != expandable {
+		t.Errorf("Target() = %q, want %q", got, expandable)
+	}
+	if link.LinkInfo == nil || link.LinkInfo.LocalBasePath != `C:\Program Files\Tool\tool.exe` {
+		t.Errorf("LinkInfo = %+v", link.LinkInfo)
+	}
+	if link.WorkingDir != `%ProgramFiles%\Tool` {
+		t.Errorf("WorkingDir = %q", link.WorkingDir)
+	}
+}
+
+func TestParseAdvertised(t *testing.T) {
+	link := parseFixture(t, "advertised.lnk")
+
+	if !link.Advertised() {
+		t.Fatal("Advertised() = false, want true")
+	}
+	if link.DarwinID != "w_1^VX!!!!!!!!!MKKSkWINWORDFiles>tW{~$4Q]c@II=l2xaTO5Z" {
+		t.Errorf("DarwinID = %q", link.DarwinID)
+	}
+	// The IDList only names the cached icon, which is not the program.
+	const icon = `C:\Windows\Installer\{90160000-0011-0000-0000-0000000FF1CE}\wordicon.exe`
+	if link.IDListPath != icon {
+		t.Errorf("IDListPath = %q, want %q", link.IDListPath, icon)
+	}
+	if link.LinkInfo != nil {
+		t.Errorf("LinkInfo = %+v, want none", link.LinkInfo)
+	}
+}
+
+func TestParseRejectsInvalidData(t *testing.T) {
+	if _, err := Parse([]byte("not a shell link")); !errors.Is(err, ErrInvalidLink) {
+		t.Errorf("Parse(garbage) error = %v, want ErrInvalidLink", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join("testdata", "plain.lnk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(data[:headerSize+40]); !errors.Is(err, ErrInvalidLink) {
+		t.Errorf("Parse(truncated) error = %v, want ErrInvalidLink", err)
+	}
+}
@@ -25,6 +25,9 @@ func SortItems(items []domain.Item) {
 		if entries[i].item.Favorite != entries[j].item.Favorite {
 			return entries[i].item.Favorite
 		}
+		if entries[i].item.Frecency != entries[j].item.Frecency {
+			return entries[i].item.Frecency > entries[j].item.Frecency
+		}
 		iUsed := lastUsedAt(entries[i].item)
 		jUsed := lastUsedAt(entries[j].item)
 		if !iUsed.Equal(jUsed) {
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+
+	"rungrid/backend/domain"
+)
+
+// NewItem returns the item an upsert inserts for input, with a new ID.
+func NewItem(input domain.ItemInput) domain.Item {
+	return domain.Item{
+		ID:         uuid.NewString(),
+		Name:       input.Name,
+		Path:       input.Path,
+		TargetName: input.TargetName,
+		Type:       input.Type,
+		IconPath:   input.IconPath,
+		GroupID:    input.GroupID,
+		Tags:       input.Tags,
+		Favorite:   input.Favorite,
+		Hidden:     input.Hidden,
+	}
+}
+
+// UpsertItem applies input to the existing item at the same path and
+// reports whether anything changed. Only the type and the target name are
+// taken over, so names, groups and flags the user edited survive; an empty
+// target name, or one that differs only in case, is ignored.
+func UpsertItem(existing domain.Item, input domain.ItemInput) (domain.Item, bool) {
+	updated := existing
+	if input.Type.IsValid() && input.Type != existing.Type {
+		updated.Type = input.Type
+	}
+	if input.TargetName != "" && !strings.EqualFold(input.TargetName, existing.TargetName) {
+		updated.TargetName = input.TargetName
+	}
+	return updated, updated.Type != existing.Type || updated.TargetName != existing.TargetName
+}
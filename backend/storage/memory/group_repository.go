@@ -10,13 +10,17 @@ import (
 	"rungrid/backend/storage"
 )
 
+// GroupRepository keeps the items and launches it was created with
+// consistent when a group is deleted.
 type GroupRepository struct {
-	mu     sync.RWMutex
-	groups map[string]domain.Group
+	mu       sync.RWMutex
+	groups   map[string]domain.Group
+	items    *ItemRepository
+	launches *LaunchRepository
 }
 
-func NewGroupRepository() *GroupRepository {
-	return &GroupRepository{groups: make(map[string]domain.Group)}
+func NewGroupRepository(items *ItemRepository, launches *LaunchRepository) *GroupRepository {
+	return &GroupRepository{groups: make(map[string]domain.Group), items: items, launches: launches}
 }
 
 func (r *GroupRepository) List(_ context.Context) ([]domain.Group, error) {
@@ -97,3 +101,90 @@ func (r *GroupRepository) Delete(_ context.Context, id string) error {
 	delete(r.groups, id)
 	return nil
 }
+
+func (r *GroupRepository) DeleteWithItems(_ context.Context, id string, moveTo string, deleteItems bool) error {
+	if strings.TrimSpace(id) == "" {
+		return storage.ErrInvalidInput
+	}
+
+	r.items.mu.Lock()
+	defer r.items.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.launches.mu.Lock()
+	defer r.launches.mu.Unlock()
+
+	deletedGroup, exists := r.groups[id]
+	if !exists {
+		return storage.ErrNotFound
+	}
+	if moveTo != "" && !deleteItems {
+		if _, exists := r.groups[moveTo]; !exists {
+			return storage.ErrNotFound
+		}
+	}
+
+	deleted := map[string]struct{}{}
+	for itemID, item := range r.items.items {
+		if item.GroupID != id {
+			continue
+		}
+		if deleteItems {
+			deleted[itemID] = struct{}{}
+			delete(r.items.items, itemID)
+			continue
+		}
+		item.GroupID = moveTo
+		r.items.items[itemID] = item
+	}
+	if len(deleted) > 0 {
+		kept := r.launches.launches[:0]
+		for _, launch := range r.launches.launches {
+			if _, ok := deleted[launch.ItemID]; !ok {
+				kept = append(kept, launch)
+			}
+		}
+		r.launches.launches = kept
+	}
+
+	for groupID, group := range r.groups {
+		if group.ParentID == id {
+			group.ParentID = deletedGroup.ParentID
+			r.groups[groupID] = group
+		}
+	}
+	delete(r.groups, id)
+	return nil
+}
+
+func (r *GroupRepository) UngroupOrphans(_ context.Context) (int, error) {
+	r.items.mu.Lock()
+	defer r.items.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changed := 0
+	for groupID, group := range r.groups {
+		if group.ParentID == "" {
+			continue
+		}
+		if _, exists := r.groups[group.ParentID]; exists {
+			continue
+		}
+		group.ParentID = ""
+		r.groups[groupID] = group
+		changed++
+	}
+	for itemID, item := range r.items.items {
+		if item.GroupID == "" {
+			continue
+		}
+		if _, exists := r.groups[item.GroupID]; exists {
+			continue
+		}
+		item.GroupID = ""
+		r.items.items[itemID] = item
+		changed++
+	}
+	return changed, nil
+}
@@ -2,6 +2,7 @@ package memory
 
 import (
 	"context"
+	"slices"
 	"strings"
 	"sync"
 
@@ -24,13 +25,13 @@ func (r *ItemRepository) List(_ context.Context, filter storage.ItemFilter) ([]d
 	defer r.mu.RUnlock()
 
 	items := make([]domain.Item, 0, len(r.items))
-	query := strings.ToLower(strings.TrimSpace(filter.Query))
 
 	for _, item := range r.items {
-		if filter.GroupID != "" && filter.GroupID != "all" && item.GroupID != filter.GroupID {
-			continue
-		}
-		if query != "" && !strings.Contains(strings.ToLower(item.Name), query) {
+		if len(filter.GroupIDs) > 0 {
+			if !slices.Contains(filter.GroupIDs, item.GroupID) {
+				continue
+			}
+		} else if filter.GroupID != "" && filter.GroupID != "all" && item.GroupID != filter.GroupID {
 			continue
 		}
 		items = append(items, item)
@@ -88,6 +89,24 @@ func (r *ItemRepository) SetIconPath(_ context.Context, id string, iconPath stri
 	return nil
 }
 
+func (r *ItemRepository) SetBroken(_ context.Context, id string, reason domain.BrokenReason) error {
+	if strings.TrimSpace(id) == "" {
+		return storage.ErrInvalidInput
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item, exists := r.items[id]
+	if !exists {
+		return storage.ErrNotFound
+	}
+
+	item.Broken = reason
+	r.items[id] = item
+	return nil
+}
+
 func (r *ItemRepository) Create(_ context.Context, item domain.Item) (domain.Item, error) {
 	if strings.TrimSpace(item.ID) == "" {
 		return domain.Item{}, storage.ErrInvalidInput
@@ -104,6 +123,42 @@ func (r *ItemRepository) Create(_ context.Context, item domain.Item) (domain.Ite
 	return item, nil
 }
 
+func (r *ItemRepository) UpsertByPath(ctx context.Context, inputs []domain.ItemInput) ([]domain.ItemUpsert, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byPath := make(map[string]string, len(r.items))
+	for id, item := range r.items {
+		byPath[strings.ToLower(item.Path)] = id
+	}
+
+	// Checked up front, so a cancelled batch writes nothing.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	results := make([]domain.ItemUpsert, 0, len(inputs))
+	for _, input := range inputs {
+		id, found := byPath[strings.ToLower(input.Path)]
+		if !found {
+			item := storage.NewItem(input)
+			r.items[item.ID] = item
+			byPath[strings.ToLower(item.Path)] = item.ID
+			results = append(results, domain.ItemUpsert{Outcome: domain.UpsertInserted, Item: item})
+			continue
+		}
+
+		existing := r.items[id]
+		updated, changed := storage.UpsertItem(existing, input)
+		if !changed {
+			results = append(results, domain.ItemUpsert{Outcome: domain.UpsertUnchanged, Item: existing})
+			continue
+		}
+		r.items[id] = updated
+		results = append(results, domain.ItemUpsert{Outcome: domain.UpsertUpdated, Item: updated, Before: &existing})
+	}
+	return results, nil
+}
+
 func (r *ItemRepository) Update(_ context.Context, item domain.Item) (domain.Item, error) {
 	if strings.TrimSpace(item.ID) == "" {
 		return domain.Item{}, storage.ErrInvalidInput
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/storage"
+)
+
+type LaunchRepository struct {
+	mu       sync.RWMutex
+	launches []domain.Launch
+}
+
+func NewLaunchRepository() *LaunchRepository {
+	return &LaunchRepository{}
+}
+
+func (r *LaunchRepository) Record(_ context.Context, launch domain.Launch) error {
+	if launch.ItemID == "" {
+		return storage.ErrInvalidInput
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.launches = append(r.launches, launch)
+	return nil
+}
+
+func (r *LaunchRepository) List(_ context.Context, filter storage.LaunchFilter) ([]domain.Launch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	launches := []domain.Launch{}
+	for _, launch := range r.launches {
+		if filter.ItemID != "" && launch.ItemID != filter.ItemID {
+			continue
+		}
+		if !filter.Since.IsZero() && launch.LaunchedAt.Before(filter.Since) {
+			continue
+		}
+		launches = append(launches, launch)
+	}
+
+	sort.SliceStable(launches, func(i, j int) bool {
+		return launches[i].LaunchedAt.After(launches[j].LaunchedAt)
+	})
+	return launches, nil
+}
+
+func (r *LaunchRepository) Recent(_ context.Context, perItem int) (map[string][]time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string][]time.Time)
+	for _, launch := range r.launches {
+		if !launch.Success {
+			continue
+		}
+		result[launch.ItemID] = append(result[launch.ItemID], launch.LaunchedAt)
+	}
+	for id, times := range result {
+		result[id] = latest(times, perItem)
+	}
+	return result, nil
+}
+
+func (r *LaunchRepository) RecentForItem(_ context.Context, itemID string, limit int) ([]time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	times := []time.Time{}
+	for _, launch := range r.launches {
+		if launch.ItemID == itemID && launch.Success {
+			times = append(times, launch.LaunchedAt)
+		}
+	}
+	return latest(times, limit), nil
+}
+
+func (r *LaunchRepository) DeleteByItem(_ context.Context, itemID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.launches[:0]
+	for _, launch := range r.launches {
+		if launch.ItemID != itemID {
+			kept = append(kept, launch)
+		}
+	}
+	r.launches = kept
+	return nil
+}
+
+func (r *LaunchRepository) Clear(_ context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.launches = nil
+	return nil
+}
+
+func latest(times []time.Time, limit int) []time.Time {
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].After(times[j])
+	})
+	if limit > 0 && len(times) > limit {
+		times = times[:limit]
+	}
+	return times
+}
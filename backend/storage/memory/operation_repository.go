@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/storage"
+)
+
+type operationRecord struct {
+	operation domain.Operation
+	entries   []domain.OperationEntry
+}
+
+// OperationRepository undoes operations against the memory repositories it
+// was created with.
+type OperationRepository struct {
+	mu         sync.RWMutex
+	operations []operationRecord
+	items      *ItemRepository
+	groups     *GroupRepository
+	launches   *LaunchRepository
+}
+
+func NewOperationRepository(items *ItemRepository, groups *GroupRepository, launches *LaunchRepository) *OperationRepository {
+	return &OperationRepository{items: items, groups: groups, launches: launches}
+}
+
+func (r *OperationRepository) Create(_ context.Context, operation domain.Operation, entries []domain.OperationEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range entries {
+		switch entry.Entity {
+		case domain.OperationEntityItem:
+			operation.Items++
+		case domain.OperationEntityGroup:
+			operation.Groups++
+		}
+	}
+	r.operations = append(r.operations, operationRecord{
+		operation: operation,
+		entries:   append([]domain.OperationEntry(nil), entries...),
+	})
+	return nil
+}
+
+func (r *OperationRepository) List(_ context.Context, limit int) ([]domain.Operation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	operations := make([]domain.Operation, 0, len(r.operations))
+	for index := len(r.operations) - 1; index >= 0; index-- {
+		operations = append(operations, r.operations[index].operation)
+	}
+	sort.SliceStable(operations, func(i, j int) bool {
+		return operations[i].CreatedAt.After(operations[j].CreatedAt)
+	})
+	if limit > 0 && len(operations) > limit {
+		operations = operations[:limit]
+	}
+	return operations, nil
+}
+
+func (r *OperationRepository) Get(_ context.Context, id string) (domain.Operation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, ok := r.find(id)
+	if !ok {
+		return domain.Operation{}, storage.ErrNotFound
+	}
+	return record.operation, nil
+}
+
+func (r *OperationRepository) Entries(_ context.Context, id string) ([]domain.OperationEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, ok := r.find(id)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return append([]domain.OperationEntry{}, record.entries...), nil
+}
+
+func (r *OperationRepository) Undo(_ context.Context, id string, undoneAt time.Time) (domain.Operation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.find(id)
+	if !ok || record.operation.UndoneAt != nil {
+		return domain.Operation{}, storage.ErrNotFound
+	}
+
+	r.items.mu.Lock()
+	r.groups.mu.Lock()
+	r.launches.mu.Lock()
+	for index := len(record.entries) - 1; index >= 0; index-- {
+		r.restore(record.entries[index])
+	}
+	r.launches.mu.Unlock()
+	r.groups.mu.Unlock()
+	r.items.mu.Unlock()
+
+	record.operation.UndoneAt = &undoneAt
+	return record.operation, nil
+}
+
+func (r *OperationRepository) Prune(_ context.Context, keep int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.operations) > keep {
+		r.operations = append([]operationRecord(nil), r.operations[len(r.operations)-keep:]...)
+	}
+	return nil
+}
+
+func (r *OperationRepository) find(id string) (*operationRecord, bool) {
+	for index := range r.operations {
+		if r.operations[index].operation.ID == id {
+			return &r.operations[index], true
+		}
+	}
+	return nil, false
+}
+
+// restore expects the item, group and launch locks to be held.
+func (r *OperationRepository) restore(entry domain.OperationEntry) {
+	switch entry.Entity {
+	case domain.OperationEntityItem:
+		if entry.Item == nil {
+			delete(r.items.items, entry.EntityID)
+			kept := r.launches.launches[:0]
+			for _, launch := range r.launches.launches {
+				if launch.ItemID != entry.EntityID {
+					kept = append(kept, launch)
+				}
+			}
+			r.launches.launches = kept
+			return
+		}
+		item := *entry.Item
+		if current, ok := r.items.items[item.ID]; ok {
+			item.LaunchCount = current.LaunchCount
+			item.LastUsedAt = current.LastUsedAt
+		}
+		r.items.items[item.ID] = item
+		r.launches.launches = append(r.launches.launches, entry.Launches...)
+	case domain.OperationEntityGroup:
+		if entry.Group == nil {
+			delete(r.groups.groups, entry.EntityID)
+			return
+		}
+		r.groups.groups[entry.Group.ID] = *entry.Group
+	}
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/storage"
+)
+
+type RuleSetRepository struct {
+	mu   sync.RWMutex
+	sets map[string]domain.RuleSet
+}
+
+func NewRuleSetRepository() *RuleSetRepository {
+	return &RuleSetRepository{sets: make(map[string]domain.RuleSet)}
+}
+
+func (r *RuleSetRepository) List(_ context.Context) ([]domain.RuleSet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sets := make([]domain.RuleSet, 0, len(r.sets))
+	for _, set := range r.sets {
+		sets = append(sets, copyRuleSet(set))
+	}
+	sort.Slice(sets, func(i, j int) bool {
+		if !sets[i].ImportedAt.Equal(sets[j].ImportedAt) {
+			return sets[i].ImportedAt.Before(sets[j].ImportedAt)
+		}
+		return sets[i].Name < sets[j].Name
+	})
+	return sets, nil
+}
+
+func (r *RuleSetRepository) Get(_ context.Context, id string) (domain.RuleSet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	set, ok := r.sets[id]
+	if !ok {
+		return domain.RuleSet{}, storage.ErrNotFound
+	}
+	return copyRuleSet(set), nil
+}
+
+func (r *RuleSetRepository) GetByName(_ context.Context, name string) (domain.RuleSet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, set := range r.sets {
+		if strings.EqualFold(set.Name, name) {
+			return copyRuleSet(set), nil
+		}
+	}
+	return domain.RuleSet{}, storage.ErrNotFound
+}
+
+func (r *RuleSetRepository) Save(_ context.Context, set domain.RuleSet) error {
+	if set.ID == "" {
+		return storage.ErrInvalidInput
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sets[set.ID] = copyRuleSet(set)
+	return nil
+}
+
+func (r *RuleSetRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sets[id]; !ok {
+		return storage.ErrNotFound
+	}
+	delete(r.sets, id)
+	return nil
+}
+
+func copyRuleSet(set domain.RuleSet) domain.RuleSet {
+	groupIDs := make(map[string]string, len(set.GroupIDs))
+	for key, id := range set.GroupIDs {
+		groupIDs[key] = id
+	}
+	set.GroupIDs = groupIDs
+	return set
+}
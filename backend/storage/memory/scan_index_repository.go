@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"rungrid/backend/domain"
+)
+
+type ScanIndexRepository struct {
+	mu      sync.RWMutex
+	entries map[string]domain.ScanIndexEntry
+}
+
+func NewScanIndexRepository() *ScanIndexRepository {
+	return &ScanIndexRepository{entries: make(map[string]domain.ScanIndexEntry)}
+}
+
+func (r *ScanIndexRepository) List(_ context.Context) ([]domain.ScanIndexEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]domain.ScanIndexEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (r *ScanIndexRepository) Save(_ context.Context, entries []domain.ScanIndexEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range entries {
+		r.entries[entry.Path] = entry
+	}
+	return nil
+}
+
+func (r *ScanIndexRepository) Delete(_ context.Context, paths []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, path := range paths {
+		delete(r.entries, path)
+	}
+	return nil
+}
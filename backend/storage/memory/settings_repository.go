@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/storage"
+)
+
+type SettingsRepository struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+func NewSettingsRepository() *SettingsRepository {
+	return &SettingsRepository{values: make(map[string]string)}
+}
+
+func (r *SettingsRepository) List(_ context.Context) ([]domain.Setting, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	settings := make([]domain.Setting, 0, len(r.values))
+	for key, value := range r.values {
+		settings = append(settings, domain.Setting{Key: key, Value: value})
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+	return settings, nil
+}
+
+func (r *SettingsRepository) Get(_ context.Context, key string) (domain.Setting, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	value, ok := r.values[key]
+	if !ok {
+		return domain.Setting{}, storage.ErrNotFound
+	}
+	return domain.Setting{Key: key, Value: value}, nil
+}
+
+func (r *SettingsRepository) Set(_ context.Context, setting domain.Setting) error {
+	if strings.TrimSpace(setting.Key) == "" {
+		return storage.ErrInvalidInput
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.values[setting.Key] = setting.Value
+	return nil
+}
+
+func (r *SettingsRepository) Delete(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.values, key)
+	return nil
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"rungrid/backend/storage"
+)
+
+// UnitOfWork gives the memory repositories transactions by snapshotting
+// them before fn runs and putting the snapshot back when fn fails. Units
+// run one at a time. Writes made outside a unit while it runs are not
+// isolated from it and are lost if it rolls back.
+type UnitOfWork struct {
+	mu         sync.Mutex
+	items      *ItemRepository
+	groups     *GroupRepository
+	launches   *LaunchRepository
+	operations *OperationRepository
+	ruleSets   *RuleSetRepository
+	scanIndex  *ScanIndexRepository
+}
+
+func NewUnitOfWork(items *ItemRepository, groups *GroupRepository, launches *LaunchRepository, operations *OperationRepository, ruleSets *RuleSetRepository, scanIndex *ScanIndexRepository) *UnitOfWork {
+	return &UnitOfWork{
+		items:      items,
+		groups:     groups,
+		launches:   launches,
+		operations: operations,
+		ruleSets:   ruleSets,
+		scanIndex:  scanIndex,
+	}
+}
+
+func (u *UnitOfWork) WithinTx(ctx context.Context, fn func(tx storage.Tx) error) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	restore := u.snapshot()
+	err := fn(storage.Tx{
+		Items:      u.items,
+		Groups:     u.groups,
+		Launches:   u.launches,
+		Operations: u.operations,
+		RuleSets:   u.ruleSets,
+		ScanIndex:  u.scanIndex,
+	})
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		restore()
+	}
+	return err
+}
+
+// snapshot copies the state of every repository and returns a function
+// that puts it back. Locks are taken in the order items, groups, launches,
+// like the other multi-repository writes.
+func (u *UnitOfWork) snapshot() func() {
+	u.items.mu.RLock()
+	items := maps.Clone(u.items.items)
+	u.items.mu.RUnlock()
+	u.groups.mu.RLock()
+	groups := maps.Clone(u.groups.groups)
+	u.groups.mu.RUnlock()
+	u.launches.mu.RLock()
+	launches := slices.Clone(u.launches.launches)
+	u.launches.mu.RUnlock()
+	u.operations.mu.RLock()
+	operations := slices.Clone(u.operations.operations)
+	u.operations.mu.RUnlock()
+	u.ruleSets.mu.RLock()
+	ruleSets := maps.Clone(u.ruleSets.sets)
+	u.ruleSets.mu.RUnlock()
+	u.scanIndex.mu.RLock()
+	scanIndex := maps.Clone(u.scanIndex.entries)
+	u.scanIndex.mu.RUnlock()
+
+	return func() {
+		u.items.mu.Lock()
+		u.items.items = items
+		u.items.mu.Unlock()
+		u.groups.mu.Lock()
+		u.groups.groups = groups
+		u.groups.mu.Unlock()
+		u.launches.mu.Lock()
+		u.launches.launches = launches
+		u.launches.mu.Unlock()
+		u.operations.mu.Lock()
+		u.operations.operations = operations
+		u.operations.mu.Unlock()
+		u.ruleSets.mu.Lock()
+		u.ruleSets.sets = ruleSets
+		u.ruleSets.mu.Unlock()
+		u.scanIndex.mu.Lock()
+		u.scanIndex.entries = scanIndex
+		u.scanIndex.mu.Unlock()
+	}
+}
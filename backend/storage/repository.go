@@ -9,14 +9,28 @@ import (
 
 type ItemFilter struct {
 	GroupID string
-	Query   string
+	// GroupIDs, when set, replaces GroupID and matches items in any of the
+	// groups, such as a group and its subgroups.
+	GroupIDs []string
+	// Query is matched by the search engine in ItemService; repositories
+	// ignore it.
+	Query string
+	// Smart is the query of a smart group. ItemService keeps the items it
+	// matches; repositories ignore it.
+	Smart string
 }
 
 type ItemRepository interface {
 	List(ctx context.Context, filter ItemFilter) ([]domain.Item, error)
+	// UpsertByPath writes a batch of validated inputs in one transaction.
+	// Inputs whose path matches an item, ignoring case, refresh its type
+	// and target name as UpsertItem does; the others are inserted. The
+	// outcomes are returned in input order.
+	UpsertByPath(ctx context.Context, inputs []domain.ItemInput) ([]domain.ItemUpsert, error)
 	Get(ctx context.Context, id string) (domain.Item, error)
 	GetByPath(ctx context.Context, path string) (domain.Item, error)
 	SetIconPath(ctx context.Context, id string, iconPath string) error
+	SetBroken(ctx context.Context, id string, reason domain.BrokenReason) error
 	Create(ctx context.Context, item domain.Item) (domain.Item, error)
 	Update(ctx context.Context, item domain.Item) (domain.Item, error)
 	Delete(ctx context.Context, id string) error
@@ -30,4 +44,85 @@ type GroupRepository interface {
 	Create(ctx context.Context, group domain.Group) (domain.Group, error)
 	Update(ctx context.Context, group domain.Group) (domain.Group, error)
 	Delete(ctx context.Context, id string) error
+	// DeleteWithItems deletes the group and, in the same transaction, moves
+	// its items to moveTo, where "" leaves them ungrouped, or deletes them
+	// with their launch history when deleteItems is set. Subgroups move up
+	// to the deleted group's parent.
+	DeleteWithItems(ctx context.Context, id string, moveTo string, deleteItems bool) error
+	// UngroupOrphans clears the group of items whose group no longer
+	// exists, and the parent of groups whose parent no longer exists. It
+	// returns how many items and groups it changed.
+	UngroupOrphans(ctx context.Context) (int, error)
+}
+
+type LaunchFilter struct {
+	ItemID string
+	Since  time.Time
+}
+
+type LaunchRepository interface {
+	Record(ctx context.Context, launch domain.Launch) error
+	List(ctx context.Context, filter LaunchFilter) ([]domain.Launch, error)
+	// Recent returns up to perItem of the latest successful launch times of
+	// every item.
+	Recent(ctx context.Context, perItem int) (map[string][]time.Time, error)
+	RecentForItem(ctx context.Context, itemID string, limit int) ([]time.Time, error)
+	DeleteByItem(ctx context.Context, itemID string) error
+	Clear(ctx context.Context) error
+}
+
+type SettingsRepository interface {
+	List(ctx context.Context) ([]domain.Setting, error)
+	Get(ctx context.Context, key string) (domain.Setting, error)
+	Set(ctx context.Context, setting domain.Setting) error
+	Delete(ctx context.Context, key string) error
+}
+
+// OperationRepository stores the undo journal. Undo writes every
+// before-image of an operation back in one transaction.
+type OperationRepository interface {
+	Create(ctx context.Context, operation domain.Operation, entries []domain.OperationEntry) error
+	List(ctx context.Context, limit int) ([]domain.Operation, error)
+	Get(ctx context.Context, id string) (domain.Operation, error)
+	Entries(ctx context.Context, id string) ([]domain.OperationEntry, error)
+	Undo(ctx context.Context, id string, undoneAt time.Time) (domain.Operation, error)
+	// Prune drops all but the newest keep operations.
+	Prune(ctx context.Context, keep int) error
+}
+
+type RuleSetRepository interface {
+	// List returns rule sets in import order.
+	List(ctx context.Context) ([]domain.RuleSet, error)
+	Get(ctx context.Context, id string) (domain.RuleSet, error)
+	GetByName(ctx context.Context, name string) (domain.RuleSet, error)
+	// Save inserts or replaces the rule set with the same id.
+	Save(ctx context.Context, set domain.RuleSet) error
+	Delete(ctx context.Context, id string) error
+}
+
+// ScanIndexRepository keeps the file state of the last scan of each path.
+type ScanIndexRepository interface {
+	List(ctx context.Context) ([]domain.ScanIndexEntry, error)
+	// Save inserts or replaces the entries with the same paths.
+	Save(ctx context.Context, entries []domain.ScanIndexEntry) error
+	Delete(ctx context.Context, paths []string) error
+}
+
+// Tx holds repositories that share one transaction. What is written through
+// them is committed together when the WithinTx function returns nil and
+// discarded when it returns an error.
+type Tx struct {
+	Items      ItemRepository
+	Groups     GroupRepository
+	Launches   LaunchRepository
+	Operations OperationRepository
+	RuleSets   RuleSetRepository
+	ScanIndex  ScanIndexRepository
+}
+
+// UnitOfWork runs multi-step writes atomically. fn must only use the
+// repositories in tx: the sqlite backend has a single connection, which
+// the transaction holds until fn returns.
+type UnitOfWork interface {
+	WithinTx(ctx context.Context, fn func(tx Tx) error) error
 }
@@ -9,7 +9,7 @@ import (
 )
 
 type GroupRepository struct {
-	db *sql.DB
+	db queryer
 }
 
 func NewGroupRepository(db *sql.DB) *GroupRepository {
@@ -18,7 +18,7 @@ func NewGroupRepository(db *sql.DB) *GroupRepository {
 
 func (r *GroupRepository) List(ctx context.Context) ([]domain.Group, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, name, display_order, color, category, icon
+		SELECT id, parent_id, name, display_order, color, category, icon, kind, query
 		FROM groups
 		ORDER BY display_order ASC, name ASC
 	`)
@@ -30,7 +30,7 @@ func (r *GroupRepository) List(ctx context.Context) ([]domain.Group, error) {
 	groups := []domain.Group{}
 	for rows.Next() {
 		var group domain.Group
-		if err := rows.Scan(&group.ID, &group.Name, &group.Order, &group.Color, &group.Category, &group.Icon); err != nil {
+		if err := rows.Scan(&group.ID, &group.ParentID, &group.Name, &group.Order, &group.Color, &group.Category, &group.Icon, &group.Kind, &group.Query); err != nil {
 			return nil, err
 		}
 		groups = append(groups, group)
@@ -45,12 +45,12 @@ func (r *GroupRepository) List(ctx context.Context) ([]domain.Group, error) {
 
 func (r *GroupRepository) Get(ctx context.Context, id string) (domain.Group, error) {
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, name, display_order, color, category, icon
+		SELECT id, parent_id, name, display_order, color, category, icon, kind, query
 		FROM groups WHERE id = ?
 	`, id)
 
 	var group domain.Group
-	if err := row.Scan(&group.ID, &group.Name, &group.Order, &group.Color, &group.Category, &group.Icon); err != nil {
+	if err := row.Scan(&group.ID, &group.ParentID, &group.Name, &group.Order, &group.Color, &group.Category, &group.Icon, &group.Kind, &group.Query); err != nil {
 		if err == sql.ErrNoRows {
 			return domain.Group{}, storage.ErrNotFound
 		}
@@ -62,9 +62,9 @@ func (r *GroupRepository) Get(ctx context.Context, id string) (domain.Group, err
 
 func (r *GroupRepository) Create(ctx context.Context, group domain.Group) (domain.Group, error) {
 	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO groups (id, name, display_order, color, category, icon)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, group.ID, group.Name, group.Order, group.Color, group.Category, group.Icon)
+		INSERT INTO groups (id, parent_id, name, display_order, color, category, icon, kind, query)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, group.ID, group.ParentID, group.Name, group.Order, group.Color, group.Category, group.Icon, string(group.Kind), group.Query)
 	if err != nil {
 		return domain.Group{}, err
 	}
@@ -74,9 +74,9 @@ func (r *GroupRepository) Create(ctx context.Context, group domain.Group) (domai
 
 func (r *GroupRepository) Update(ctx context.Context, group domain.Group) (domain.Group, error) {
 	result, err := r.db.ExecContext(ctx, `
-		UPDATE groups SET name = ?, display_order = ?, color = ?, category = ?, icon = ?
+		UPDATE groups SET parent_id = ?, name = ?, display_order = ?, color = ?, category = ?, icon = ?, kind = ?, query = ?
 		WHERE id = ?
-	`, group.Name, group.Order, group.Color, group.Category, group.Icon, group.ID)
+	`, group.ParentID, group.Name, group.Order, group.Color, group.Category, group.Icon, string(group.Kind), group.Query, group.ID)
 	if err != nil {
 		return domain.Group{}, err
 	}
@@ -108,3 +108,85 @@ func (r *GroupRepository) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+func (r *GroupRepository) DeleteWithItems(ctx context.Context, id string, moveTo string, deleteItems bool) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if moveTo != "" && !deleteItems {
+		var exists int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(1) FROM groups WHERE id = ?", moveTo).Scan(&exists); err != nil {
+			return err
+		}
+		if exists == 0 {
+			return storage.ErrNotFound
+		}
+	}
+
+	if deleteItems {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM launches WHERE item_id IN (SELECT id FROM items WHERE group_id = ?)", id); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM items WHERE group_id = ?", id); err != nil {
+			return err
+		}
+	} else {
+		if _, err := tx.ExecContext(ctx, "UPDATE items SET group_id = ? WHERE group_id = ?", moveTo, id); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE groups SET parent_id = (SELECT parent_id FROM groups WHERE id = ?)
+		WHERE parent_id = ?
+	`, id, id); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM groups WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return storage.ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+func (r *GroupRepository) UngroupOrphans(ctx context.Context) (int, error) {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	changed := 0
+	for _, statement := range []string{
+		`UPDATE items SET group_id = ''
+		WHERE group_id <> '' AND group_id NOT IN (SELECT id FROM groups)`,
+		`UPDATE groups SET parent_id = ''
+		WHERE parent_id <> '' AND parent_id NOT IN (SELECT id FROM groups)`,
+	} {
+		result, err := tx.ExecContext(ctx, statement)
+		if err != nil {
+			return 0, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		changed += int(affected)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
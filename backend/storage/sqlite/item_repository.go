@@ -12,7 +12,7 @@ import (
 )
 
 type ItemRepository struct {
-	db *sql.DB
+	db queryer
 }
 
 func NewItemRepository(db *sql.DB) *ItemRepository {
@@ -21,20 +21,21 @@ func NewItemRepository(db *sql.DB) *ItemRepository {
 
 func (r *ItemRepository) List(ctx context.Context, filter storage.ItemFilter) ([]domain.Item, error) {
 	query := `
-		SELECT id, name, path, target_name, type, icon_path, group_id, tags, favorite, launch_count, last_used_at, hidden
+		SELECT id, name, path, target_name, type, icon_path, group_id, tags, favorite, launch_count, last_used_at, hidden, broken
 		FROM items
 	`
 	args := []interface{}{}
 	conditions := []string{}
 
-	if filter.GroupID != "" && filter.GroupID != "all" {
+	if len(filter.GroupIDs) > 0 {
+		conditions = append(conditions, "group_id IN (?"+strings.Repeat(", ?", len(filter.GroupIDs)-1)+")")
+		for _, id := range filter.GroupIDs {
+			args = append(args, id)
+		}
+	} else if filter.GroupID != "" && filter.GroupID != "all" {
 		conditions = append(conditions, "group_id = ?")
 		args = append(args, filter.GroupID)
 	}
-	if strings.TrimSpace(filter.Query) != "" {
-		conditions = append(conditions, "LOWER(name) LIKE '%' || ? || '%' ")
-		args = append(args, strings.ToLower(strings.TrimSpace(filter.Query)))
-	}
 
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
@@ -65,7 +66,7 @@ func (r *ItemRepository) List(ctx context.Context, filter storage.ItemFilter) ([
 
 func (r *ItemRepository) Get(ctx context.Context, id string) (domain.Item, error) {
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, name, path, target_name, type, icon_path, group_id, tags, favorite, launch_count, last_used_at, hidden
+		SELECT id, name, path, target_name, type, icon_path, group_id, tags, favorite, launch_count, last_used_at, hidden, broken
 		FROM items WHERE id = ?
 	`, id)
 
@@ -82,7 +83,7 @@ func (r *ItemRepository) Get(ctx context.Context, id string) (domain.Item, error
 
 func (r *ItemRepository) GetByPath(ctx context.Context, path string) (domain.Item, error) {
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, name, path, target_name, type, icon_path, group_id, tags, favorite, launch_count, last_used_at, hidden
+		SELECT id, name, path, target_name, type, icon_path, group_id, tags, favorite, launch_count, last_used_at, hidden, broken
 		FROM items WHERE LOWER(path) = LOWER(?)
 	`, path)
 
@@ -114,6 +115,23 @@ func (r *ItemRepository) SetIconPath(ctx context.Context, id string, iconPath st
 	return nil
 }
 
+func (r *ItemRepository) SetBroken(ctx context.Context, id string, reason domain.BrokenReason) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE items SET broken = ? WHERE id = ?", string(reason), id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return storage.ErrNotFound
+	}
+
+	return nil
+}
+
 func (r *ItemRepository) Create(ctx context.Context, item domain.Item) (domain.Item, error) {
 	tags, err := encodeTags(item.Tags)
 	if err != nil {
@@ -122,8 +140,8 @@ func (r *ItemRepository) Create(ctx context.Context, item domain.Item) (domain.I
 
 	_, err = r.db.ExecContext(ctx, `
 		INSERT INTO items (
-			id, name, path, target_name, type, icon_path, group_id, tags, favorite, launch_count, last_used_at, hidden
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			id, name, path, target_name, type, icon_path, group_id, tags, favorite, launch_count, last_used_at, hidden, broken
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		item.ID,
 		item.Name,
@@ -137,6 +155,7 @@ func (r *ItemRepository) Create(ctx context.Context, item domain.Item) (domain.I
 		item.LaunchCount,
 		timeToUnix(item.LastUsedAt),
 		boolToInt(item.Hidden),
+		string(item.Broken),
 	)
 	if err != nil {
 		return domain.Item{}, err
@@ -145,6 +164,97 @@ func (r *ItemRepository) Create(ctx context.Context, item domain.Item) (domain.I
 	return item, nil
 }
 
+// UpsertByPath looks up, inserts and updates through prepared statements
+// in one transaction, which turns a scan of thousands of shortcuts into a
+// single commit.
+func (r *ItemRepository) UpsertByPath(ctx context.Context, inputs []domain.ItemInput) ([]domain.ItemUpsert, error) {
+	results := make([]domain.ItemUpsert, 0, len(inputs))
+	if len(inputs) == 0 {
+		return results, nil
+	}
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	find, err := tx.PrepareContext(ctx, `
+		SELECT id, name, path, target_name, type, icon_path, group_id, tags, favorite, launch_count, last_used_at, hidden, broken
+		FROM items WHERE LOWER(path) = LOWER(?) LIMIT 1
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer find.Close()
+	insert, err := tx.PrepareContext(ctx, `
+		INSERT INTO items (
+			id, name, path, target_name, type, icon_path, group_id, tags, favorite, launch_count, last_used_at, hidden, broken
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer insert.Close()
+	update, err := tx.PrepareContext(ctx, "UPDATE items SET type = ?, target_name = ? WHERE id = ?")
+	if err != nil {
+		return nil, err
+	}
+	defer update.Close()
+
+	for _, input := range inputs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		existing, err := scanItem(find.QueryRowContext(ctx, input.Path))
+		if err == sql.ErrNoRows {
+			item := storage.NewItem(input)
+			tags, err := encodeTags(item.Tags)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := insert.ExecContext(ctx,
+				item.ID,
+				item.Name,
+				item.Path,
+				item.TargetName,
+				string(item.Type),
+				item.IconPath,
+				item.GroupID,
+				tags,
+				boolToInt(item.Favorite),
+				item.LaunchCount,
+				timeToUnix(item.LastUsedAt),
+				boolToInt(item.Hidden),
+				string(item.Broken),
+			); err != nil {
+				return nil, err
+			}
+			results = append(results, domain.ItemUpsert{Outcome: domain.UpsertInserted, Item: item})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		updated, changed := storage.UpsertItem(existing, input)
+		if !changed {
+			results = append(results, domain.ItemUpsert{Outcome: domain.UpsertUnchanged, Item: existing})
+			continue
+		}
+		if _, err := update.ExecContext(ctx, string(updated.Type), updated.TargetName, updated.ID); err != nil {
+			return nil, err
+		}
+		results = append(results, domain.ItemUpsert{Outcome: domain.UpsertUpdated, Item: updated, Before: &existing})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
 func (r *ItemRepository) Update(ctx context.Context, item domain.Item) (domain.Item, error) {
 	tags, err := encodeTags(item.Tags)
 	if err != nil {
@@ -163,7 +273,8 @@ func (r *ItemRepository) Update(ctx context.Context, item domain.Item) (domain.I
 			favorite = ?,
 			launch_count = ?,
 			last_used_at = ?,
-			hidden = ?
+			hidden = ?,
+			broken = ?
 		WHERE id = ?
 	`,
 		item.Name,
@@ -177,6 +288,7 @@ func (r *ItemRepository) Update(ctx context.Context, item domain.Item) (domain.I
 		item.LaunchCount,
 		timeToUnix(item.LastUsedAt),
 		boolToInt(item.Hidden),
+		string(item.Broken),
 		item.ID,
 	)
 	if err != nil {
@@ -260,6 +372,7 @@ func scanItem(scanner itemScanner) (domain.Item, error) {
 		favorite int
 		hidden   int
 		lastUsed sql.NullInt64
+		broken   string
 	)
 
 	err := scanner.Scan(
@@ -275,6 +388,7 @@ func scanItem(scanner itemScanner) (domain.Item, error) {
 		&item.LaunchCount,
 		&lastUsed,
 		&hidden,
+		&broken,
 	)
 	if err != nil {
 		return domain.Item{}, err
@@ -284,6 +398,7 @@ func scanItem(scanner itemScanner) (domain.Item, error) {
 	item.TargetName = targetName
 	item.Favorite = favorite == 1
 	item.Hidden = hidden == 1
+	item.Broken = domain.BrokenReason(broken)
 	if lastUsed.Valid {
 		usedAt := time.Unix(lastUsed.Int64, 0)
 		item.LastUsedAt = &usedAt
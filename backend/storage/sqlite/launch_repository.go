@@ -0,0 +1,153 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/storage"
+)
+
+type LaunchRepository struct {
+	db queryer
+}
+
+func NewLaunchRepository(db *sql.DB) *LaunchRepository {
+	return &LaunchRepository{db: db}
+}
+
+func (r *LaunchRepository) Record(ctx context.Context, launch domain.Launch) error {
+	if launch.ItemID == "" {
+		return storage.ErrInvalidInput
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO launches (item_id, launched_at, source, success, error) VALUES (?, ?, ?, ?, ?)
+	`, launch.ItemID, launch.LaunchedAt.UnixMilli(), string(launch.Source), boolToInt(launch.Success), launch.Error)
+	return err
+}
+
+func (r *LaunchRepository) List(ctx context.Context, filter storage.LaunchFilter) ([]domain.Launch, error) {
+	query := `
+		SELECT item_id, launched_at, source, success, error
+		FROM launches
+	`
+	args := []interface{}{}
+	conditions := []string{}
+
+	if filter.ItemID != "" {
+		conditions = append(conditions, "item_id = ?")
+		args = append(args, filter.ItemID)
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "launched_at >= ?")
+		args = append(args, filter.Since.UnixMilli())
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY launched_at DESC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	launches := []domain.Launch{}
+	for rows.Next() {
+		var (
+			launch     domain.Launch
+			launchedAt int64
+			source     string
+			success    int
+		)
+		if err := rows.Scan(&launch.ItemID, &launchedAt, &source, &success, &launch.Error); err != nil {
+			return nil, err
+		}
+		launch.LaunchedAt = time.UnixMilli(launchedAt)
+		launch.Source = domain.LaunchSource(source)
+		launch.Success = success != 0
+		launches = append(launches, launch)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return launches, nil
+}
+
+func (r *LaunchRepository) Recent(ctx context.Context, perItem int) (map[string][]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT item_id, launched_at FROM (
+			SELECT item_id, launched_at,
+				ROW_NUMBER() OVER (PARTITION BY item_id ORDER BY launched_at DESC) AS position
+			FROM launches
+			WHERE success = 1
+		)
+		WHERE position <= ?
+	`, perItem)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]time.Time)
+	for rows.Next() {
+		var (
+			itemID     string
+			launchedAt int64
+		)
+		if err := rows.Scan(&itemID, &launchedAt); err != nil {
+			return nil, err
+		}
+		result[itemID] = append(result[itemID], time.UnixMilli(launchedAt))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *LaunchRepository) RecentForItem(ctx context.Context, itemID string, limit int) ([]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT launched_at FROM launches
+		WHERE item_id = ? AND success = 1
+		ORDER BY launched_at DESC
+		LIMIT ?
+	`, itemID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []time.Time{}
+	for rows.Next() {
+		var launchedAt int64
+		if err := rows.Scan(&launchedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, time.UnixMilli(launchedAt))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *LaunchRepository) DeleteByItem(ctx context.Context, itemID string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM launches WHERE item_id = ?", itemID)
+	return err
+}
+
+func (r *LaunchRepository) Clear(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM launches")
+	return err
+}
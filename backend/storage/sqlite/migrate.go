@@ -0,0 +1,292 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// ErrSchemaTooNew is returned when the database was written by a newer
+// build than the running one.
+var ErrSchemaTooNew = errors.New("database schema is newer than this build")
+
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// MigrationResult reports what Migrate did. BackupPath is empty when no
+// backup was needed.
+type MigrationResult struct {
+	From       int
+	To         int
+	BackupPath string
+}
+
+// Migrations returns the embedded up-migrations ordered by version. Files
+// are named NNNN_description.sql.
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		prefix, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: missing description", entry.Name())
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version", entry.Name())
+		}
+		data, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(data)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration %d: versions must be consecutive from 1", migration.Version)
+		}
+	}
+	return migrations, nil
+}
+
+// LatestSchemaVersion is the schema version this build migrates to.
+func LatestSchemaVersion() int {
+	migrations, err := Migrations()
+	if err != nil || len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersion returns the version recorded in db, or 0 for a database
+// that has never been migrated.
+func SchemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	exists, err := tableExists(ctx, db, "schema_version")
+	if err != nil || !exists {
+		return 0, err
+	}
+	var version sql.NullInt64
+	if err := db.QueryRowContext(ctx, "SELECT MAX(version) FROM schema_version").Scan(&version); err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+// Migrate applies pending migrations, each in its own transaction. Databases
+// created before versioning are adopted by detecting which migrations their
+// tables already reflect.
+func Migrate(ctx context.Context, db *sql.DB, backupDir string) (MigrationResult, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return MigrationResult{}, err
+	}
+	latest := 0
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].Version
+	}
+
+	current, err := SchemaVersion(ctx, db)
+	if err != nil {
+		return MigrationResult{}, err
+	}
+	adopted := 0
+	if current == 0 {
+		adopted, err = legacySchemaVersion(ctx, db, migrations)
+		if err != nil {
+			return MigrationResult{}, err
+		}
+		current = adopted
+	}
+
+	result := MigrationResult{From: current, To: current}
+	if current > latest {
+		return result, fmt.Errorf("%w: database is at version %d, this build supports up to %d", ErrSchemaTooNew, current, latest)
+	}
+
+	// The backup is taken before anything is written, including the version
+	// table and the rows adopting a legacy database, so it is the file as
+	// the previous build left it.
+	if current > 0 && current < latest && backupDir != "" {
+		result.BackupPath, err = backupBeforeMigration(ctx, db, backupDir, current)
+		if err != nil {
+			return result, fmt.Errorf("backup before migration: %w", err)
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at INTEGER NOT NULL
+		)
+	`); err != nil {
+		return result, err
+	}
+	if adopted > 0 {
+		if err := adoptLegacySchema(ctx, db, migrations[:adopted]); err != nil {
+			return result, err
+		}
+	}
+
+	for _, migration := range migrations[current:] {
+		if err := applyMigration(ctx, db, migration); err != nil {
+			return result, fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		result.To = migration.Version
+	}
+	return result, nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, migration Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.SQL); err != nil {
+		return err
+	}
+	if err := recordVersion(ctx, tx, migration); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func recordVersion(ctx context.Context, tx *sql.Tx, migration Migration) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)
+	`, migration.Version, migration.Name, time.Now().Unix())
+	return err
+}
+
+// legacyChecks report whether an unversioned database already contains the
+// changes of the migration with the same index (version = index + 1). They
+// mirror the column patching done before migrations existed and never need
+// to grow.
+var legacyChecks = []func(columns map[string]map[string]bool) bool{
+	func(columns map[string]map[string]bool) bool { return columns["items"] != nil },
+	func(columns map[string]map[string]bool) bool { return columns["items"]["target_name"] },
+	func(columns map[string]map[string]bool) bool {
+		return columns["groups"]["category"] && columns["groups"]["icon"]
+	},
+}
+
+// legacySchemaVersion returns the version an unversioned database already
+// matches, without writing anything.
+func legacySchemaVersion(ctx context.Context, db *sql.DB, migrations []Migration) (int, error) {
+	columns := map[string]map[string]bool{}
+	for _, table := range []string{"items", "groups"} {
+		names, err := tableColumns(ctx, db, table)
+		if err != nil {
+			return 0, err
+		}
+		if len(names) > 0 {
+			columns[table] = names
+		}
+	}
+
+	version := 0
+	for version < len(legacyChecks) && version < len(migrations) && legacyChecks[version](columns) {
+		version++
+	}
+	return version, nil
+}
+
+// adoptLegacySchema records migrations as applied without running them.
+func adoptLegacySchema(ctx context.Context, db *sql.DB, migrations []Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, migration := range migrations {
+		if err := recordVersion(ctx, tx, migration); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// backupBeforeMigration snapshots the database with VACUUM INTO, which
+// produces a consistent copy even while the connection is open.
+func backupBeforeMigration(ctx context.Context, db *sql.DB, dir string, version int) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("rungrid-v%d-%s.db", version, time.Now().Format("20060102-150405")))
+	if err := VacuumInto(ctx, db, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// VacuumInto writes a compacted, consistent copy of db to path, which must
+// not exist yet.
+func VacuumInto(ctx context.Context, db *sql.DB, path string) error {
+	_, err := db.ExecContext(ctx, "VACUUM INTO ?", path)
+	return err
+}
+
+func tableExists(ctx context.Context, db *sql.DB, name string) (bool, error) {
+	var count int
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count)
+	return count > 0, err
+}
+
+func tableColumns(ctx context.Context, db *sql.DB, table string) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, "PRAGMA table_info("+table+")")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := map[string]bool{}
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
@@ -0,0 +1,3 @@
+ALTER TABLE items ADD COLUMN target_name TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_items_target_name ON items(target_name);
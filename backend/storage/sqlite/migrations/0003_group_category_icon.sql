@@ -0,0 +1,2 @@
+ALTER TABLE groups ADD COLUMN category TEXT NOT NULL DEFAULT 'app';
+ALTER TABLE groups ADD COLUMN icon TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS launches (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	item_id TEXT NOT NULL,
+	launched_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_launches_item ON launches(item_id, launched_at);
+CREATE INDEX IF NOT EXISTS idx_launches_time ON launches(launched_at);
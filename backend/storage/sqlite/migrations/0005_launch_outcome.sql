@@ -0,0 +1,3 @@
+ALTER TABLE launches ADD COLUMN source TEXT NOT NULL DEFAULT '';
+ALTER TABLE launches ADD COLUMN success INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE launches ADD COLUMN error TEXT NOT NULL DEFAULT '';
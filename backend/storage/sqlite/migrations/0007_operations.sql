@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS operations (
+	id TEXT PRIMARY KEY,
+	kind TEXT NOT NULL,
+	summary TEXT NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL,
+	undone_at INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_operations_created ON operations(created_at);
+
+CREATE TABLE IF NOT EXISTS operation_entries (
+	operation_id TEXT NOT NULL,
+	seq INTEGER NOT NULL,
+	entity TEXT NOT NULL,
+	entity_id TEXT NOT NULL,
+	data TEXT NOT NULL,
+	PRIMARY KEY (operation_id, seq)
+);
+
+CREATE INDEX IF NOT EXISTS idx_operation_entries_entity ON operation_entries(entity, entity_id);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS rule_sets (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE,
+	enabled INTEGER NOT NULL DEFAULT 1,
+	data TEXT NOT NULL,
+	group_ids TEXT NOT NULL DEFAULT '{}',
+	imported_at INTEGER NOT NULL
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS scan_index (
+	path TEXT PRIMARY KEY,
+	size INTEGER NOT NULL,
+	mod_time INTEGER NOT NULL,
+	target TEXT NOT NULL DEFAULT '',
+	arguments TEXT NOT NULL DEFAULT ''
+);
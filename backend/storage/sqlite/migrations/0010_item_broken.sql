@@ -0,0 +1 @@
+ALTER TABLE items ADD COLUMN broken TEXT NOT NULL DEFAULT '';
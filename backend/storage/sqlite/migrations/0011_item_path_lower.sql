@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_items_path_lower ON items(LOWER(path));
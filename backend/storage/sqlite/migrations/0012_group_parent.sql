@@ -0,0 +1,3 @@
+ALTER TABLE groups ADD COLUMN parent_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_groups_parent ON groups(parent_id);
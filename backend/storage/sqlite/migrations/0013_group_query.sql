@@ -0,0 +1,3 @@
+ALTER TABLE groups ADD COLUMN kind TEXT NOT NULL DEFAULT 'manual';
+
+ALTER TABLE groups ADD COLUMN query TEXT NOT NULL DEFAULT '';
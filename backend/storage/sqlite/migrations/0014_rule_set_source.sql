@@ -0,0 +1 @@
+ALTER TABLE rule_sets ADD COLUMN source TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,277 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/storage"
+)
+
+type OperationRepository struct {
+	db queryer
+}
+
+func NewOperationRepository(db *sql.DB) *OperationRepository {
+	return &OperationRepository{db: db}
+}
+
+func (r *OperationRepository) Create(ctx context.Context, operation domain.Operation, entries []domain.OperationEntry) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO operations (id, kind, summary, created_at) VALUES (?, ?, ?, ?)
+	`, operation.ID, string(operation.Kind), operation.Summary, operation.CreatedAt.UnixMilli()); err != nil {
+		return err
+	}
+	for seq, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO operation_entries (operation_id, seq, entity, entity_id, data) VALUES (?, ?, ?, ?, ?)
+		`, operation.ID, seq, string(entry.Entity), entry.EntityID, string(data)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const operationColumns = `
+	SELECT o.id, o.kind, o.summary, o.created_at, o.undone_at,
+		(SELECT COUNT(*) FROM operation_entries e WHERE e.operation_id = o.id AND e.entity = 'item'),
+		(SELECT COUNT(*) FROM operation_entries e WHERE e.operation_id = o.id AND e.entity = 'group')
+	FROM operations o
+`
+
+func (r *OperationRepository) List(ctx context.Context, limit int) ([]domain.Operation, error) {
+	query := operationColumns + " ORDER BY o.created_at DESC, o.rowid DESC"
+	args := []interface{}{}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	operations := []domain.Operation{}
+	for rows.Next() {
+		operation, err := scanOperation(rows)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, operation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return operations, nil
+}
+
+func (r *OperationRepository) Get(ctx context.Context, id string) (domain.Operation, error) {
+	operation, err := scanOperation(r.db.QueryRowContext(ctx, operationColumns+" WHERE o.id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Operation{}, storage.ErrNotFound
+		}
+		return domain.Operation{}, err
+	}
+	return operation, nil
+}
+
+func (r *OperationRepository) Entries(ctx context.Context, id string) ([]domain.OperationEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT data FROM operation_entries WHERE operation_id = ? ORDER BY seq ASC
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.OperationEntry{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var entry domain.OperationEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *OperationRepository) Undo(ctx context.Context, id string, undoneAt time.Time) (domain.Operation, error) {
+	entries, err := r.Entries(ctx, id)
+	if err != nil {
+		return domain.Operation{}, err
+	}
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return domain.Operation{}, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "UPDATE operations SET undone_at = ? WHERE id = ? AND undone_at IS NULL", undoneAt.UnixMilli(), id)
+	if err != nil {
+		return domain.Operation{}, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return domain.Operation{}, err
+	}
+	if affected == 0 {
+		return domain.Operation{}, storage.ErrNotFound
+	}
+
+	for index := len(entries) - 1; index >= 0; index-- {
+		if err := restoreEntry(ctx, tx, entries[index]); err != nil {
+			return domain.Operation{}, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Operation{}, err
+	}
+	return r.Get(ctx, id)
+}
+
+func (r *OperationRepository) Prune(ctx context.Context, keep int) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM operations WHERE id NOT IN (
+			SELECT id FROM operations ORDER BY created_at DESC, rowid DESC LIMIT ?
+		)
+	`, keep); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM operation_entries WHERE operation_id NOT IN (SELECT id FROM operations)
+	`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// restoreEntry writes one before-image back. Launch counters are left as
+// they are for items that still exist, so launches made after the
+// operation are not lost.
+func restoreEntry(ctx context.Context, tx queryer, entry domain.OperationEntry) error {
+	switch entry.Entity {
+	case domain.OperationEntityItem:
+		if entry.Item == nil {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM launches WHERE item_id = ?", entry.EntityID); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, "DELETE FROM items WHERE id = ?", entry.EntityID)
+			return err
+		}
+		item := entry.Item
+		tags, err := encodeTags(item.Tags)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO items (
+				id, name, path, target_name, type, icon_path, group_id, tags, favorite, launch_count, last_used_at, hidden, broken
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET
+				name = excluded.name,
+				path = excluded.path,
+				target_name = excluded.target_name,
+				type = excluded.type,
+				icon_path = excluded.icon_path,
+				group_id = excluded.group_id,
+				tags = excluded.tags,
+				favorite = excluded.favorite,
+				hidden = excluded.hidden,
+				broken = excluded.broken
+		`,
+			item.ID,
+			item.Name,
+			item.Path,
+			item.TargetName,
+			string(item.Type),
+			item.IconPath,
+			item.GroupID,
+			tags,
+			boolToInt(item.Favorite),
+			item.LaunchCount,
+			timeToUnix(item.LastUsedAt),
+			boolToInt(item.Hidden),
+			string(item.Broken),
+		); err != nil {
+			return err
+		}
+		for _, launch := range entry.Launches {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO launches (item_id, launched_at, source, success, error) VALUES (?, ?, ?, ?, ?)
+			`, launch.ItemID, launch.LaunchedAt.UnixMilli(), string(launch.Source), boolToInt(launch.Success), launch.Error); err != nil {
+				return err
+			}
+		}
+		return nil
+	case domain.OperationEntityGroup:
+		if entry.Group == nil {
+			_, err := tx.ExecContext(ctx, "DELETE FROM groups WHERE id = ?", entry.EntityID)
+			return err
+		}
+		group := entry.Group
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO groups (id, parent_id, name, display_order, color, category, icon, kind, query)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET
+				parent_id = excluded.parent_id,
+				name = excluded.name,
+				display_order = excluded.display_order,
+				color = excluded.color,
+				category = excluded.category,
+				icon = excluded.icon,
+				kind = excluded.kind,
+				query = excluded.query
+		`, group.ID, group.ParentID, group.Name, group.Order, group.Color, group.Category, group.Icon, string(group.Kind), group.Query)
+		return err
+	default:
+		return storage.ErrInvalidInput
+	}
+}
+
+func scanOperation(scanner itemScanner) (domain.Operation, error) {
+	var (
+		operation domain.Operation
+		kind      string
+		createdAt int64
+		undoneAt  sql.NullInt64
+	)
+	if err := scanner.Scan(&operation.ID, &kind, &operation.Summary, &createdAt, &undoneAt, &operation.Items, &operation.Groups); err != nil {
+		return domain.Operation{}, err
+	}
+	operation.Kind = domain.OperationKind(kind)
+	operation.CreatedAt = time.UnixMilli(createdAt)
+	if undoneAt.Valid {
+		value := time.UnixMilli(undoneAt.Int64)
+		operation.UndoneAt = &value
+	}
+	return operation, nil
+}
@@ -0,0 +1,147 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// CountRows returns the number of rows in table, or 0 when the table does
+// not exist.
+func CountRows(ctx context.Context, db *sql.DB, table string) (int, error) {
+	exists, err := tableExists(ctx, db, table)
+	if err != nil || !exists {
+		return 0, err
+	}
+	var count int
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+quoteIdentifier(table)).Scan(&count)
+	return count, err
+}
+
+// RestoreFrom replaces every table of db with the contents of the database
+// file at path. The file is migrated to the current schema first, so it must
+// be a scratch copy. All tables are replaced in one transaction.
+func RestoreFrom(ctx context.Context, db *sql.DB, path string) error {
+	source, err := Open(path)
+	if err != nil {
+		return err
+	}
+	_, err = Migrate(ctx, source, "")
+	closeErr := source.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// ATTACH is not allowed inside a transaction, so the connection is
+	// pinned and the backup attached first.
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS restore_source", path); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "DETACH DATABASE restore_source")
+
+	tables, err := userTables(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range tables {
+		if err := restoreTable(ctx, tx, table); err != nil {
+			return fmt.Errorf("restore %s: %w", table, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func restoreTable(ctx context.Context, tx *sql.Tx, table string) error {
+	name := quoteIdentifier(table)
+	if _, err := tx.ExecContext(ctx, "DELETE FROM main."+name); err != nil {
+		return err
+	}
+
+	mainColumns, err := pragmaColumns(ctx, tx, "main", table)
+	if err != nil {
+		return err
+	}
+	sourceColumns, err := pragmaColumns(ctx, tx, "restore_source", table)
+	if err != nil {
+		return err
+	}
+	available := map[string]bool{}
+	for _, column := range sourceColumns {
+		available[column] = true
+	}
+
+	shared := []string{}
+	for _, column := range mainColumns {
+		if available[column] {
+			shared = append(shared, quoteIdentifier(column))
+		}
+	}
+	if len(shared) == 0 {
+		return nil
+	}
+
+	columns := strings.Join(shared, ", ")
+	_, err = tx.ExecContext(ctx, "INSERT INTO main."+name+" ("+columns+") SELECT "+columns+" FROM restore_source."+name)
+	return err
+}
+
+func userTables(ctx context.Context, db queryer) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT name FROM main.sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'schema_version'
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+func pragmaColumns(ctx context.Context, db queryer, schema, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM pragma_table_info(?, ?)", table, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+	return columns, rows.Err()
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
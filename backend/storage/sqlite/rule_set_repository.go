@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/storage"
+)
+
+type RuleSetRepository struct {
+	db queryer
+}
+
+func NewRuleSetRepository(db *sql.DB) *RuleSetRepository {
+	return &RuleSetRepository{db: db}
+}
+
+const ruleSetColumns = `SELECT id, name, source, enabled, data, group_ids, imported_at FROM rule_sets`
+
+func (r *RuleSetRepository) List(ctx context.Context) ([]domain.RuleSet, error) {
+	rows, err := r.db.QueryContext(ctx, ruleSetColumns+" ORDER BY imported_at ASC, name ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sets := []domain.RuleSet{}
+	for rows.Next() {
+		set, err := scanRuleSet(rows)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return sets, nil
+}
+
+func (r *RuleSetRepository) Get(ctx context.Context, id string) (domain.RuleSet, error) {
+	return getRuleSet(r.db.QueryRowContext(ctx, ruleSetColumns+" WHERE id = ?", id))
+}
+
+func (r *RuleSetRepository) GetByName(ctx context.Context, name string) (domain.RuleSet, error) {
+	return getRuleSet(r.db.QueryRowContext(ctx, ruleSetColumns+" WHERE LOWER(name) = LOWER(?)", name))
+}
+
+func getRuleSet(row *sql.Row) (domain.RuleSet, error) {
+	set, err := scanRuleSet(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.RuleSet{}, storage.ErrNotFound
+		}
+		return domain.RuleSet{}, err
+	}
+	return set, nil
+}
+
+func (r *RuleSetRepository) Save(ctx context.Context, set domain.RuleSet) error {
+	groupIDs, err := json.Marshal(set.GroupIDs)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO rule_sets (id, name, source, enabled, data, group_ids, imported_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			source = excluded.source,
+			enabled = excluded.enabled,
+			data = excluded.data,
+			group_ids = excluded.group_ids,
+			imported_at = excluded.imported_at
+	`, set.ID, set.Name, set.Source, boolToInt(set.Enabled), set.Data, string(groupIDs), set.ImportedAt.UnixMilli())
+	return err
+}
+
+func (r *RuleSetRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM rule_sets WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func scanRuleSet(scanner itemScanner) (domain.RuleSet, error) {
+	var (
+		set        domain.RuleSet
+		enabled    int
+		groupIDs   string
+		importedAt int64
+	)
+	if err := scanner.Scan(&set.ID, &set.Name, &set.Source, &enabled, &set.Data, &groupIDs, &importedAt); err != nil {
+		return domain.RuleSet{}, err
+	}
+	set.Enabled = enabled != 0
+	set.ImportedAt = time.UnixMilli(importedAt)
+	set.GroupIDs = map[string]string{}
+	if groupIDs != "" {
+		if err := json.Unmarshal([]byte(groupIDs), &set.GroupIDs); err != nil {
+			return domain.RuleSet{}, err
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"rungrid/backend/domain"
+)
+
+type ScanIndexRepository struct {
+	db queryer
+}
+
+func NewScanIndexRepository(db *sql.DB) *ScanIndexRepository {
+	return &ScanIndexRepository{db: db}
+}
+
+func (r *ScanIndexRepository) List(ctx context.Context) ([]domain.ScanIndexEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT path, size, mod_time, target, arguments FROM scan_index`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.ScanIndexEntry{}
+	for rows.Next() {
+		var (
+			entry   domain.ScanIndexEntry
+			modTime int64
+		)
+		if err := rows.Scan(&entry.Path, &entry.Size, &modTime, &entry.Target, &entry.Arguments); err != nil {
+			return nil, err
+		}
+		entry.ModTime = time.Unix(0, modTime)
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *ScanIndexRepository) Save(ctx context.Context, entries []domain.ScanIndexEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO scan_index (path, size, mod_time, target, arguments)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET
+			size = excluded.size,
+			mod_time = excluded.mod_time,
+			target = excluded.target,
+			arguments = excluded.arguments
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, entry := range entries {
+		if _, err := stmt.ExecContext(ctx, entry.Path, entry.Size, entry.ModTime.UnixNano(), entry.Target, entry.Arguments); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *ScanIndexRepository) Delete(ctx context.Context, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, path := range paths {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM scan_index WHERE path = ?", path); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
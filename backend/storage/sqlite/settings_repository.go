@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"rungrid/backend/domain"
+	"rungrid/backend/storage"
+)
+
+type SettingsRepository struct {
+	db *sql.DB
+}
+
+func NewSettingsRepository(db *sql.DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+func (r *SettingsRepository) List(ctx context.Context) ([]domain.Setting, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT key, value FROM settings ORDER BY key ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := []domain.Setting{}
+	for rows.Next() {
+		var setting domain.Setting
+		if err := rows.Scan(&setting.Key, &setting.Value); err != nil {
+			return nil, err
+		}
+		settings = append(settings, setting)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (r *SettingsRepository) Get(ctx context.Context, key string) (domain.Setting, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT key, value FROM settings WHERE key = ?", key)
+
+	var setting domain.Setting
+	if err := row.Scan(&setting.Key, &setting.Value); err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Setting{}, storage.ErrNotFound
+		}
+		return domain.Setting{}, err
+	}
+
+	return setting, nil
+}
+
+func (r *SettingsRepository) Set(ctx context.Context, setting domain.Setting) error {
+	if strings.TrimSpace(setting.Key) == "" {
+		return storage.ErrInvalidInput
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
+	`, setting.Key, setting.Value)
+	return err
+}
+
+func (r *SettingsRepository) Delete(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM settings WHERE key = ?", key)
+	return err
+}
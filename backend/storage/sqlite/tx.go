@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+
+	"rungrid/backend/storage"
+)
+
+// queryer is implemented by *sql.DB and *sql.Tx, so repositories run the
+// same statements inside and outside a unit of work.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// transaction is what beginTx returns: a real transaction, or a savepoint
+// inside one.
+type transaction interface {
+	queryer
+	Commit() error
+	Rollback() error
+}
+
+var savepoints atomic.Int64
+
+// beginTx starts a transaction for a repository method that writes more
+// than once. Inside a unit of work db is already a transaction, so a
+// savepoint is used instead: the method's statements still roll back
+// together on error, and the unit decides whether the rest commits.
+func beginTx(ctx context.Context, db queryer) (transaction, error) {
+	switch db := db.(type) {
+	case *sql.DB:
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return tx, nil
+	case *sql.Tx:
+		name := fmt.Sprintf("rungrid_%d", savepoints.Add(1))
+		if _, err := db.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+			return nil, err
+		}
+		return &savepoint{Tx: db, name: name}, nil
+	default:
+		return nil, fmt.Errorf("cannot begin a transaction on %T", db)
+	}
+}
+
+type savepoint struct {
+	*sql.Tx
+	name string
+	done bool
+}
+
+func (s *savepoint) Commit() error {
+	if s.done {
+		return sql.ErrTxDone
+	}
+	s.done = true
+	_, err := s.Tx.Exec("RELEASE " + s.name)
+	return err
+}
+
+func (s *savepoint) Rollback() error {
+	if s.done {
+		return sql.ErrTxDone
+	}
+	s.done = true
+	if _, err := s.Tx.Exec("ROLLBACK TO " + s.name); err != nil {
+		return err
+	}
+	_, err := s.Tx.Exec("RELEASE " + s.name)
+	return err
+}
+
+// UnitOfWork runs functions against repositories that share one
+// transaction on db.
+type UnitOfWork struct {
+	db *sql.DB
+}
+
+func NewUnitOfWork(db *sql.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+func (u *UnitOfWork) WithinTx(ctx context.Context, fn func(tx storage.Tx) error) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(storage.Tx{
+		Items:      &ItemRepository{db: tx},
+		Groups:     &GroupRepository{db: tx},
+		Launches:   &LaunchRepository{db: tx},
+		Operations: &OperationRepository{db: tx},
+		RuleSets:   &RuleSetRepository{db: tx},
+		ScanIndex:  &ScanIndexRepository{db: tx},
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,264 @@
+// Package watcher reports file changes below a set of directories. Events
+// are collected and delivered in one batch once the directories have been
+// quiet for a while, so copying a folder of shortcuts causes one callback
+// instead of hundreds.
+package watcher
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const DefaultDelay = 800 * time.Millisecond
+
+// ChangeFunc receives the changed paths of one batch in sorted order. Paths
+// may name files or directories and may no longer exist.
+type ChangeFunc func(paths []string)
+
+// ErrorFunc receives errors reported by the platform watcher, such as an
+// event queue overflow.
+type ErrorFunc func(err error)
+
+// Watcher watches directory trees recursively. fsnotify only watches single
+// directories, so every subdirectory is added when the tree is walked and
+// when it is created later.
+type Watcher struct {
+	delay    time.Duration
+	onChange ChangeFunc
+	onError  ErrorFunc
+
+	mu          sync.Mutex
+	fs          *fsnotify.Watcher
+	done        chan struct{}
+	directories map[string]struct{}
+	pending     map[string]struct{}
+	timer       *time.Timer
+	paused      int
+	// flushing serializes callbacks when a batch takes longer than delay.
+	flushing sync.Mutex
+}
+
+// New returns a stopped watcher. onError may be nil.
+func New(delay time.Duration, onChange ChangeFunc, onError ErrorFunc) *Watcher {
+	if delay <= 0 {
+		delay = DefaultDelay
+	}
+	return &Watcher{delay: delay, onChange: onChange, onError: onError}
+}
+
+// Start watches roots and everything below them, replacing any roots
+// watched before. Roots that do not exist are skipped. Changes that are
+// still pending from the previous roots are dropped.
+func (w *Watcher) Start(roots []string) error {
+	w.Stop()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.fs = watcher
+	w.done = make(chan struct{})
+	w.directories = map[string]struct{}{}
+	w.pending = map[string]struct{}{}
+	for _, root := range roots {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			continue
+		}
+		w.addTreeLocked(root)
+	}
+	go w.run(watcher, w.done)
+	return nil
+}
+
+// Stop ends watching and discards pending changes. It is safe to call on a
+// stopped watcher.
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	watcher := w.fs
+	done := w.done
+	w.fs = nil
+	w.done = nil
+	w.directories = nil
+	w.pending = nil
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.mu.Unlock()
+
+	if watcher == nil {
+		return
+	}
+	close(done)
+	_ = watcher.Close()
+}
+
+// Pause holds back callbacks until the matching Resume. Changes keep being
+// collected meanwhile and are delivered together after resuming. Calls
+// nest.
+func (w *Watcher) Pause() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused++
+}
+
+func (w *Watcher) Resume() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.paused == 0 {
+		return
+	}
+	w.paused--
+	if w.paused == 0 && len(w.pending) > 0 {
+		w.scheduleLocked()
+	}
+}
+
+func (w *Watcher) Running() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.fs != nil
+}
+
+func (w *Watcher) Paused() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.paused > 0
+}
+
+// Directories returns how many directories are being watched.
+func (w *Watcher) Directories() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.directories)
+}
+
+func (w *Watcher) run(watcher *fsnotify.Watcher, done chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			w.handle(event)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			if w.onError != nil {
+				w.onError(err)
+			}
+		}
+	}
+}
+
+func (w *Watcher) handle(event fsnotify.Event) {
+	// Attribute changes do not affect what a scan finds.
+	if event.Op == fsnotify.Chmod {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.fs == nil {
+		return
+	}
+
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			w.addTreeLocked(event.Name)
+		}
+	}
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		w.removeTreeLocked(event.Name)
+	}
+
+	w.pending[event.Name] = struct{}{}
+	w.scheduleLocked()
+}
+
+// addTreeLocked watches root and its subdirectories. Directories that
+// cannot be read are skipped rather than failing the whole tree.
+func (w *Watcher) addTreeLocked(root string) {
+	_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if _, ok := w.directories[path]; ok {
+			return nil
+		}
+		if err := w.fs.Add(path); err != nil {
+			if w.onError != nil && !errors.Is(err, fs.ErrNotExist) {
+				w.onError(err)
+			}
+			return filepath.SkipDir
+		}
+		w.directories[path] = struct{}{}
+		return nil
+	})
+}
+
+// removeTreeLocked forgets a deleted or renamed directory and everything
+// below it. The platform drops the watches on its own.
+func (w *Watcher) removeTreeLocked(root string) {
+	for path := range w.directories {
+		if path == root || withinDir(path, root) {
+			_ = w.fs.Remove(path)
+			delete(w.directories, path)
+		}
+	}
+}
+
+func (w *Watcher) scheduleLocked() {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(w.delay, w.flush)
+}
+
+func (w *Watcher) flush() {
+	w.flushing.Lock()
+	defer w.flushing.Unlock()
+
+	w.mu.Lock()
+	if w.fs == nil || w.paused > 0 || len(w.pending) == 0 {
+		w.mu.Unlock()
+		return
+	}
+	paths := make([]string, 0, len(w.pending))
+	for path := range w.pending {
+		paths = append(paths, path)
+	}
+	w.pending = map[string]struct{}{}
+	w.mu.Unlock()
+
+	sort.Strings(paths)
+	w.onChange(paths)
+}
+
+func withinDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -2,22 +2,35 @@ import {useCallback, useEffect, useMemo, useRef, useState} from 'react';
 import './App.css';
 import {categories, menuItems} from './data/mock';
 import {
-  ApplyHotkeys,
+  CancelScan,
   ClearItems,
   CreateGroup,
   CreateItem,
   DeleteGroup,
   DeleteItem,
+  ExportBackup,
+  ExportGroupRules,
   GetCursorAnchorPosition,
+  GetHotkeyIssues,
+  GetSettings,
+  ImportBackup,
   ImportGroupRules,
   LaunchItem,
   ListGroups,
   ListItems,
-  ListScanRoots,
+  MoveGroup,
   OpenItemLocation,
+  PickBackupDestination,
+  PickBackupFile,
+  PickRuleExportDestination,
   PickRuleFile,
+  PreviewBackup,
+  PreviewGroupRules,
   RefreshItemIcon,
+  SaveHotkeys,
+  SaveScanRoots,
   ScanShortcuts,
+  SearchItems,
   SetFavorite,
   SyncIcons,
   UpdateGroup,
@@ -40,33 +53,52 @@ import {
 } from '../wailsjs/runtime/runtime';
 import {AppGrid} from './components/grid/AppGrid';
 import {GroupForm, type GroupDraft} from './components/group/GroupForm';
+import {
+  GroupDeleteForm,
+  type GroupDeleteDraft,
+} from './components/group/GroupDeleteForm';
+import {GroupMoveForm, type GroupMoveDraft} from './components/group/GroupMoveForm';
 import {CategoryBar} from './components/layout/CategoryBar';
 import {GroupTabs} from './components/layout/GroupTabs';
 import {SearchBar} from './components/layout/SearchBar';
 import {TopBar} from './components/layout/TopBar';
+import {RulePlanPreview} from './components/rules/RulePlanPreview';
+import {RuleSetList} from './components/rules/RuleSetList';
 import {ScanRootsEditor} from './components/scan/ScanRootsEditor';
 import {EditItemForm, type EditDraft} from './components/item/EditItemForm';
 import {ModalHost} from './components/overlay/ModalHost';
+import {OperationHistory} from './components/operations/OperationHistory';
 import {ToastHost} from './components/overlay/ToastHost';
 import {ContextMenu} from './components/ui/ContextMenu';
 import {ScrollArea} from './components/ui/ScrollArea';
 import {SettingsModal} from './components/settings/SettingsModal';
 import {useModalStore, useToastStore} from './store/overlays';
-import {mapTypeToCategory, toAppItem, toGroupTab} from './utils/items';
+import {
+  flattenGroupTree,
+  groupSubtree,
+  mapTypeToCategory,
+  toAppItem,
+  toGroupTab,
+} from './utils/items';
 import {toGroupIconName} from './utils/groupIcons';
 import {
+  DEFAULT_HOTKEYS,
   HOTKEY_ACTIONS,
-  loadHotkeys,
-  saveHotkeys,
+  clearLegacyHotkeys,
+  fromHotkeyBindings,
+  readLegacyHotkeys,
   toHotkeyBindings,
   type HotkeyConfig,
 } from './utils/hotkeys';
 import {
-  loadPreferences,
+  DEFAULT_PREFERENCES,
+  clearLegacyPreferences,
+  normalizePreferences,
+  readLegacyPreferences,
   savePreferences,
   type Preferences,
 } from './utils/preferences';
-import type {AppItem} from './types';
+import type {AppItem, TextRange} from './types';
 
 type ClipboardItem = {
   id: string;
@@ -87,6 +119,9 @@ type ClipboardPayload = {
 
 function App() {
   const [items, setItems] = useState<domain.Item[]>([]);
+  const [nameMatches, setNameMatches] = useState<Record<string, TextRange[]>>(
+    {}
+  );
   const [groups, setGroups] = useState<domain.Group[]>([]);
   const [activeCategoryId, setActiveCategoryId] = useState(categories[0].id);
   const [activeGroupId, setActiveGroupId] = useState('all');
@@ -97,17 +132,20 @@ function App() {
   const [scanRootsReady, setScanRootsReady] = useState(false);
   const [iconVersion, setIconVersion] = useState(0);
   const [isWindowHidden, setIsWindowHidden] = useState(false);
-  const [preferences, setPreferences] = useState<Preferences>(() =>
-    loadPreferences()
-  );
+  const [preferences, setPreferences] =
+    useState<Preferences>(DEFAULT_PREFERENCES);
+  const [hotkeys, setHotkeys] = useState<HotkeyConfig>(DEFAULT_HOTKEYS);
   const [clipboard, setClipboard] = useState<ClipboardPayload | null>(null);
   const [selectedIds, setSelectedIds] = useState<string[]>([]);
   const [selectionMode, setSelectionMode] = useState(false);
   const scanRootsRef = useRef<string[]>([]);
+  const savedScanRootsRef = useRef<string | null>(null);
   const selectionMetaRef = useRef<Map<string, ClipboardItem>>(new Map());
   const editDraftRef = useRef<EditDraft | null>(null);
   const createDraftRef = useRef<EditDraft | null>(null);
   const groupDraftRef = useRef<GroupDraft | null>(null);
+  const groupDeleteRef = useRef<GroupDeleteDraft | null>(null);
+  const groupMoveRef = useRef<GroupMoveDraft | null>(null);
   const hotkeyDraftRef = useRef<HotkeyConfig | null>(null);
   const preferenceDraftRef = useRef<Preferences | null>(null);
   const searchInputRef = useRef<HTMLInputElement | null>(null);
@@ -247,7 +285,7 @@ function App() {
           ...prev,
           lastWindowPosition: {x: pos.x, y: pos.y},
         };
-        savePreferences(next);
+        void savePreferences(next).catch(() => {});
         return next;
       });
     } catch {
@@ -297,10 +335,12 @@ function App() {
     [hotkeyLabelMap]
   );
 
+  // applyHotkeys saves and registers the bindings and reports whether
+  // SaveHotkeys succeeded; bindings it could not register are still saved.
   const applyHotkeys = useCallback(
-    async (config: HotkeyConfig) => {
+    async (config: HotkeyConfig): Promise<boolean> => {
       try {
-        const result = await ApplyHotkeys(toHotkeyBindings(config));
+        const result = await SaveHotkeys(toHotkeyBindings(config));
         if (result?.issues?.length) {
           notify({
             type: 'warning',
@@ -308,50 +348,99 @@ function App() {
             message: formatHotkeyIssues(result.issues),
           });
         }
+        return true;
       } catch (err) {
         showError(
           err instanceof Error ? err.message : '全局快捷键注册失败',
           '快捷键注册失败'
         );
+        return false;
       }
     },
     [formatHotkeyIssues, notify, showError]
   );
 
   useEffect(() => {
-    const cached = window.localStorage.getItem('rungrid.scanRoots');
-    if (cached) {
-      try {
-        const parsed = JSON.parse(cached);
+    const loadSettings = async () => {
+      const settings = await GetSettings();
+
+      let roots = settings.scan_roots ?? [];
+      const cachedRoots = window.localStorage.getItem('rungrid.scanRoots');
+      if (cachedRoots) {
+        let parsed: unknown = null;
+        try {
+          parsed = JSON.parse(cachedRoots);
+        } catch {
+        }
         if (Array.isArray(parsed)) {
-          handleScanRootsChange(normalizeRoots(parsed));
-          setScanRootsReady(true);
-          return;
+          roots = await SaveScanRoots(normalizeRoots(parsed));
+          window.localStorage.removeItem('rungrid.scanRoots');
         }
-      } catch {
-        window.localStorage.removeItem('rungrid.scanRoots');
       }
-    }
+      const normalized = normalizeRoots(roots);
+      savedScanRootsRef.current = JSON.stringify(normalized);
+      handleScanRootsChange(normalized);
+
+      const legacyPreferences = readLegacyPreferences();
+      if (legacyPreferences) {
+        await savePreferences(legacyPreferences);
+        clearLegacyPreferences();
+        setPreferences(legacyPreferences);
+      } else {
+        setPreferences(normalizePreferences(settings.preferences));
+      }
 
-    ListScanRoots()
-      .then((roots) => {
-        handleScanRootsChange(normalizeRoots(roots));
-      })
+      const legacyHotkeys = readLegacyHotkeys();
+      if (legacyHotkeys) {
+        setHotkeys(legacyHotkeys);
+        if (await applyHotkeys(legacyHotkeys)) {
+          clearLegacyHotkeys();
+        }
+        return;
+      }
+      setHotkeys(fromHotkeyBindings(settings.hotkeys ?? []));
+      const issues = await GetHotkeyIssues();
+      if (issues.length) {
+        notify({
+          type: 'warning',
+          title: '快捷键注册失败',
+          message: formatHotkeyIssues(issues),
+        });
+      }
+    };
+
+    loadSettings()
       .catch((err) => {
         showError(
-          err instanceof Error ? err.message : '无法读取扫描路径',
+          err instanceof Error ? err.message : '无法读取设置',
           '读取失败'
         );
       })
       .finally(() => setScanRootsReady(true));
-  }, [handleScanRootsChange, showError]);
+  }, [
+    applyHotkeys,
+    formatHotkeyIssues,
+    handleScanRootsChange,
+    notify,
+    showError,
+  ]);
 
   useEffect(() => {
     if (!scanRootsReady) {
       return;
     }
-    window.localStorage.setItem('rungrid.scanRoots', JSON.stringify(scanRoots));
-  }, [scanRoots, scanRootsReady]);
+    const serialized = JSON.stringify(scanRoots);
+    if (serialized === savedScanRootsRef.current) {
+      return;
+    }
+    savedScanRootsRef.current = serialized;
+    SaveScanRoots(scanRoots).catch((err) => {
+      showError(
+        err instanceof Error ? err.message : '无法保存扫描路径',
+        '保存失败'
+      );
+    });
+  }, [scanRoots, scanRootsReady, showError]);
 
   const loadGroups = useCallback(async () => {
     try {
@@ -367,8 +456,22 @@ function App() {
     setError(null);
 
     try {
-      const data = await ListItems(activeGroupId, query);
-      setItems(data);
+      if (!query.trim()) {
+        const data = await ListItems(activeGroupId, query);
+        setItems(data);
+        setNameMatches({});
+        return;
+      }
+
+      const results = await SearchItems(activeGroupId, query);
+      const matches: Record<string, TextRange[]> = {};
+      for (const result of results) {
+        matches[result.item.id] = (result.matches ?? [])
+          .filter((match) => match.field === 'name')
+          .map((match) => ({start: match.start, end: match.end}));
+      }
+      setItems(results.map((result) => result.item));
+      setNameMatches(matches);
     } catch (err) {
       showError(err instanceof Error ? err.message : '无法加载项目', '加载失败');
     } finally {
@@ -394,6 +497,23 @@ function App() {
     };
   }, [loadItems, bumpIconVersion]);
 
+  useEffect(() => {
+    // Watcher rescans report items:changed; scans started from the tray
+    // only report scan:finished.
+    const offChanged = EventsOn('items:changed', () => {
+      loadItems();
+    });
+    const offFinished = EventsOn('scan:finished', (job: domain.ScanJob) => {
+      if (job?.source === 'manual') {
+        loadItems();
+      }
+    });
+    return () => {
+      offChanged();
+      offFinished();
+    };
+  }, [loadItems]);
+
   useEffect(() => {
     const off = EventsOn('window:show', () => {
       void showWindow();
@@ -424,12 +544,6 @@ function App() {
     };
   }, [hideWindow, isWindowHidden, preferences.panelCloseMode]);
 
-  useEffect(() => {
-    const stored = loadHotkeys();
-    hotkeyDraftRef.current = stored;
-    void applyHotkeys(stored);
-  }, [applyHotkeys]);
-
   const categoryGroups = useMemo(
     () =>
       groups.filter(
@@ -439,18 +553,29 @@ function App() {
     [groups, activeCategoryId]
   );
 
+  const groupTree = useMemo(() => flattenGroupTree(categoryGroups), [categoryGroups]);
+
+  const isSmartGroupActive = useMemo(
+    () =>
+      categoryGroups.some(
+        (group) => group.id === activeGroupId && group.kind === 'smart'
+      ),
+    [activeGroupId, categoryGroups]
+  );
+
   const handleReorderGroup = useCallback(
     async (sourceId: string, targetId: string) => {
       if (!sourceId || !targetId || sourceId === targetId) {
         return;
       }
-      const sourceIndex = categoryGroups.findIndex((group) => group.id === sourceId);
-      const targetIndex = categoryGroups.findIndex((group) => group.id === targetId);
+      const ordered = groupTree.map((entry) => entry.group);
+      const sourceIndex = ordered.findIndex((group) => group.id === sourceId);
+      const targetIndex = ordered.findIndex((group) => group.id === targetId);
       if (sourceIndex < 0 || targetIndex < 0) {
         return;
       }
 
-      const reordered = [...categoryGroups];
+      const reordered = [...ordered];
       const [moved] = reordered.splice(sourceIndex, 1);
       reordered.splice(targetIndex, 0, moved);
 
@@ -461,11 +586,14 @@ function App() {
           }
           return UpdateGroup({
             id: group.id,
+            parent_id: group.parent_id,
             name: group.name,
             order: index,
             color: group.color,
             category: group.category || 'app',
             icon: group.icon,
+            kind: group.kind,
+            query: group.query,
           });
         })
         .filter(Boolean) as Array<Promise<domain.Group>>;
@@ -482,7 +610,7 @@ function App() {
         showError(err instanceof Error ? err.message : '排序失败', '排序失败');
       }
     },
-    [categoryGroups, loadGroups, notify, showError]
+    [groupTree, loadGroups, notify, showError]
   );
 
   useEffect(() => {
@@ -500,13 +628,15 @@ function App() {
     }
   }, [activeGroupId, categoryGroups]);
 
-  const handleAddGroup = useCallback(async () => {
-    const initialDraft: GroupDraft = {name: '', icon: ''};
+  const createGroup = useCallback(async (parent: domain.Group | null) => {
+    const initialDraft: GroupDraft = {name: '', icon: '', kind: 'manual', query: ''};
     groupDraftRef.current = initialDraft;
     const modalId = openModal({
       kind: 'form',
-      title: '添加分组',
-      description: '为当前分类创建新的分组。',
+      title: parent ? '添加子分组' : '添加分组',
+      description: parent
+        ? `在「${parent.name}」下创建子分组。`
+        : '为当前分类创建新的分组。',
       size: 'lg',
       primaryLabel: '保存',
       secondaryLabel: '关闭',
@@ -514,6 +644,7 @@ function App() {
       content: (
         <GroupForm
           initialDraft={initialDraft}
+          kindEditable
           onChange={(next) => {
             groupDraftRef.current = next;
           }}
@@ -533,14 +664,25 @@ function App() {
           });
           return;
         }
+        if (draft.kind === 'smart' && !draft.query.trim()) {
+          notify({
+            type: 'warning',
+            title: '请补全信息',
+            message: '智能分组需要填写查询条件。',
+          });
+          return;
+        }
 
         try {
           const newGroup = await CreateGroup({
+            parent_id: parent?.id ?? '',
             name,
             order: categoryGroups.length,
             color: '#4f7dff',
-            category: mapCategoryToType(activeCategoryId),
+            category: parent?.category || mapCategoryToType(activeCategoryId),
             icon: draft.icon,
+            kind: draft.kind,
+            query: draft.kind === 'smart' ? draft.query.trim() : '',
           });
           setActiveGroupId(newGroup.id);
           await loadGroups();
@@ -566,11 +708,17 @@ function App() {
     showError,
   ]);
 
+  const handleAddGroup = useCallback(() => createGroup(null), [createGroup]);
+
   const handleAddItem = useCallback(async () => {
     if (activeGroupId === 'all') {
       window.alert('请先选择一个分组');
       return;
     }
+    if (isSmartGroupActive) {
+      window.alert('智能分组的项目由查询条件决定，请切换到普通分组后添加');
+      return;
+    }
 
     const initialDraft: EditDraft = {
       id: '',
@@ -653,6 +801,7 @@ function App() {
     activeGroupId,
     bumpIconVersion,
     closeModal,
+    isSmartGroupActive,
     loadItems,
     notify,
     openModal,
@@ -677,6 +826,12 @@ function App() {
         description: `正在扫描 ${normalizedRoots.length} 个目录…`,
         closable: false,
         backdropClose: false,
+        secondaryLabel: '取消扫描',
+        autoClose: false,
+        onCancel: async () => {
+          updateModal(modalId, {description: '正在取消…', secondaryLabel: undefined});
+          await CancelScan('');
+        },
       });
       const off = EventsOn('scan:progress', (payload: ScanProgressPayload) => {
         if (!payload) {
@@ -704,9 +859,29 @@ function App() {
       setIsLoading(true);
       setError(null);
       try {
-        await ScanShortcuts(normalizedRoots);
+        const job = await ScanShortcuts(normalizedRoots);
+        const result = job.result;
         await loadItems();
-        notify({type: 'success', title: '扫描完成', message: '应用列表已更新'});
+        const changes = [
+          result.inserted > 0 ? `新增 ${result.inserted}` : '',
+          result.updated > 0 ? `更新 ${result.updated}` : '',
+          result.removed > 0 ? `移除 ${result.removed}` : '',
+          result.grouped > 0 ? `规则分组 ${result.grouped}` : '',
+          result.broken > 0 ? `失效 ${result.broken}` : '',
+        ].filter(Boolean);
+        if (job.status === 'cancelled') {
+          notify({
+            type: 'info',
+            title: '扫描已取消',
+            message: '项目未作任何更改',
+          });
+        } else {
+          notify({
+            type: 'success',
+            title: '扫描完成',
+            message: changes.length > 0 ? changes.join('，') : '没有变化',
+          });
+        }
       } catch (err) {
         showError(err instanceof Error ? err.message : '扫描失败', '扫描失败');
       } finally {
@@ -719,7 +894,7 @@ function App() {
   );
 
   const openSettingsModal = useCallback(() => {
-    const initial = loadHotkeys();
+    const initial = hotkeys;
     const initialPreferences = preferences;
     hotkeyDraftRef.current = initial;
     preferenceDraftRef.current = initialPreferences;
@@ -745,11 +920,19 @@ function App() {
       ),
       onConfirm: async () => {
         if (hotkeyDraftRef.current) {
-          saveHotkeys(hotkeyDraftRef.current);
+          setHotkeys(hotkeyDraftRef.current);
           await applyHotkeys(hotkeyDraftRef.current);
         }
         if (preferenceDraftRef.current) {
-          savePreferences(preferenceDraftRef.current);
+          try {
+            await savePreferences(preferenceDraftRef.current);
+          } catch (err) {
+            showError(
+              err instanceof Error ? err.message : '无法保存偏好设置',
+              '保存失败'
+            );
+            return;
+          }
           setPreferences(preferenceDraftRef.current);
         }
         notify({type: 'success', title: '设置已保存'});
@@ -763,7 +946,15 @@ function App() {
         closeModal(modalId);
       },
     });
-  }, [applyHotkeys, closeModal, notify, openModal, preferences]);
+  }, [
+    applyHotkeys,
+    closeModal,
+    hotkeys,
+    notify,
+    openModal,
+    preferences,
+    showError,
+  ]);
 
   const handleMenuSelect = useCallback(
     async (id: string) => {
@@ -827,37 +1018,182 @@ function App() {
       }
 
       if (id === 'import-rules') {
-        let modalId: string | null = null;
+        let filePath = '';
+        let plan: domain.RuleImportPlan | null = null;
         try {
-          const filePath = await PickRuleFile();
+          filePath = await PickRuleFile();
           if (!filePath) {
             return;
           }
-          modalId = openModal({
-            kind: 'progress',
-            title: '正在导入规则',
-            description: '正在解析并应用分组规则…',
-            closable: false,
-            backdropClose: false,
+          plan = await PreviewGroupRules(filePath);
+        } catch (err) {
+          showError(err instanceof Error ? err.message : '规则文件无效', '导入失败');
+          return;
+        }
+        if (!plan) {
+          return;
+        }
+        openModal({
+          kind: 'form',
+          title: '导入分组规则',
+          description: '确认以下变更后再应用规则。',
+          size: 'lg',
+          primaryLabel: '应用',
+          secondaryLabel: '取消',
+          content: <RulePlanPreview plan={plan} />,
+          onConfirm: async () => {
+            setIsLoading(true);
+            setError(null);
+            try {
+              const result = await ImportGroupRules(filePath);
+              await loadGroups();
+              await loadItems();
+              notify({
+                type: 'success',
+                title: '规则已导入',
+                message: `新增分组 ${result.groups_created}，更新分组 ${result.groups_updated}，更新项目 ${result.items_updated}${
+                  result.conflicts?.length ? `，规则冲突 ${result.conflicts.length}` : ''
+                }`,
+              });
+            } catch (err) {
+              showError(err instanceof Error ? err.message : '导入失败', '导入失败');
+            } finally {
+              setIsLoading(false);
+            }
+          },
+        });
+        return;
+      }
+
+      if (id === 'export-rules') {
+        try {
+          const filePath = await PickRuleExportDestination();
+          if (!filePath) {
+            return;
+          }
+          const result = await ExportGroupRules(filePath);
+          notify({
+            type: 'success',
+            title: '分组规则已导出',
+            message:
+              result.items_skipped > 0
+                ? `${result.groups} 个分组，${result.items} 个项目；${result.items_skipped} 个项目缺少目标文件名与路径未导出`
+                : `${result.groups} 个分组，${result.items} 个项目`,
           });
+        } catch (err) {
+          showError(err instanceof Error ? err.message : '导出失败', '导出失败');
+        }
+        return;
+      }
+
+      if (id === 'rule-sets') {
+        openModal({
+          kind: 'form',
+          title: '分组规则集',
+          description: '已导入的规则文件保存在数据目录的 rules 文件夹中。',
+          size: 'lg',
+          primaryLabel: '关闭',
+          content: <RuleSetList onError={(message) => showError(message, '规则集操作失败')} />,
+        });
+        return;
+      }
+
+      if (id === 'history') {
+        openModal({
+          kind: 'form',
+          title: '操作记录',
+          description: '导入规则、扫描、清空与批量编辑可以撤销。',
+          size: 'lg',
+          primaryLabel: '关闭',
+          content: (
+            <OperationHistory
+              onUndone={async () => {
+                await loadGroups();
+                await loadItems();
+                notify({type: 'success', title: '已撤销'});
+              }}
+              onError={(message) => showError(message, '撤销失败')}
+            />
+          ),
+        });
+        return;
+      }
+
+      if (id === 'export-backup') {
+        try {
+          const filePath = await PickBackupDestination();
+          if (!filePath) {
+            return;
+          }
           setIsLoading(true);
-          setError(null);
-          const result = await ImportGroupRules(filePath);
-          await loadGroups();
-          await loadItems();
+          const manifest = await ExportBackup(filePath);
           notify({
             type: 'success',
-            title: '规则已导入',
-            message: `新增分组 ${result.groups_created}，更新分组 ${result.groups_updated}，更新项目 ${result.items_updated}`,
+            title: '备份已导出',
+            message: `共 ${manifest.files.length} 个文件`,
           });
         } catch (err) {
-          showError(err instanceof Error ? err.message : '导入失败', '导入失败');
+          showError(err instanceof Error ? err.message : '导出失败', '导出失败');
         } finally {
           setIsLoading(false);
-          if (modalId) {
-            closeModal(modalId);
+        }
+        return;
+      }
+
+      if (id === 'import-backup') {
+        let filePath = '';
+        let preview: domain.BackupPreview | null = null;
+        try {
+          filePath = await PickBackupFile();
+          if (!filePath) {
+            return;
           }
+          preview = await PreviewBackup(filePath);
+        } catch (err) {
+          showError(err instanceof Error ? err.message : '备份文件无效', '无法读取备份');
+          return;
+        }
+        if (!preview) {
+          return;
         }
+        const describe = (label: string, count: domain.BackupCount) =>
+          `${label} ${count.current} → ${count.backup}`;
+        openModal({
+          kind: 'confirm',
+          title: '从备份恢复？',
+          description: [
+            describe('项目', preview.items),
+            describe('分组', preview.groups),
+            describe('启动记录', preview.launches),
+            describe('图标', preview.icons),
+            `设置变更 ${preview.settings.length} 项`,
+            '当前数据会被替换，恢复前会自动保存一份快照。',
+          ].join('；'),
+          tone: 'danger',
+          primaryLabel: '恢复',
+          secondaryLabel: '取消',
+          onConfirm: async () => {
+            setIsLoading(true);
+            setError(null);
+            try {
+              await ImportBackup(filePath);
+              const settings = await GetSettings();
+              setPreferences(normalizePreferences(settings.preferences));
+              setHotkeys(fromHotkeyBindings(settings.hotkeys ?? []));
+              const roots = normalizeRoots(settings.scan_roots ?? []);
+              savedScanRootsRef.current = JSON.stringify(roots);
+              handleScanRootsChange(roots);
+              await loadGroups();
+              await loadItems();
+              bumpIconVersion();
+              notify({type: 'success', title: '恢复完成'});
+            } catch (err) {
+              showError(err instanceof Error ? err.message : '恢复失败', '恢复失败');
+            } finally {
+              setIsLoading(false);
+            }
+          },
+        });
         return;
       }
 
@@ -888,6 +1224,7 @@ function App() {
     [
       closeModal,
       bumpIconVersion,
+      handleScanRootsChange,
       loadGroups,
       loadItems,
       notify,
@@ -943,7 +1280,7 @@ function App() {
     async (id: string) => {
       setFocusedId(null);
       try {
-        await LaunchItem(id);
+        await LaunchItem(id, query.trim() ? 'search' : 'grid');
         if (
           preferences.panelCloseMode === 'launch' ||
           preferences.panelCloseMode === 'launch-or-blur'
@@ -955,18 +1292,25 @@ function App() {
         showError(err instanceof Error ? err.message : '启动失败', '启动失败');
       }
     },
-    [hideWindow, loadItems, preferences.panelCloseMode, showError]
+    [hideWindow, loadItems, preferences.panelCloseMode, query, showError]
   );
 
 
   const groupTabs = useMemo(
-    () => [{id: 'all', label: '全部'}, ...categoryGroups.map(toGroupTab)],
-    [categoryGroups]
+    () => [
+      {id: 'all', label: '全部'},
+      ...groupTree.map((entry) => toGroupTab(entry.group, entry.depth)),
+    ],
+    [groupTree]
   );
 
   const appItems = useMemo(
-    () => items.map((item, index) => toAppItem(item, index, iconVersion)),
-    [items, iconVersion]
+    () =>
+      items.map((item, index) => ({
+        ...toAppItem(item, index, iconVersion),
+        nameMatches: nameMatches[item.id],
+      })),
+    [items, nameMatches, iconVersion]
   );
 
   const filteredItems = useMemo(
@@ -1092,7 +1436,8 @@ function App() {
   const canPaste =
     Boolean(clipboard) &&
     clipboard?.categoryId === activeCategoryId &&
-    activeGroupId !== 'all';
+    activeGroupId !== 'all' &&
+    !isSmartGroupActive;
 
   const pasteToActiveGroup = useCallback(async () => {
     if (!clipboard) {
@@ -1107,6 +1452,14 @@ function App() {
       });
       return;
     }
+    if (isSmartGroupActive) {
+      notify({
+        type: 'warning',
+        title: '无法粘贴到智能分组',
+        message: '智能分组的项目由查询条件决定。',
+      });
+      return;
+    }
     if (clipboard.categoryId !== activeCategoryId) {
       notify({
         type: 'warning',
@@ -1161,6 +1514,7 @@ function App() {
     activeGroupId,
     clipboard,
     cancelSelection,
+    isSmartGroupActive,
     loadItems,
     notify,
     showError,
@@ -1383,6 +1737,8 @@ function App() {
   const groupMenuItems = useMemo(
     () => [
       {id: 'edit', label: '编辑分组'},
+      {id: 'add-child', label: '新建子分组'},
+      {id: 'move', label: '移动到…'},
       {id: 'delete', label: '删除分组', tone: 'danger' as const},
     ],
     []
@@ -1403,12 +1759,15 @@ function App() {
         const initialDraft: GroupDraft = {
           name: group.name,
           icon: toGroupIconName(group.icon) ?? '',
+          kind: group.kind === 'smart' ? 'smart' : 'manual',
+          query: group.query,
         };
         groupDraftRef.current = initialDraft;
         const modalId = openModal({
           kind: 'form',
           title: '编辑分组',
-          description: '更新分组名称与图标。',
+          description:
+            group.kind === 'smart' ? '更新分组名称、图标与查询条件。' : '更新分组名称与图标。',
           size: 'lg',
           primaryLabel: '保存',
           secondaryLabel: '关闭',
@@ -1439,11 +1798,14 @@ function App() {
             try {
               await UpdateGroup({
                 id: group.id,
+                parent_id: group.parent_id,
                 name,
                 order: group.order,
                 color: group.color,
                 category: group.category || mapCategoryToType(activeCategoryId),
                 icon: draft.icon,
+                kind: group.kind,
+                query: draft.query.trim(),
               });
               await loadGroups();
               notify({type: 'success', title: '分组已更新', message: name});
@@ -1460,32 +1822,139 @@ function App() {
         });
       }
 
+      if (actionId === 'add-child') {
+        if (group.kind === 'smart') {
+          notify({
+            type: 'warning',
+            title: '无法新建子分组',
+            message: '智能分组下不能再嵌套分组。',
+          });
+          return;
+        }
+        await createGroup(group);
+      }
+
+      if (actionId === 'move') {
+        const subtree = groupSubtree(groups, group.id);
+        const targets = groupTree
+          .map((entry) => entry.group)
+          .filter((entry) => !subtree.has(entry.id) && entry.kind !== 'smart');
+        const initialDraft: GroupMoveDraft = {parentId: group.parent_id};
+        groupMoveRef.current = initialDraft;
+        const modalId = openModal({
+          kind: 'form',
+          title: `移动分组「${group.name}」`,
+          description: '选择上级分组，子分组会随之移动。',
+          primaryLabel: '移动',
+          secondaryLabel: '取消',
+          autoClose: false,
+          content: (
+            <GroupMoveForm
+              targets={targets}
+              initialDraft={initialDraft}
+              onChange={(next) => {
+                groupMoveRef.current = next;
+              }}
+            />
+          ),
+          onConfirm: async () => {
+            const draft = groupMoveRef.current;
+            if (!draft) {
+              return;
+            }
+
+            try {
+              await MoveGroup(group.id, draft.parentId);
+              await loadGroups();
+              await loadItems();
+              notify({type: 'success', title: '分组已移动', message: group.name});
+              closeModal(modalId);
+              groupMoveRef.current = null;
+            } catch (err) {
+              showError(err instanceof Error ? err.message : '无法移动分组');
+            }
+          },
+          onCancel: () => {
+            groupMoveRef.current = null;
+            closeModal(modalId);
+          },
+        });
+      }
+
       if (actionId === 'delete') {
-        openModal({
+        const targets = groups.filter(
+          (entry) => entry.id !== group.id && entry.kind !== 'smart'
+        );
+        const initialDraft: GroupDeleteDraft = {
+          policy: 'ungroup',
+          targetId: targets[0]?.id ?? '',
+        };
+        groupDeleteRef.current = initialDraft;
+        const modalId = openModal({
           kind: 'confirm',
           title: `删除分组「${group.name}」？`,
-          description: '分组删除后，该分组下的项目会保留在全部列表中。',
+          description: '选择如何处理该分组下的项目。',
           tone: 'danger',
           primaryLabel: '删除',
           secondaryLabel: '取消',
+          autoClose: false,
+          content: (
+            <GroupDeleteForm
+              targets={targets}
+              initialDraft={initialDraft}
+              onChange={(next) => {
+                groupDeleteRef.current = next;
+              }}
+            />
+          ),
           onConfirm: async () => {
+            const draft = groupDeleteRef.current;
+            if (!draft) {
+              return;
+            }
+            if (draft.policy === 'move' && !draft.targetId) {
+              notify({
+                type: 'warning',
+                title: '请选择目标分组',
+                message: '移动项目前需要选择一个分组。',
+              });
+              return;
+            }
+
             try {
-              await DeleteGroup(group.id);
+              const result = await DeleteGroup(group.id, {
+                policy: draft.policy,
+                target_id: draft.policy === 'move' ? draft.targetId : '',
+              });
               await loadGroups();
-              notify({type: 'success', title: '分组已删除', message: group.name});
+              await loadItems();
+              notify({
+                type: 'success',
+                title: '分组已删除',
+                message: result.items ? `${group.name} · 项目 ${result.items}` : group.name,
+              });
+              closeModal(modalId);
+              groupDeleteRef.current = null;
             } catch (err) {
               showError(err instanceof Error ? err.message : '无法删除分组');
             }
           },
+          onCancel: () => {
+            groupDeleteRef.current = null;
+            closeModal(modalId);
+          },
         });
       }
     },
     [
       activeCategoryId,
       closeModal,
+      createGroup,
       groupMenuState.groupId,
+      groupTree,
       groups,
       loadGroups,
+      loadItems,
       notify,
       openModal,
       showError,
@@ -1,4 +1,10 @@
-import type {AppItem} from '../../types';
+import type {ReactNode} from 'react';
+import type {AppItem, TextRange} from '../../types';
+
+const brokenLabels: Record<string, string> = {
+  missing_source: '文件已不存在',
+  missing_target: '快捷方式的目标已不存在',
+};
 
 type AppTileProps = {
   item: AppItem;
@@ -26,8 +32,10 @@ export function AppTile({
   return (
     <button
       type="button"
-      className={`app-tile${selected ? ' is-selected' : ''}${focused ? ' is-focused' : ''}`}
-      title={item.name}
+      className={`app-tile${selected ? ' is-selected' : ''}${focused ? ' is-focused' : ''}${
+        item.broken ? ' is-broken' : ''
+      }`}
+      title={item.broken ? `${item.name}（${brokenLabels[item.broken] ?? '无法启动'}）` : item.name}
       onClick={(event) => {
         const multi = event.ctrlKey || event.metaKey;
         if (selectionMode || multi) {
@@ -63,8 +71,36 @@ export function AppTile({
         ) : (
           <span className="app-glyph">{item.glyph}</span>
         )}
+        {item.broken ? <span className="app-badge app-badge--broken">!</span> : null}
       </div>
-      <span className="app-name">{item.name}</span>
+      <span className="app-name">
+        {renderHighlightedName(item.name, item.nameMatches)}
+      </span>
     </button>
   );
 }
+
+function renderHighlightedName(name: string, ranges?: TextRange[]) {
+  if (!ranges || ranges.length === 0) {
+    return name;
+  }
+
+  const parts: ReactNode[] = [];
+  let cursor = 0;
+  for (const range of ranges) {
+    if (range.start < cursor || range.end <= range.start) {
+      continue;
+    }
+    if (range.start > cursor) {
+      parts.push(name.slice(cursor, range.start));
+    }
+    parts.push(
+      <mark key={range.start}>{name.slice(range.start, range.end)}</mark>
+    );
+    cursor = range.end;
+  }
+  if (cursor < name.length) {
+    parts.push(name.slice(cursor));
+  }
+  return parts;
+}
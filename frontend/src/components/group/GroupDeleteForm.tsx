@@ -0,0 +1,74 @@
+import {useEffect, useState} from 'react';
+import type {domain} from '../../../wailsjs/go/models';
+import './GroupForm.css';
+
+export type GroupDeleteDraft = {
+  policy: 'move' | 'ungroup' | 'delete_items';
+  targetId: string;
+};
+
+type GroupDeleteFormProps = {
+  targets: domain.Group[];
+  initialDraft: GroupDeleteDraft;
+  onChange: (next: GroupDeleteDraft) => void;
+};
+
+const policyOptions: {value: GroupDeleteDraft['policy']; label: string; detail: string}[] = [
+  {value: 'ungroup', label: '移出分组', detail: '项目保留在全部列表中，不属于任何分组。'},
+  {value: 'move', label: '移动到其他分组', detail: '项目整体转入所选分组。'},
+  {value: 'delete_items', label: '一并删除项目', detail: '项目及其启动记录会被删除，可在操作记录中撤销。'},
+];
+
+export function GroupDeleteForm({targets, initialDraft, onChange}: GroupDeleteFormProps) {
+  const [draft, setDraft] = useState<GroupDeleteDraft>(initialDraft);
+
+  useEffect(() => {
+    onChange(draft);
+  }, [draft, onChange]);
+
+  return (
+    <div className="group-form">
+      <div className="group-delete-options" role="radiogroup" aria-label="分组内的项目">
+        {policyOptions.map((option) => {
+          const disabled = option.value === 'move' && targets.length === 0;
+          const isSelected = draft.policy === option.value;
+          return (
+            <button
+              key={option.value}
+              type="button"
+              role="radio"
+              aria-checked={isSelected}
+              disabled={disabled}
+              className={`group-delete-option${isSelected ? ' is-selected' : ''}`}
+              onClick={() => setDraft((prev) => ({...prev, policy: option.value}))}
+            >
+              <span>{option.label}</span>
+              <span className="group-delete-detail">{option.detail}</span>
+            </button>
+          );
+        })}
+      </div>
+      {draft.policy === 'move' ? (
+        <div className="group-icon-field">
+          <span className="edit-label">目标分组</span>
+          <div className="group-delete-targets" role="listbox" aria-label="选择目标分组">
+            {targets.map((target) => {
+              const isSelected = draft.targetId === target.id;
+              return (
+                <button
+                  key={target.id}
+                  type="button"
+                  className={`group-delete-target${isSelected ? ' is-selected' : ''}`}
+                  aria-pressed={isSelected}
+                  onClick={() => setDraft((prev) => ({...prev, targetId: target.id}))}
+                >
+                  {target.name}
+                </button>
+              );
+            })}
+          </div>
+        </div>
+      ) : null}
+    </div>
+  );
+}
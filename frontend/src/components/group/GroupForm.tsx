@@ -9,14 +9,23 @@ import './GroupForm.css';
 export type GroupDraft = {
   name: string;
   icon: GroupIconValue;
+  kind: 'manual' | 'smart';
+  query: string;
 };
 
 type GroupFormProps = {
   initialDraft: GroupDraft;
   onChange: (next: GroupDraft) => void;
+  // The kind can only be picked when the group is created.
+  kindEditable?: boolean;
 };
 
-export function GroupForm({initialDraft, onChange}: GroupFormProps) {
+const kindOptions: {value: GroupDraft['kind']; label: string; detail: string}[] = [
+  {value: 'manual', label: '普通分组', detail: '手动放入项目，规则导入也会归入此类分组。'},
+  {value: 'smart', label: '智能分组', detail: '按查询条件实时筛选项目，不能直接放入项目。'},
+];
+
+export function GroupForm({initialDraft, onChange, kindEditable = false}: GroupFormProps) {
   const [draft, setDraft] = useState<GroupDraft>(initialDraft);
 
   useEffect(() => {
@@ -37,6 +46,43 @@ export function GroupForm({initialDraft, onChange}: GroupFormProps) {
           placeholder="输入分组名称"
         />
       </label>
+      {kindEditable ? (
+        <div className="group-delete-options" role="radiogroup" aria-label="分组类型">
+          {kindOptions.map((option) => {
+            const isSelected = draft.kind === option.value;
+            return (
+              <button
+                key={option.value}
+                type="button"
+                role="radio"
+                aria-checked={isSelected}
+                className={`group-delete-option${isSelected ? ' is-selected' : ''}`}
+                onClick={() => setDraft((prev) => ({...prev, kind: option.value}))}
+              >
+                <span>{option.label}</span>
+                <span className="group-delete-detail">{option.detail}</span>
+              </button>
+            );
+          })}
+        </div>
+      ) : null}
+      {draft.kind === 'smart' ? (
+        <label className="edit-field">
+          <span className="edit-label">查询条件</span>
+          <input
+            type="text"
+            className="edit-input"
+            value={draft.query}
+            onChange={(event) =>
+              setDraft((prev) => ({...prev, query: event.target.value}))
+            }
+            placeholder="例如 used:week、tag:work -is:hidden、type:url OR is:broken"
+          />
+          <span className="group-query-hint">
+            支持 name / path / target / type / tag / is / launches / used 字段，空格表示同时满足，OR 表示任一满足，- 或 NOT 表示排除。
+          </span>
+        </label>
+      ) : null}
       <div className="group-icon-field">
         <span className="edit-label">图标</span>
         <div className="group-icon-grid" role="listbox" aria-label="选择分组图标">
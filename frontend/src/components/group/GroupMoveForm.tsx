@@ -0,0 +1,47 @@
+import {useEffect, useState} from 'react';
+import type {domain} from '../../../wailsjs/go/models';
+import './GroupForm.css';
+
+export type GroupMoveDraft = {
+  parentId: string;
+};
+
+type GroupMoveFormProps = {
+  targets: domain.Group[];
+  initialDraft: GroupMoveDraft;
+  onChange: (next: GroupMoveDraft) => void;
+};
+
+export function GroupMoveForm({targets, initialDraft, onChange}: GroupMoveFormProps) {
+  const [draft, setDraft] = useState<GroupMoveDraft>(initialDraft);
+
+  useEffect(() => {
+    onChange(draft);
+  }, [draft, onChange]);
+
+  const options = [{id: '', name: '顶层'}, ...targets];
+
+  return (
+    <div className="group-form">
+      <div className="group-icon-field">
+        <span className="edit-label">上级分组</span>
+        <div className="group-delete-targets" role="listbox" aria-label="选择上级分组">
+          {options.map((target) => {
+            const isSelected = draft.parentId === target.id;
+            return (
+              <button
+                key={target.id || 'top'}
+                type="button"
+                className={`group-delete-target${isSelected ? ' is-selected' : ''}`}
+                aria-pressed={isSelected}
+                onClick={() => setDraft({parentId: target.id})}
+              >
+                {target.name}
+              </button>
+            );
+          })}
+        </div>
+      </div>
+    </div>
+  );
+}
@@ -52,7 +52,9 @@ export function GroupTabs({
           <button
             key={tab.id}
             type="button"
-            className={`group-tab${activeId === tab.id ? ' is-active' : ''}${
+            className={`group-tab${tab.depth ? ' group-tab--nested' : ''}${
+              tab.smart ? ' group-tab--smart' : ''
+            }${activeId === tab.id ? ' is-active' : ''}${
               draggingId === tab.id ? ' is-dragging' : ''
             }`}
             onClick={() => onSelect(tab.id)}
@@ -94,6 +96,11 @@ export function GroupTabs({
               onReorder(sourceId, tab.id);
             }}
           >
+            {tab.depth ? (
+              <span className="group-tab__indent" aria-hidden="true">
+                {'·'.repeat(tab.depth)}
+              </span>
+            ) : null}
             {tab.icon ? <Icon name={tab.icon} size={14} /> : null}
             <span>{tab.label}</span>
           </button>
@@ -0,0 +1,94 @@
+import {useCallback, useEffect, useState} from 'react';
+import {ListOperations, UndoOperation} from '../../../wailsjs/go/main/App';
+import type {domain} from '../../../wailsjs/go/models';
+import {ScrollArea} from '../ui/ScrollArea';
+import './OperationHistory.css';
+
+type OperationHistoryProps = {
+  onUndone: (operation: domain.Operation) => void | Promise<void>;
+  onError: (message: string) => void;
+};
+
+const kindLabels: Record<string, string> = {
+  rule_import: '导入分组规则',
+  clear_items: '清空项目',
+  scan: '扫描快捷方式',
+  bulk_edit: '批量编辑',
+  delete_group: '删除分组',
+};
+
+const formatTime = (value: unknown) => {
+  const date = new Date(value as string);
+  return Number.isNaN(date.getTime()) ? '' : date.toLocaleString();
+};
+
+export function OperationHistory({onUndone, onError}: OperationHistoryProps) {
+  const [operations, setOperations] = useState<domain.Operation[] | null>(null);
+  const [pendingId, setPendingId] = useState<string | null>(null);
+
+  const load = useCallback(async () => {
+    try {
+      setOperations(await ListOperations(0));
+    } catch (err) {
+      setOperations([]);
+      onError(err instanceof Error ? err.message : '无法读取操作记录');
+    }
+  }, [onError]);
+
+  useEffect(() => {
+    void load();
+  }, [load]);
+
+  const handleUndo = async (operation: domain.Operation) => {
+    setPendingId(operation.id);
+    try {
+      const undone = await UndoOperation(operation.id);
+      await onUndone(undone);
+      await load();
+    } catch (err) {
+      onError(err instanceof Error ? err.message : '撤销失败');
+    } finally {
+      setPendingId(null);
+    }
+  };
+
+  if (operations === null) {
+    return <p className="operation-hint">正在读取操作记录...</p>;
+  }
+
+  return (
+    <div className="operation-history">
+      <p className="operation-hint">
+        撤销会恢复操作前的项目与分组；之后仍生效的操作改动过相同数据时需先撤销后者。
+      </p>
+      <ScrollArea
+        className="operation-list scroll-area--auto"
+        viewportClassName="operation-list__viewport"
+        contentClassName="operation-items"
+      >
+        {operations.length ? (
+          operations.map((operation) => (
+            <div key={operation.id} className="operation-item">
+              <div className="operation-text" title={operation.summary}>
+                <span>{kindLabels[operation.kind] ?? operation.kind}</span>
+                <span className="operation-detail">
+                  {formatTime(operation.created_at)} · 项目 {operation.items} · 分组 {operation.groups}
+                </span>
+              </div>
+              <button
+                type="button"
+                className="operation-undo"
+                disabled={Boolean(operation.undone_at) || pendingId !== null}
+                onClick={() => void handleUndo(operation)}
+              >
+                {operation.undone_at ? '已撤销' : '撤销'}
+              </button>
+            </div>
+          ))
+        ) : (
+          <div className="operation-empty">暂无可撤销的操作</div>
+        )}
+      </ScrollArea>
+    </div>
+  );
+}
@@ -60,12 +60,15 @@ function ModalView({modal, zIndex, onClose}: ModalViewProps) {
     modal.primaryLabel ?? (modal.kind === 'error' ? '知道了' : '确定');
   const secondaryLabel =
     modal.secondaryLabel ?? (modal.kind === 'confirm' ? '取消' : undefined);
+  // Progress modals only offer their secondary action, such as cancel.
+  const showPrimary = modal.kind !== 'progress';
   const showActions =
-    modal.kind !== 'progress' &&
-    (modal.primaryLabel ||
-      modal.secondaryLabel ||
-      modal.kind === 'confirm' ||
-      modal.kind === 'error');
+    modal.kind === 'progress'
+      ? Boolean(modal.secondaryLabel)
+      : modal.primaryLabel ||
+        modal.secondaryLabel ||
+        modal.kind === 'confirm' ||
+        modal.kind === 'error';
   const canClose = modal.closable !== false;
 
   const handleBackdrop = useCallback(() => {
@@ -141,13 +144,15 @@ function ModalView({modal, zIndex, onClose}: ModalViewProps) {
                 {secondaryLabel}
               </button>
             ) : null}
-            <button
-              type="button"
-              className={`modal-button modal-button--primary${modal.tone === 'danger' ? ' modal-button--danger' : ''}`}
-              onClick={handleConfirm}
-            >
-              {primaryLabel}
-            </button>
+            {showPrimary ? (
+              <button
+                type="button"
+                className={`modal-button modal-button--primary${modal.tone === 'danger' ? ' modal-button--danger' : ''}`}
+                onClick={handleConfirm}
+              >
+                {primaryLabel}
+              </button>
+            ) : null}
           </div>
         ) : null}
       </div>
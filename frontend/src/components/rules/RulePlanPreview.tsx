@@ -0,0 +1,81 @@
+import type {domain} from '../../../wailsjs/go/models';
+import {ScrollArea} from '../ui/ScrollArea';
+import './RulePlanPreview.css';
+
+type RulePlanPreviewProps = {
+  plan: domain.RuleImportPlan;
+};
+
+const fieldLabels: Record<string, string> = {
+  name: '名称',
+  category: '分类',
+  order: '排序',
+  color: '颜色',
+  icon: '图标',
+  parent: '上级分组',
+};
+
+export function RulePlanPreview({plan}: RulePlanPreviewProps) {
+  const groups = plan.groups.filter((group) => group.action !== 'unchanged');
+  const created = groups.filter((group) => group.action === 'create').length;
+
+  return (
+    <div className="rule-plan">
+      <p className="rule-plan-summary">
+        新增分组 {created}，更新分组 {groups.length - created}，移动项目 {plan.moves.length}
+        ，类型不符跳过 {plan.skipped.length}，规则冲突 {plan.conflicts.length}
+      </p>
+      <ScrollArea
+        className="rule-plan-list scroll-area--auto"
+        viewportClassName="rule-plan-list__viewport"
+        contentClassName="rule-plan-items"
+      >
+        {groups.map((group) => (
+          <div key={`group-${group.key}`} className="rule-plan-item">
+            <span className={`rule-plan-tag is-${group.action}`}>
+              {group.action === 'create' ? '新增' : '更新'}
+            </span>
+            <span className="rule-plan-text">
+              {group.group.name}
+              {group.changes.length > 0 && (
+                <span className="rule-plan-detail">
+                  {group.changes
+                    .map(
+                      (change) =>
+                        `${fieldLabels[change.field] ?? change.field}: ${change.from || '空'} → ${change.to || '空'}`
+                    )
+                    .join('；')}
+                </span>
+              )}
+            </span>
+          </div>
+        ))}
+        {plan.moves.map((move) => (
+          <div key={`move-${move.item_id}`} className="rule-plan-item">
+            <span className="rule-plan-tag is-move">移动</span>
+            <span className="rule-plan-text">
+              {move.item_name}
+              <span className="rule-plan-detail">
+                {move.from_group_name || '未分组'} → {move.to_group_name}（规则 {move.rule}）
+              </span>
+            </span>
+          </div>
+        ))}
+        {plan.skipped.map((skip) => (
+          <div key={`skip-${skip.item_id}-${skip.group_key}-${skip.rule}`} className="rule-plan-item">
+            <span className="rule-plan-tag is-skip">跳过</span>
+            <span className="rule-plan-text">
+              {skip.item_name}
+              <span className="rule-plan-detail">
+                分组「{skip.group_name}」只接收 {skip.category}（规则 {skip.rule}）
+              </span>
+            </span>
+          </div>
+        ))}
+        {groups.length + plan.moves.length + plan.skipped.length === 0 && (
+          <div className="rule-plan-empty">规则不会产生任何变化</div>
+        )}
+      </ScrollArea>
+    </div>
+  );
+}
@@ -0,0 +1,95 @@
+import {useCallback, useEffect, useState} from 'react';
+import {DeleteRuleSet, ListRuleSets, SetRuleSetEnabled} from '../../../wailsjs/go/main/App';
+import type {domain} from '../../../wailsjs/go/models';
+import {ScrollArea} from '../ui/ScrollArea';
+import './RuleSetList.css';
+
+type RuleSetListProps = {
+  onError: (message: string) => void;
+};
+
+const formatTime = (value: unknown) => {
+  const date = new Date(value as string);
+  return Number.isNaN(date.getTime()) ? '' : date.toLocaleString();
+};
+
+export function RuleSetList({onError}: RuleSetListProps) {
+  const [ruleSets, setRuleSets] = useState<domain.RuleSet[] | null>(null);
+  const [pendingId, setPendingId] = useState<string | null>(null);
+
+  const load = useCallback(async () => {
+    try {
+      setRuleSets(await ListRuleSets());
+    } catch (err) {
+      setRuleSets([]);
+      onError(err instanceof Error ? err.message : '无法读取规则集');
+    }
+  }, [onError]);
+
+  useEffect(() => {
+    void load();
+  }, [load]);
+
+  const run = async (ruleSet: domain.RuleSet, action: () => Promise<unknown>) => {
+    setPendingId(ruleSet.id);
+    try {
+      await action();
+      await load();
+    } catch (err) {
+      onError(err instanceof Error ? err.message : '操作失败');
+    } finally {
+      setPendingId(null);
+    }
+  };
+
+  if (ruleSets === null) {
+    return <p className="rule-set-hint">正在读取规则集...</p>;
+  }
+
+  return (
+    <div className="rule-set-list">
+      <p className="rule-set-hint">
+        启用的规则集会在每次扫描后自动应用到新增或变更的项目；删除规则集不会移动已分组的项目。
+      </p>
+      <ScrollArea
+        className="rule-set-scroll scroll-area--auto"
+        viewportClassName="rule-set-scroll__viewport"
+        contentClassName="rule-set-items"
+      >
+        {ruleSets.length ? (
+          ruleSets.map((ruleSet) => (
+            <div key={ruleSet.id} className="rule-set-item">
+              <label className="rule-set-toggle">
+                <input
+                  type="checkbox"
+                  checked={ruleSet.enabled}
+                  disabled={pendingId !== null}
+                  onChange={(event) => {
+                    const enabled = event.target.checked;
+                    void run(ruleSet, () => SetRuleSetEnabled(ruleSet.id, enabled));
+                  }}
+                />
+                <span className="rule-set-text">
+                  <span title={ruleSet.source}>{ruleSet.name}</span>
+                  <span className="rule-set-detail">
+                    {formatTime(ruleSet.imported_at)} · 分组 {ruleSet.groups} · 规则 {ruleSet.rules}
+                  </span>
+                </span>
+              </label>
+              <button
+                type="button"
+                className="rule-set-delete"
+                disabled={pendingId !== null}
+                onClick={() => void run(ruleSet, () => DeleteRuleSet(ruleSet.id))}
+              >
+                删除
+              </button>
+            </div>
+          ))
+        ) : (
+          <div className="rule-set-empty">尚未导入分组规则</div>
+        )}
+      </ScrollArea>
+    </div>
+  );
+}
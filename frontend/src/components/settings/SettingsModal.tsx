@@ -2,10 +2,19 @@ import {useCallback, useEffect, useMemo, useState} from 'react';
 import {ScrollArea} from '../ui/ScrollArea';
 import {
   GetDataRoot,
+  GetSettings,
+  GetWatcherStatus,
+  ListScanHistory,
+  PauseWatcher,
   PickDataRoot,
   RestartApp,
+  ResumeWatcher,
+  SetBrokenItemPolicy,
   SetDataRoot,
+  SetScanConcurrency,
+  SetWatchScanRoots,
 } from '../../../wailsjs/go/main/App';
+import type {domain} from '../../../wailsjs/go/models';
 import {
   DEFAULT_HOTKEYS,
   HOTKEY_ACTIONS,
@@ -47,6 +56,40 @@ const launchModeOptions: Array<{value: LaunchMode; label: string}> = [
   {value: 'single', label: '单击启动'},
   {value: 'double', label: '双击启动'},
 ];
+type BrokenItemPolicy = 'keep' | 'hide' | 'remove';
+const brokenItemPolicyOptions: Array<{value: BrokenItemPolicy; label: string}> = [
+  {value: 'keep', label: '保留并标记'},
+  {value: 'hide', label: '自动隐藏'},
+  {value: 'remove', label: '自动移除'},
+];
+const scanConcurrencyOptions: Array<{value: number; label: string}> = [
+  {value: 0, label: '自动'},
+  {value: 1, label: '1'},
+  {value: 2, label: '2'},
+  {value: 4, label: '4'},
+  {value: 8, label: '8'},
+];
+const scanStatusLabels: Record<string, string> = {
+  completed: '完成',
+  failed: '失败',
+  cancelled: '已取消',
+};
+const scanSourceLabels: Record<string, string> = {
+  manual: '手动',
+  watcher: '监听',
+};
+
+const formatScanJob = (job: domain.ScanJob) => {
+  const started = new Date(job.started_at as string);
+  const time = Number.isNaN(started.getTime()) ? '' : started.toLocaleString();
+  const result = job.result;
+  const counts =
+    job.status === 'failed'
+      ? job.error
+      : `新增 ${result.inserted} · 更新 ${result.updated} · 移除 ${result.removed} · 失效 ${result.broken}`;
+  return `${time} · ${scanSourceLabels[job.source] ?? job.source} · ${scanStatusLabels[job.status] ?? job.status} · ${counts}`;
+};
+
 const panelCloseOptions: Array<{value: PanelCloseMode; label: string}> = [
   {value: 'manual', label: '不自动关闭'},
   {value: 'launch', label: '启动应用后关闭'},
@@ -75,6 +118,10 @@ export function SettingsModal({
   const [dataRootSaving, setDataRootSaving] = useState(false);
   const [dataRootRestarting, setDataRootRestarting] = useState(false);
   const [dataRootMessage, setDataRootMessage] = useState<string | null>(null);
+  const [brokenItemPolicy, setBrokenItemPolicyState] = useState<BrokenItemPolicy>('keep');
+  const [watcherStatus, setWatcherStatus] = useState<domain.WatcherStatus | null>(null);
+  const [scanHistory, setScanHistory] = useState<domain.ScanJob[]>([]);
+  const [scanConcurrency, setScanConcurrencyState] = useState(0);
   const notify = useToastStore((state) => state.notify);
 
   useEffect(() => {
@@ -167,6 +214,89 @@ export function SettingsModal({
     };
   }, [notify]);
 
+  useEffect(() => {
+    let cancelled = false;
+    GetSettings()
+      .then((settings) => {
+        if (cancelled) {
+          return;
+        }
+        if (settings.broken_item_policy) {
+          setBrokenItemPolicyState(settings.broken_item_policy as BrokenItemPolicy);
+        }
+        setScanConcurrencyState(settings.scan_concurrency ?? 0);
+      })
+      .catch(() => undefined);
+    GetWatcherStatus()
+      .then((status) => {
+        if (!cancelled) {
+          setWatcherStatus(status);
+        }
+      })
+      .catch(() => undefined);
+    ListScanHistory()
+      .then((history) => {
+        if (!cancelled) {
+          setScanHistory(history ?? []);
+        }
+      })
+      .catch(() => undefined);
+    return () => {
+      cancelled = true;
+    };
+  }, []);
+
+  const handleWatcher = useCallback(
+    async (action: () => Promise<domain.WatcherStatus>) => {
+      try {
+        setWatcherStatus(await action());
+      } catch (err) {
+        notify({
+          type: 'error',
+          title: '操作失败',
+          message: err instanceof Error ? err.message : '无法更新目录监听',
+        });
+      }
+    },
+    [notify]
+  );
+
+  const handleScanConcurrency = useCallback(
+    async (limit: number) => {
+      const previous = scanConcurrency;
+      setScanConcurrencyState(limit);
+      try {
+        await SetScanConcurrency(limit);
+      } catch (err) {
+        setScanConcurrencyState(previous);
+        notify({
+          type: 'error',
+          title: '保存失败',
+          message: err instanceof Error ? err.message : '无法保存扫描并发数',
+        });
+      }
+    },
+    [notify, scanConcurrency]
+  );
+
+  const handleBrokenItemPolicy = useCallback(
+    async (policy: BrokenItemPolicy) => {
+      const previous = brokenItemPolicy;
+      setBrokenItemPolicyState(policy);
+      try {
+        await SetBrokenItemPolicy(policy);
+      } catch (err) {
+        setBrokenItemPolicyState(previous);
+        notify({
+          type: 'error',
+          title: '保存失败',
+          message: err instanceof Error ? err.message : '无法保存失效项目处理方式',
+        });
+      }
+    },
+    [brokenItemPolicy, notify]
+  );
+
   const handleReset = useCallback(() => {
     setHotkeys({...DEFAULT_HOTKEYS});
   }, []);
@@ -416,6 +546,126 @@ export function SettingsModal({
       );
     }
 
+    if (activeTab === 'scan') {
+      return (
+        <div className="settings-general">
+          <div className="settings-section-header">
+            <div>
+              <h3 className="settings-section-title">文件扫描</h3>
+              <p className="settings-section-desc">
+                每次扫描后会检查项目的文件与快捷方式目标是否仍然存在。
+              </p>
+            </div>
+          </div>
+          <div className="settings-option">
+            <div className="settings-option-info">
+              <div className="settings-option-title">监听目录变更</div>
+              <div className="settings-option-desc">
+                扫描目录中的文件增删改后自动增量扫描
+                {watcherStatus?.running ? `，正在监听 ${watcherStatus.directories} 个目录` : ''}
+                {watcherStatus?.paused ? '（已暂停）' : ''}。
+              </div>
+            </div>
+            <label className="settings-switch" aria-label="监听目录变更">
+              <input
+                type="checkbox"
+                checked={watcherStatus?.enabled ?? false}
+                disabled={watcherStatus === null}
+                onChange={() =>
+                  void handleWatcher(() => SetWatchScanRoots(!watcherStatus?.enabled))
+                }
+              />
+              <span className="settings-switch-track">
+                <span className="settings-switch-thumb" />
+              </span>
+            </label>
+          </div>
+          <div className="settings-option">
+            <div className="settings-option-info">
+              <div className="settings-option-title">暂停监听</div>
+              <div className="settings-option-desc">
+                批量整理快捷方式时暂停，恢复后一次性处理期间的变更。
+              </div>
+            </div>
+            <button
+              type="button"
+              className="settings-ghost-button"
+              disabled={!watcherStatus?.running}
+              onClick={() =>
+                void handleWatcher(watcherStatus?.paused ? ResumeWatcher : PauseWatcher)
+              }
+            >
+              {watcherStatus?.paused ? '恢复监听' : '暂停监听'}
+            </button>
+          </div>
+          <div className="settings-option">
+            <div className="settings-option-info">
+              <div className="settings-option-title">并发扫描</div>
+              <div className="settings-option-desc">
+                同时遍历的目录数与解析快捷方式的线程数，机械硬盘可适当调低。
+              </div>
+            </div>
+            <div
+              className="settings-choice-group"
+              role="radiogroup"
+              aria-label="并发扫描"
+            >
+              {scanConcurrencyOptions.map((option) => (
+                <button
+                  key={option.value}
+                  type="button"
+                  className={`settings-choice${scanConcurrency === option.value ? ' is-active' : ''}`}
+                  aria-pressed={scanConcurrency === option.value}
+                  onClick={() => void handleScanConcurrency(option.value)}
+                >
+                  {option.label}
+                </button>
+              ))}
+            </div>
+          </div>
+          <div className="settings-option">
+            <div className="settings-option-info">
+              <div className="settings-option-title">失效项目</div>
+              <div className="settings-option-desc">
+                文件或目标被删除后的处理方式，移除与隐藏可在操作记录中撤销。
+              </div>
+            </div>
+            <div
+              className="settings-choice-group"
+              role="radiogroup"
+              aria-label="失效项目"
+            >
+              {brokenItemPolicyOptions.map((option) => (
+                <button
+                  key={option.value}
+                  type="button"
+                  className={`settings-choice${brokenItemPolicy === option.value ? ' is-active' : ''}`}
+                  aria-pressed={brokenItemPolicy === option.value}
+                  onClick={() => void handleBrokenItemPolicy(option.value)}
+                >
+                  {option.label}
+                </button>
+              ))}
+            </div>
+          </div>
+          <div className="settings-option settings-option--stack">
+            <div className="settings-option-info">
+              <div className="settings-option-title">最近扫描</div>
+              {scanHistory.length > 0 ? (
+                scanHistory.slice(0, 5).map((job) => (
+                  <div key={job.id} className="settings-option-desc">
+                    {formatScanJob(job)}
+                  </div>
+                ))
+              ) : (
+                <div className="settings-option-desc">本次启动后尚未扫描。</div>
+              )}
+            </div>
+          </div>
+        </div>
+      );
+    }
+
     if (activeTab === 'storage') {
       const dataRootDisplay = dataRootLoading
         ? '正在读取...'
@@ -480,6 +730,13 @@ export function SettingsModal({
     );
   }, [
     activeTab,
+    brokenItemPolicy,
+    handleBrokenItemPolicy,
+    handleScanConcurrency,
+    handleWatcher,
+    scanConcurrency,
+    scanHistory,
+    watcherStatus,
     handleClear,
     handleRecord,
     handleReset,
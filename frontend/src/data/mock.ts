@@ -12,6 +12,11 @@ export const menuItems: MenuItem[] = [
   {id: 'settings', label: '设置'},
   {id: 'scan', label: '扫描快捷方式'},
   {id: 'import-rules', label: '导入分组规则'},
+  {id: 'export-rules', label: '导出分组规则'},
+  {id: 'rule-sets', label: '分组规则集'},
   {id: 'sync-icons', label: '刷新图标缓存'},
+  {id: 'history', label: '操作记录'},
+  {id: 'export-backup', label: '导出备份'},
+  {id: 'import-backup', label: '从备份恢复'},
   {id: 'clear', label: '清空项目'},
 ];
@@ -54,6 +54,8 @@ export type GroupTab = {
   id: string;
   label: string;
   icon?: IconName;
+  depth?: number;
+  smart?: boolean;
 };
 
 export type MenuItem = {
@@ -78,4 +80,11 @@ export type AppItem = {
   tags: string[];
   favorite: boolean;
   hidden: boolean;
+  broken?: string;
+  nameMatches?: TextRange[];
+};
+
+export type TextRange = {
+  start: number;
+  end: number;
 };
@@ -20,29 +20,43 @@ export const HOTKEY_ACTIONS: HotkeyAction[] = [
   },
 ];
 
-export function loadHotkeys(): HotkeyConfig {
-  const next = {...DEFAULT_HOTKEYS};
+// readLegacyHotkeys returns bindings saved by older versions in
+// localStorage. They stay there until clearLegacyHotkeys is called once the
+// backend has saved them.
+export function readLegacyHotkeys(): HotkeyConfig | null {
   try {
     const raw = window.localStorage.getItem(STORAGE_KEY);
     if (!raw) {
-      return next;
+      return null;
     }
     const parsed = JSON.parse(raw);
-    if (parsed && typeof parsed === 'object') {
-      for (const [key, value] of Object.entries(parsed)) {
-        if (typeof value === 'string') {
-          next[key] = value;
-        }
+    if (!parsed || typeof parsed !== 'object') {
+      return null;
+    }
+    const next = {...DEFAULT_HOTKEYS};
+    for (const [key, value] of Object.entries(parsed)) {
+      if (typeof value === 'string') {
+        next[key] = value;
       }
     }
-  } catch {
     return next;
+  } catch {
+    return null;
   }
-  return next;
 }
 
-export function saveHotkeys(value: HotkeyConfig) {
-  window.localStorage.setItem(STORAGE_KEY, JSON.stringify(value));
+export function clearLegacyHotkeys() {
+  window.localStorage.removeItem(STORAGE_KEY);
+}
+
+export function fromHotkeyBindings(
+  bindings: Array<{id: string; keys: string}>
+): HotkeyConfig {
+  const next = {...DEFAULT_HOTKEYS};
+  for (const binding of bindings) {
+    next[binding.id] = binding.keys;
+  }
+  return next;
 }
 
 export function toHotkeyBindings(config: HotkeyConfig) {
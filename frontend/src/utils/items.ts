@@ -11,14 +11,60 @@ const accentPalette: Accent[] = [
   'indigo',
 ];
 
-export function toGroupTab(group: domain.Group): GroupTab {
+export function toGroupTab(group: domain.Group, depth = 0): GroupTab {
   return {
     id: group.id,
     label: group.name,
     icon: toGroupIconName(group.icon),
+    depth,
+    smart: group.kind === 'smart',
   };
 }
 
+export type GroupTreeEntry = {
+  group: domain.Group;
+  depth: number;
+};
+
+// Lists groups in tree order: every group is followed by its subgroups, and
+// siblings are sorted by order. Groups whose parent is not in the list are
+// treated as top-level groups.
+export function flattenGroupTree(groups: domain.Group[]): GroupTreeEntry[] {
+  const ids = new Set(groups.map((group) => group.id));
+  const children = new Map<string, domain.Group[]>();
+  groups.forEach((group) => {
+    const parentId = ids.has(group.parent_id) ? group.parent_id : '';
+    children.set(parentId, [...(children.get(parentId) ?? []), group]);
+  });
+
+  const entries: GroupTreeEntry[] = [];
+  const visit = (parentId: string, depth: number) => {
+    const siblings = [...(children.get(parentId) ?? [])].sort((a, b) => a.order - b.order);
+    siblings.forEach((group) => {
+      entries.push({group, depth});
+      visit(group.id, depth + 1);
+    });
+  };
+  visit('', 0);
+  return entries;
+}
+
+// Returns the ids of the group and of every group nested below it.
+export function groupSubtree(groups: domain.Group[], id: string): Set<string> {
+  const subtree = new Set([id]);
+  let grown = true;
+  while (grown) {
+    grown = false;
+    groups.forEach((group) => {
+      if (!subtree.has(group.id) && subtree.has(group.parent_id)) {
+        subtree.add(group.id);
+        grown = true;
+      }
+    });
+  }
+  return subtree;
+}
+
 export function toAppItem(
   item: domain.Item,
   index: number,
@@ -37,6 +83,7 @@ export function toAppItem(
     tags: item.tags ?? [],
     favorite: item.favorite,
     hidden: item.hidden,
+    broken: item.broken || undefined,
   };
 }
 
@@ -1,3 +1,6 @@
+import {SavePreferences} from '../../wailsjs/go/main/App';
+import {domain} from '../../wailsjs/go/models';
+
 export type PanelPositionMode = 'center' | 'last' | 'cursor';
 export type LaunchMode = 'single' | 'double';
 export type PanelCloseMode = 'manual' | 'launch' | 'blur' | 'launch-or-blur';
@@ -28,54 +31,85 @@ const PANEL_CLOSE_MODES: PanelCloseMode[] = [
   'launch-or-blur',
 ];
 
-export function loadPreferences(): Preferences {
+// normalizePreferences accepts both the backend (snake_case) and the legacy
+// localStorage (camelCase) shapes.
+export function normalizePreferences(raw: unknown): Preferences {
   const next = {...DEFAULT_PREFERENCES};
+  if (!raw || typeof raw !== 'object') {
+    return next;
+  }
+  const parsed = raw as Record<string, any>;
+  const focusSearchOnShow =
+    parsed.focus_search_on_show ?? parsed.focusSearchOnShow;
+  const panelPositionMode =
+    parsed.panel_position_mode ?? parsed.panelPositionMode;
+  const launchMode = parsed.launch_mode ?? parsed.launchMode;
+  const panelCloseMode = parsed.panel_close_mode ?? parsed.panelCloseMode;
+  const lastWindowPosition =
+    parsed.last_window_position ?? parsed.lastWindowPosition;
+
+  if (typeof focusSearchOnShow === 'boolean') {
+    next.focusSearchOnShow = focusSearchOnShow;
+  }
+  if (
+    typeof panelPositionMode === 'string' &&
+    PANEL_POSITION_MODES.includes(panelPositionMode as PanelPositionMode)
+  ) {
+    next.panelPositionMode = panelPositionMode as PanelPositionMode;
+  }
+  if (
+    typeof launchMode === 'string' &&
+    LAUNCH_MODES.includes(launchMode as LaunchMode)
+  ) {
+    next.launchMode = launchMode as LaunchMode;
+  }
+  if (
+    typeof panelCloseMode === 'string' &&
+    PANEL_CLOSE_MODES.includes(panelCloseMode as PanelCloseMode)
+  ) {
+    next.panelCloseMode = panelCloseMode as PanelCloseMode;
+  }
+  if (
+    lastWindowPosition &&
+    typeof lastWindowPosition === 'object' &&
+    typeof lastWindowPosition.x === 'number' &&
+    typeof lastWindowPosition.y === 'number'
+  ) {
+    next.lastWindowPosition = {
+      x: lastWindowPosition.x,
+      y: lastWindowPosition.y,
+    };
+  }
+  return next;
+}
+
+// readLegacyPreferences returns preferences saved by older versions in
+// localStorage. They stay there until clearLegacyPreferences is called once
+// the backend has saved them.
+export function readLegacyPreferences(): Preferences | null {
   try {
     const raw = window.localStorage.getItem(STORAGE_KEY);
     if (!raw) {
-      return next;
-    }
-    const parsed = JSON.parse(raw);
-    if (parsed && typeof parsed === 'object') {
-      if (typeof parsed.focusSearchOnShow === 'boolean') {
-        next.focusSearchOnShow = parsed.focusSearchOnShow;
-      }
-      if (
-        typeof parsed.panelPositionMode === 'string' &&
-        PANEL_POSITION_MODES.includes(parsed.panelPositionMode as PanelPositionMode)
-      ) {
-        next.panelPositionMode = parsed.panelPositionMode as PanelPositionMode;
-      }
-      if (
-        typeof parsed.launchMode === 'string' &&
-        LAUNCH_MODES.includes(parsed.launchMode as LaunchMode)
-      ) {
-        next.launchMode = parsed.launchMode as LaunchMode;
-      }
-      if (
-        typeof parsed.panelCloseMode === 'string' &&
-        PANEL_CLOSE_MODES.includes(parsed.panelCloseMode as PanelCloseMode)
-      ) {
-        next.panelCloseMode = parsed.panelCloseMode as PanelCloseMode;
-      }
-      if (
-        parsed.lastWindowPosition &&
-        typeof parsed.lastWindowPosition === 'object' &&
-        typeof parsed.lastWindowPosition.x === 'number' &&
-        typeof parsed.lastWindowPosition.y === 'number'
-      ) {
-        next.lastWindowPosition = {
-          x: parsed.lastWindowPosition.x,
-          y: parsed.lastWindowPosition.y,
-        };
-      }
+      return null;
     }
+    return normalizePreferences(JSON.parse(raw));
   } catch {
-    return next;
+    return null;
   }
-  return next;
 }
 
-export function savePreferences(value: Preferences) {
-  window.localStorage.setItem(STORAGE_KEY, JSON.stringify(value));
+export function clearLegacyPreferences() {
+  window.localStorage.removeItem(STORAGE_KEY);
+}
+
+export async function savePreferences(value: Preferences) {
+  await SavePreferences(
+    domain.Preferences.createFrom({
+      focus_search_on_show: value.focusSearchOnShow,
+      panel_position_mode: value.panelPositionMode,
+      launch_mode: value.launchMode,
+      panel_close_mode: value.panelCloseMode,
+      last_window_position: value.lastWindowPosition ?? null,
+    })
+  );
 }
@@ -4,36 +4,82 @@ import {domain} from '../models';
 
 export function ApplyHotkeys(arg1:Array<domain.HotkeyBinding>):Promise<domain.HotkeyApplyResult>;
 
+export function BulkUpdateItems(arg1:Array<domain.ItemUpdate>):Promise<Array<domain.Item>>;
+
+export function CancelScan(arg1:string):Promise<boolean>;
+
 export function ClearItems():Promise<number>;
 
 export function CreateGroup(arg1:domain.GroupInput):Promise<domain.Group>;
 
 export function CreateItem(arg1:domain.ItemInput):Promise<domain.Item>;
 
-export function DeleteGroup(arg1:string):Promise<void>;
+export function DeleteGroup(arg1:string,arg2:domain.GroupDeleteOptions):Promise<domain.GroupDeleteResult>;
 
 export function DeleteItem(arg1:string):Promise<void>;
 
+export function DeleteRuleSet(arg1:string):Promise<void>;
+
+export function ExportBackup(arg1:string):Promise<domain.BackupManifest>;
+
+export function ExportGroupRules(arg1:string):Promise<domain.RuleExportResult>;
+
+export function GetCurrentScan():Promise<domain.ScanJob>;
+
 export function GetCursorAnchorPosition(arg1:number,arg2:number):Promise<domain.Point>;
 
 export function GetDataRoot():Promise<string>;
 
+export function GetHotkeyIssues():Promise<Array<domain.HotkeyIssue>>;
+
+export function GetItemFrecency(arg1:string):Promise<domain.FrecencyBreakdown>;
+
+export function GetSettings():Promise<domain.Settings>;
+
+export function GetUsageHeatmap(arg1:string,arg2:string):Promise<domain.UsageHeatmap>;
+
+export function GetWatcherStatus():Promise<domain.WatcherStatus>;
+
+export function ImportBackup(arg1:string):Promise<domain.BackupPreview>;
+
 export function ImportGroupRules(arg1:string):Promise<domain.RuleImportResult>;
 
-export function LaunchItem(arg1:string):Promise<domain.Item>;
+export function LaunchItem(arg1:string,arg2:string):Promise<domain.Item>;
+
+export function ListBrokenItems():Promise<Array<domain.Item>>;
+
+export function ListGroupUsage(arg1:string):Promise<Array<domain.GroupUsage>>;
 
 export function ListGroups():Promise<Array<domain.Group>>;
 
+export function ListItemUsage(arg1:string):Promise<Array<domain.ItemUsage>>;
+
 export function ListItems(arg1:string,arg2:string):Promise<Array<domain.Item>>;
 
+export function ListOperations(arg1:number):Promise<Array<domain.Operation>>;
+
+export function ListRuleSets():Promise<Array<domain.RuleSet>>;
+
+export function ListScanHistory():Promise<Array<domain.ScanJob>>;
+
 export function ListScanRoots():Promise<Array<string>>;
 
+export function MoveGroup(arg1:string,arg2:string):Promise<domain.Group>;
+
 export function OpenItemLocation(arg1:string):Promise<void>;
 
+export function PauseWatcher():Promise<domain.WatcherStatus>;
+
+export function PickBackupDestination():Promise<string>;
+
+export function PickBackupFile():Promise<string>;
+
 export function PickDataRoot():Promise<string>;
 
 export function PickIconSource():Promise<string>;
 
+export function PickRuleExportDestination():Promise<string>;
+
 export function PickRuleFile():Promise<string>;
 
 export function PickScanRoot():Promise<string>;
@@ -42,22 +88,50 @@ export function PickTargetFolder():Promise<string>;
 
 export function PickTargetPath():Promise<string>;
 
+export function PreviewBackup(arg1:string):Promise<domain.BackupPreview>;
+
+export function PreviewGroupRules(arg1:string):Promise<domain.RuleImportPlan>;
+
 export function PreviewIconFromSource(arg1:string):Promise<string>;
 
-export function RecordLaunch(arg1:string):Promise<domain.Item>;
+export function RecordLaunch(arg1:string,arg2:string):Promise<domain.Item>;
 
 export function RefreshItemIcon(arg1:string):Promise<domain.Item>;
 
 export function RestartApp():Promise<void>;
 
-export function ScanShortcuts(arg1:Array<string>):Promise<domain.ScanResult>;
+export function ResumeWatcher():Promise<domain.WatcherStatus>;
+
+export function SaveHotkeys(arg1:Array<domain.HotkeyBinding>):Promise<domain.HotkeyApplyResult>;
+
+export function SavePreferences(arg1:domain.Preferences):Promise<domain.Preferences>;
+
+export function SaveScanRoots(arg1:Array<string>):Promise<Array<string>>;
+
+export function ScanShortcuts(arg1:Array<string>):Promise<domain.ScanJob>;
+
+export function SearchItems(arg1:string,arg2:string):Promise<Array<domain.SearchResult>>;
+
+export function SetBrokenItemPolicy(arg1:string):Promise<void>;
 
 export function SetDataRoot(arg1:string):Promise<string>;
 
 export function SetFavorite(arg1:string,arg2:boolean):Promise<domain.Item>;
 
+export function SetFrecencyHalfLife(arg1:number):Promise<void>;
+
+export function SetRuleSetEnabled(arg1:string,arg2:boolean):Promise<domain.RuleSet>;
+
+export function SetScanConcurrency(arg1:number):Promise<void>;
+
+export function SetWatchScanRoots(arg1:boolean):Promise<domain.WatcherStatus>;
+
 export function SyncIcons():Promise<number>;
 
+export function TopItems(arg1:string,arg2:number):Promise<Array<domain.ItemUsage>>;
+
+export function UndoOperation(arg1:string):Promise<domain.Operation>;
+
 export function UpdateGroup(arg1:domain.Group):Promise<domain.Group>;
 
 export function UpdateItem(arg1:domain.ItemUpdate):Promise<domain.Item>;
@@ -6,6 +6,14 @@ export function ApplyHotkeys(arg1) {
   return window['go']['main']['App']['ApplyHotkeys'](arg1);
 }
 
+export function BulkUpdateItems(arg1) {
+  return window['go']['main']['App']['BulkUpdateItems'](arg1);
+}
+
+export function CancelScan(arg1) {
+  return window['go']['main']['App']['CancelScan'](arg1);
+}
+
 export function ClearItems() {
   return window['go']['main']['App']['ClearItems']();
 }
@@ -18,14 +26,30 @@ export function CreateItem(arg1) {
   return window['go']['main']['App']['CreateItem'](arg1);
 }
 
-export function DeleteGroup(arg1) {
-  return window['go']['main']['App']['DeleteGroup'](arg1);
+export function DeleteGroup(arg1, arg2) {
+  return window['go']['main']['App']['DeleteGroup'](arg1, arg2);
 }
 
 export function DeleteItem(arg1) {
   return window['go']['main']['App']['DeleteItem'](arg1);
 }
 
+export function DeleteRuleSet(arg1) {
+  return window['go']['main']['App']['DeleteRuleSet'](arg1);
+}
+
+export function ExportBackup(arg1) {
+  return window['go']['main']['App']['ExportBackup'](arg1);
+}
+
+export function ExportGroupRules(arg1) {
+  return window['go']['main']['App']['ExportGroupRules'](arg1);
+}
+
+export function GetCurrentScan() {
+  return window['go']['main']['App']['GetCurrentScan']();
+}
+
 export function GetCursorAnchorPosition(arg1, arg2) {
   return window['go']['main']['App']['GetCursorAnchorPosition'](arg1, arg2);
 }
@@ -34,30 +58,94 @@ export function GetDataRoot() {
   return window['go']['main']['App']['GetDataRoot']();
 }
 
+export function GetHotkeyIssues() {
+  return window['go']['main']['App']['GetHotkeyIssues']();
+}
+
+export function GetItemFrecency(arg1) {
+  return window['go']['main']['App']['GetItemFrecency'](arg1);
+}
+
+export function GetSettings() {
+  return window['go']['main']['App']['GetSettings']();
+}
+
+export function GetUsageHeatmap(arg1, arg2) {
+  return window['go']['main']['App']['GetUsageHeatmap'](arg1, arg2);
+}
+
+export function GetWatcherStatus() {
+  return window['go']['main']['App']['GetWatcherStatus']();
+}
+
+export function ImportBackup(arg1) {
+  return window['go']['main']['App']['ImportBackup'](arg1);
+}
+
 export function ImportGroupRules(arg1) {
   return window['go']['main']['App']['ImportGroupRules'](arg1);
 }
 
-export function LaunchItem(arg1) {
-  return window['go']['main']['App']['LaunchItem'](arg1);
+export function LaunchItem(arg1, arg2) {
+  return window['go']['main']['App']['LaunchItem'](arg1, arg2);
+}
+
+export function ListBrokenItems() {
+  return window['go']['main']['App']['ListBrokenItems']();
+}
+
+export function ListGroupUsage(arg1) {
+  return window['go']['main']['App']['ListGroupUsage'](arg1);
 }
 
 export function ListGroups() {
   return window['go']['main']['App']['ListGroups']();
 }
 
+export function ListItemUsage(arg1) {
+  return window['go']['main']['App']['ListItemUsage'](arg1);
+}
+
 export function ListItems(arg1, arg2) {
   return window['go']['main']['App']['ListItems'](arg1, arg2);
 }
 
+export function ListOperations(arg1) {
+  return window['go']['main']['App']['ListOperations'](arg1);
+}
+
+export function ListRuleSets() {
+  return window['go']['main']['App']['ListRuleSets']();
+}
+
+export function ListScanHistory() {
+  return window['go']['main']['App']['ListScanHistory']();
+}
+
 export function ListScanRoots() {
   return window['go']['main']['App']['ListScanRoots']();
 }
 
+export function MoveGroup(arg1, arg2) {
+  return window['go']['main']['App']['MoveGroup'](arg1, arg2);
+}
+
 export function OpenItemLocation(arg1) {
   return window['go']['main']['App']['OpenItemLocation'](arg1);
 }
 
+export function PauseWatcher() {
+  return window['go']['main']['App']['PauseWatcher']();
+}
+
+export function PickBackupDestination() {
+  return window['go']['main']['App']['PickBackupDestination']();
+}
+
+export function PickBackupFile() {
+  return window['go']['main']['App']['PickBackupFile']();
+}
+
 export function PickDataRoot() {
   return window['go']['main']['App']['PickDataRoot']();
 }
@@ -66,6 +154,10 @@ export function PickIconSource() {
   return window['go']['main']['App']['PickIconSource']();
 }
 
+export function PickRuleExportDestination() {
+  return window['go']['main']['App']['PickRuleExportDestination']();
+}
+
 export function PickRuleFile() {
   return window['go']['main']['App']['PickRuleFile']();
 }
@@ -82,12 +174,20 @@ export function PickTargetPath() {
   return window['go']['main']['App']['PickTargetPath']();
 }
 
+export function PreviewBackup(arg1) {
+  return window['go']['main']['App']['PreviewBackup'](arg1);
+}
+
+export function PreviewGroupRules(arg1) {
+  return window['go']['main']['App']['PreviewGroupRules'](arg1);
+}
+
 export function PreviewIconFromSource(arg1) {
   return window['go']['main']['App']['PreviewIconFromSource'](arg1);
 }
 
-export function RecordLaunch(arg1) {
-  return window['go']['main']['App']['RecordLaunch'](arg1);
+export function RecordLaunch(arg1, arg2) {
+  return window['go']['main']['App']['RecordLaunch'](arg1, arg2);
 }
 
 export function RefreshItemIcon(arg1) {
@@ -98,10 +198,34 @@ export function RestartApp() {
   return window['go']['main']['App']['RestartApp']();
 }
 
+export function ResumeWatcher() {
+  return window['go']['main']['App']['ResumeWatcher']();
+}
+
+export function SaveHotkeys(arg1) {
+  return window['go']['main']['App']['SaveHotkeys'](arg1);
+}
+
+export function SavePreferences(arg1) {
+  return window['go']['main']['App']['SavePreferences'](arg1);
+}
+
+export function SaveScanRoots(arg1) {
+  return window['go']['main']['App']['SaveScanRoots'](arg1);
+}
+
 export function ScanShortcuts(arg1) {
   return window['go']['main']['App']['ScanShortcuts'](arg1);
 }
 
+export function SearchItems(arg1, arg2) {
+  return window['go']['main']['App']['SearchItems'](arg1, arg2);
+}
+
+export function SetBrokenItemPolicy(arg1) {
+  return window['go']['main']['App']['SetBrokenItemPolicy'](arg1);
+}
+
 export function SetDataRoot(arg1) {
   return window['go']['main']['App']['SetDataRoot'](arg1);
 }
@@ -110,10 +234,34 @@ export function SetFavorite(arg1, arg2) {
   return window['go']['main']['App']['SetFavorite'](arg1, arg2);
 }
 
+export function SetFrecencyHalfLife(arg1) {
+  return window['go']['main']['App']['SetFrecencyHalfLife'](arg1);
+}
+
+export function SetRuleSetEnabled(arg1, arg2) {
+  return window['go']['main']['App']['SetRuleSetEnabled'](arg1, arg2);
+}
+
+export function SetScanConcurrency(arg1) {
+  return window['go']['main']['App']['SetScanConcurrency'](arg1);
+}
+
+export function SetWatchScanRoots(arg1) {
+  return window['go']['main']['App']['SetWatchScanRoots'](arg1);
+}
+
 export function SyncIcons() {
   return window['go']['main']['App']['SyncIcons']();
 }
 
+export function TopItems(arg1, arg2) {
+  return window['go']['main']['App']['TopItems'](arg1, arg2);
+}
+
+export function UndoOperation(arg1) {
+  return window['go']['main']['App']['UndoOperation'](arg1);
+}
+
 export function UpdateGroup(arg1) {
   return window['go']['main']['App']['UpdateGroup'](arg1);
 }
@@ -1,12 +1,202 @@
 export namespace domain {
 	
+	export class BackupCount {
+	    current: number;
+	    backup: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.current = source["current"];
+	        this.backup = source["backup"];
+	    }
+	}
+	export class BackupFile {
+	    path: string;
+	    size: number;
+	    sha256: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.sha256 = source["sha256"];
+	    }
+	}
+	export class BackupManifest {
+	    format: number;
+	    app_version: string;
+	    schema_version: number;
+	    // Go type: time
+	    created_at: any;
+	    files: BackupFile[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupManifest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.app_version = source["app_version"];
+	        this.schema_version = source["schema_version"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.files = this.convertValues(source["files"], BackupFile);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BackupPreview {
+	    manifest: BackupManifest;
+	    current_schema_version: number;
+	    items: BackupCount;
+	    groups: BackupCount;
+	    launches: BackupCount;
+	    icons: BackupCount;
+	    rules: BackupCount;
+	    settings: SettingChange[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.manifest = this.convertValues(source["manifest"], BackupManifest);
+	        this.current_schema_version = source["current_schema_version"];
+	        this.items = this.convertValues(source["items"], BackupCount);
+	        this.groups = this.convertValues(source["groups"], BackupCount);
+	        this.launches = this.convertValues(source["launches"], BackupCount);
+	        this.icons = this.convertValues(source["icons"], BackupCount);
+	        this.rules = this.convertValues(source["rules"], BackupCount);
+	        this.settings = this.convertValues(source["settings"], SettingChange);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FieldChange {
+	    field: string;
+	    from: string;
+	    to: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	    }
+	}
+	export class FrecencyBreakdown {
+	    item_id: string;
+	    score: number;
+	    launch_count: number;
+	    sampled: number;
+	    half_life_days: number;
+	    buckets: FrecencyBucket[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FrecencyBreakdown(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.item_id = source["item_id"];
+	        this.score = source["score"];
+	        this.launch_count = source["launch_count"];
+	        this.sampled = source["sampled"];
+	        this.half_life_days = source["half_life_days"];
+	        this.buckets = this.convertValues(source["buckets"], FrecencyBucket);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FrecencyBucket {
+	    max_age_days: number;
+	    weight: number;
+	    launches: number;
+	    points: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FrecencyBucket(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.max_age_days = source["max_age_days"];
+	        this.weight = source["weight"];
+	        this.launches = source["launches"];
+	        this.points = source["points"];
+	    }
+	}
 	export class Group {
 	    id: string;
+	    parent_id: string;
 	    name: string;
 	    order: number;
 	    color: string;
 	    category: string;
 	    icon: string;
+	    kind: string;
+	    query: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Group(source);
@@ -15,19 +205,51 @@ export namespace domain {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
+	        this.parent_id = source["parent_id"];
 	        this.name = source["name"];
 	        this.order = source["order"];
 	        this.color = source["color"];
 	        this.category = source["category"];
 	        this.icon = source["icon"];
+	        this.kind = source["kind"];
+	        this.query = source["query"];
+	    }
+	}
+	export class GroupDeleteOptions {
+	    policy: string;
+	    target_id: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GroupDeleteOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.policy = source["policy"];
+	        this.target_id = source["target_id"];
+	    }
+	}
+	export class GroupDeleteResult {
+	    items: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new GroupDeleteResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = source["items"];
 	    }
 	}
 	export class GroupInput {
+	    parent_id: string;
 	    name: string;
 	    order: number;
 	    color: string;
 	    category: string;
 	    icon: string;
+	    kind: string;
+	    query: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new GroupInput(source);
@@ -35,11 +257,36 @@ export namespace domain {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.parent_id = source["parent_id"];
 	        this.name = source["name"];
 	        this.order = source["order"];
 	        this.color = source["color"];
 	        this.category = source["category"];
 	        this.icon = source["icon"];
+	        this.kind = source["kind"];
+	        this.query = source["query"];
+	    }
+	}
+	export class GroupUsage {
+	    group_id: string;
+	    name: string;
+	    items: number;
+	    used_items: number;
+	    launches: number;
+	    failures: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new GroupUsage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.group_id = source["group_id"];
+	        this.name = source["name"];
+	        this.items = source["items"];
+	        this.used_items = source["used_items"];
+	        this.launches = source["launches"];
+	        this.failures = source["failures"];
 	    }
 	}
 	export class HotkeyIssue {
@@ -117,6 +364,8 @@ export namespace domain {
 	    // Go type: time
 	    last_used_at?: any;
 	    hidden: boolean;
+	    broken: string;
+	    frecency: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new Item(source);
@@ -136,6 +385,8 @@ export namespace domain {
 	        this.launch_count = source["launch_count"];
 	        this.last_used_at = this.convertValues(source["last_used_at"], null);
 	        this.hidden = source["hidden"];
+	        this.broken = source["broken"];
+	        this.frecency = source["frecency"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -214,6 +465,111 @@ export namespace domain {
 	        this.hidden = source["hidden"];
 	    }
 	}
+	export class ItemUsage {
+	    item_id: string;
+	    name: string;
+	    group_id: string;
+	    launches: number;
+	    failures: number;
+	    by_source: {[key: string]: number};
+	    // Go type: time
+	    last_launched_at?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ItemUsage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.item_id = source["item_id"];
+	        this.name = source["name"];
+	        this.group_id = source["group_id"];
+	        this.launches = source["launches"];
+	        this.failures = source["failures"];
+	        this.by_source = source["by_source"];
+	        this.last_launched_at = this.convertValues(source["last_launched_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class MatchRange {
+	    field: string;
+	    index: number;
+	    start: number;
+	    end: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MatchRange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.index = source["index"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
+	export class Operation {
+	    id: string;
+	    kind: string;
+	    summary: string;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    undone_at?: any;
+	    items: number;
+	    groups: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Operation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.summary = source["summary"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.undone_at = this.convertValues(source["undone_at"], null);
+	        this.items = source["items"];
+	        this.groups = source["groups"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Point {
 	    x: number;
 	    y: number;
@@ -228,10 +584,159 @@ export namespace domain {
 	        this.y = source["y"];
 	    }
 	}
+	export class Preferences {
+	    focus_search_on_show: boolean;
+	    panel_position_mode: string;
+	    launch_mode: string;
+	    panel_close_mode: string;
+	    last_window_position?: Point;
+	
+	    static createFrom(source: any = {}) {
+	        return new Preferences(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.focus_search_on_show = source["focus_search_on_show"];
+	        this.panel_position_mode = source["panel_position_mode"];
+	        this.launch_mode = source["launch_mode"];
+	        this.panel_close_mode = source["panel_close_mode"];
+	        this.last_window_position = this.convertValues(source["last_window_position"], Point);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RuleConflict {
+	    item_id: string;
+	    item_name: string;
+	    group_key: string;
+	    candidates: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleConflict(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.item_id = source["item_id"];
+	        this.item_name = source["item_name"];
+	        this.group_key = source["group_key"];
+	        this.candidates = source["candidates"];
+	    }
+	}
+	export class RuleExportResult {
+	    groups: number;
+	    rules: number;
+	    items: number;
+	    items_skipped: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleExportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.groups = source["groups"];
+	        this.rules = source["rules"];
+	        this.items = source["items"];
+	        this.items_skipped = source["items_skipped"];
+	    }
+	}
+	export class RuleGroupPlan {
+	    key: string;
+	    parent_key: string;
+	    action: string;
+	    group: Group;
+	    changes: FieldChange[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleGroupPlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.parent_key = source["parent_key"];
+	        this.action = source["action"];
+	        this.group = this.convertValues(source["group"], Group);
+	        this.changes = this.convertValues(source["changes"], FieldChange);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RuleImportPlan {
+	    groups: RuleGroupPlan[];
+	    moves: RuleItemMove[];
+	    skipped: RuleItemSkip[];
+	    conflicts: RuleConflict[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleImportPlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.groups = this.convertValues(source["groups"], RuleGroupPlan);
+	        this.moves = this.convertValues(source["moves"], RuleItemMove);
+	        this.skipped = this.convertValues(source["skipped"], RuleItemSkip);
+	        this.conflicts = this.convertValues(source["conflicts"], RuleConflict);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class RuleImportResult {
 	    groups_created: number;
 	    groups_updated: number;
 	    items_updated: number;
+	    conflicts: RuleConflict[];
 	
 	    static createFrom(source: any = {}) {
 	        return new RuleImportResult(source);
@@ -242,12 +747,176 @@ export namespace domain {
 	        this.groups_created = source["groups_created"];
 	        this.groups_updated = source["groups_updated"];
 	        this.items_updated = source["items_updated"];
+	        this.conflicts = this.convertValues(source["conflicts"], RuleConflict);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RuleItemMove {
+	    item_id: string;
+	    item_name: string;
+	    from_group_id: string;
+	    from_group_name: string;
+	    to_group_key: string;
+	    to_group_id: string;
+	    to_group_name: string;
+	    rule: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleItemMove(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.item_id = source["item_id"];
+	        this.item_name = source["item_name"];
+	        this.from_group_id = source["from_group_id"];
+	        this.from_group_name = source["from_group_name"];
+	        this.to_group_key = source["to_group_key"];
+	        this.to_group_id = source["to_group_id"];
+	        this.to_group_name = source["to_group_name"];
+	        this.rule = source["rule"];
+	    }
+	}
+	export class RuleItemSkip {
+	    item_id: string;
+	    item_name: string;
+	    item_type: string;
+	    group_key: string;
+	    group_name: string;
+	    category: string;
+	    rule: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleItemSkip(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.item_id = source["item_id"];
+	        this.item_name = source["item_name"];
+	        this.item_type = source["item_type"];
+	        this.group_key = source["group_key"];
+	        this.group_name = source["group_name"];
+	        this.category = source["category"];
+	        this.rule = source["rule"];
+	    }
+	}
+	export class RuleSet {
+	    id: string;
+	    name: string;
+	    source: string;
+	    enabled: boolean;
+	    // Go type: time
+	    imported_at: any;
+	    group_ids: Record<string, string>;
+	    groups: number;
+	    rules: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleSet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.source = source["source"];
+	        this.enabled = source["enabled"];
+	        this.imported_at = this.convertValues(source["imported_at"], null);
+	        this.group_ids = source["group_ids"];
+	        this.groups = source["groups"];
+	        this.rules = source["rules"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ScanJob {
+	    id: string;
+	    source: string;
+	    roots: string[];
+	    status: string;
+	    // Go type: time
+	    started_at: any;
+	    // Go type: time
+	    finished_at?: any;
+	    result: ScanResult;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanJob(source);
 	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source = source["source"];
+	        this.roots = source["roots"];
+	        this.status = source["status"];
+	        this.started_at = this.convertValues(source["started_at"], null);
+	        this.finished_at = this.convertValues(source["finished_at"], null);
+	        this.result = this.convertValues(source["result"], ScanResult);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class ScanResult {
 	    total: number;
 	    inserted: number;
+	    updated: number;
+	    removed: number;
 	    skipped: number;
+	    grouped: number;
+	    broken: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new ScanResult(source);
@@ -257,7 +926,141 @@ export namespace domain {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.total = source["total"];
 	        this.inserted = source["inserted"];
+	        this.updated = source["updated"];
+	        this.removed = source["removed"];
 	        this.skipped = source["skipped"];
+	        this.grouped = source["grouped"];
+	        this.broken = source["broken"];
+	    }
+	}
+	export class SearchResult {
+	    item: Item;
+	    score: number;
+	    matches: MatchRange[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.item = this.convertValues(source["item"], Item);
+	        this.score = source["score"];
+	        this.matches = this.convertValues(source["matches"], MatchRange);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SettingChange {
+	    key: string;
+	    current: string;
+	    backup: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SettingChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.current = source["current"];
+	        this.backup = source["backup"];
+	    }
+	}
+	export class Settings {
+	    hotkeys: HotkeyBinding[];
+	    scan_roots: string[];
+	    preferences: Preferences;
+	    frecency_half_life_days: number;
+	    broken_item_policy: string;
+	    watch_scan_roots: boolean;
+	    scan_concurrency: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hotkeys = this.convertValues(source["hotkeys"], HotkeyBinding);
+	        this.scan_roots = source["scan_roots"];
+	        this.preferences = this.convertValues(source["preferences"], Preferences);
+	        this.frecency_half_life_days = source["frecency_half_life_days"];
+	        this.broken_item_policy = source["broken_item_policy"];
+	        this.watch_scan_roots = source["watch_scan_roots"];
+	        this.scan_concurrency = source["scan_concurrency"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UsageHeatmap {
+	    window: string;
+	    item_id: string;
+	    total: number;
+	    hours: number[];
+	    cells: number[][];
+	
+	    static createFrom(source: any = {}) {
+	        return new UsageHeatmap(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.window = source["window"];
+	        this.item_id = source["item_id"];
+	        this.total = source["total"];
+	        this.hours = source["hours"];
+	        this.cells = source["cells"];
+	    }
+	}
+	export class WatcherStatus {
+	    enabled: boolean;
+	    running: boolean;
+	    paused: boolean;
+	    directories: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new WatcherStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.running = source["running"];
+	        this.paused = source["paused"];
+	        this.directories = source["directories"];
 	    }
 	}
 
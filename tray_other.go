@@ -0,0 +1,15 @@
+//go:build !windows
+
+package main
+
+import "context"
+
+type trayController struct{}
+
+var globalTray trayController
+
+func (t *trayController) setApp(_ *App) {}
+
+func (t *trayController) start(_ context.Context) {}
+
+func (t *trayController) stop() {}